@@ -0,0 +1,156 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.31.0
+// 	protoc        (unknown)
+// source: mini/freetx/module/v1/module.proto
+
+package v1
+
+import (
+	_ "cosmossdk.io/api/cosmos/app/v1alpha1"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Module is the config object of the freetx module.
+type Module struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// authority defines the custom module authority. If not set, defaults to the governance module.
+	Authority string `protobuf:"bytes,1,opt,name=authority,proto3" json:"authority,omitempty"`
+}
+
+func (x *Module) Reset() {
+	*x = Module{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mini_freetx_module_v1_module_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Module) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Module) ProtoMessage() {}
+
+func (x *Module) ProtoReflect() protoreflect.Message {
+	mi := &file_mini_freetx_module_v1_module_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Module.ProtoReflect.Descriptor instead.
+func (*Module) Descriptor() ([]byte, []int) {
+	return file_mini_freetx_module_v1_module_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Module) GetAuthority() string {
+	if x != nil {
+		return x.Authority
+	}
+	return ""
+}
+
+var File_mini_freetx_module_v1_module_proto protoreflect.FileDescriptor
+
+var file_mini_freetx_module_v1_module_proto_rawDesc = []byte{
+	0x0a, 0x22, 0x6d, 0x69, 0x6e, 0x69, 0x2f, 0x66, 0x72, 0x65, 0x65, 0x74, 0x78, 0x2f, 0x6d, 0x6f,
+	0x64, 0x75, 0x6c, 0x65, 0x2f, 0x76, 0x31, 0x2f, 0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x12, 0x15, 0x6d, 0x69, 0x6e, 0x69, 0x2e, 0x66, 0x72, 0x65, 0x65, 0x74,
+	0x78, 0x2e, 0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x2e, 0x76, 0x31, 0x1a, 0x20, 0x63, 0x6f, 0x73,
+	0x6d, 0x6f, 0x73, 0x2f, 0x61, 0x70, 0x70, 0x2f, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31,
+	0x2f, 0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x5c, 0x0a,
+	0x06, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x61, 0x75, 0x74, 0x68, 0x6f,
+	0x72, 0x69, 0x74, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x61, 0x75, 0x74, 0x68,
+	0x6f, 0x72, 0x69, 0x74, 0x79, 0x3a, 0x34, 0xba, 0xc0, 0x96, 0xda, 0x01, 0x2e, 0x0a, 0x2c, 0x67,
+	0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6a, 0x75, 0x6c, 0x69, 0x65, 0x6e,
+	0x72, 0x62, 0x72, 0x74, 0x2f, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x2d, 0x6d, 0x69, 0x6e, 0x69, 0x6d,
+	0x61, 0x6c, 0x2f, 0x78, 0x2f, 0x66, 0x72, 0x65, 0x65, 0x74, 0x78, 0x42, 0x3f, 0x5a, 0x3d, 0x67,
+	0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6a, 0x75, 0x6c, 0x69, 0x65, 0x6e,
+	0x72, 0x62, 0x72, 0x74, 0x2f, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x2d, 0x6d, 0x69, 0x6e, 0x69, 0x6d,
+	0x61, 0x6c, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x6d, 0x69, 0x6e, 0x69, 0x2f, 0x66, 0x72, 0x65, 0x65,
+	0x74, 0x78, 0x2f, 0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x2f, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_mini_freetx_module_v1_module_proto_rawDescOnce sync.Once
+	file_mini_freetx_module_v1_module_proto_rawDescData = file_mini_freetx_module_v1_module_proto_rawDesc
+)
+
+func file_mini_freetx_module_v1_module_proto_rawDescGZIP() []byte {
+	file_mini_freetx_module_v1_module_proto_rawDescOnce.Do(func() {
+		file_mini_freetx_module_v1_module_proto_rawDescData = protoimpl.X.CompressGZIP(file_mini_freetx_module_v1_module_proto_rawDescData)
+	})
+	return file_mini_freetx_module_v1_module_proto_rawDescData
+}
+
+var file_mini_freetx_module_v1_module_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_mini_freetx_module_v1_module_proto_goTypes = []interface{}{
+	(*Module)(nil), // 0: mini.freetx.module.v1.Module
+}
+var file_mini_freetx_module_v1_module_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_mini_freetx_module_v1_module_proto_init() }
+func file_mini_freetx_module_v1_module_proto_init() {
+	if File_mini_freetx_module_v1_module_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_mini_freetx_module_v1_module_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Module); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_mini_freetx_module_v1_module_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_mini_freetx_module_v1_module_proto_goTypes,
+		DependencyIndexes: file_mini_freetx_module_v1_module_proto_depIdxs,
+		MessageInfos:      file_mini_freetx_module_v1_module_proto_msgTypes,
+	}.Build()
+	File_mini_freetx_module_v1_module_proto = out.File
+	file_mini_freetx_module_v1_module_proto_rawDesc = nil
+	file_mini_freetx_module_v1_module_proto_goTypes = nil
+	file_mini_freetx_module_v1_module_proto_depIdxs = nil
+}
@@ -0,0 +1,77 @@
+package app
+
+import (
+	errorsmod "cosmossdk.io/errors"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/x/auth/ante"
+	ibcante "github.com/cosmos/ibc-go/v7/modules/core/ante"
+	ibckeeper "github.com/cosmos/ibc-go/v7/modules/core/keeper"
+
+	"github.com/julienrbrt/chain-minimal/mempool"
+	basefeeante "github.com/julienrbrt/chain-minimal/x/basefee/ante"
+	freetxante "github.com/julienrbrt/chain-minimal/x/freetx/ante"
+)
+
+// HandlerOptions are the options required for constructing the MiniApp ante handler.
+type HandlerOptions struct {
+	ante.HandlerOptions
+
+	BaseFeeKeeper basefeeante.BaseFeeKeeper
+	FreeTxKeeper  freetxante.FreeTxKeeper
+	IBCKeeper     *ibckeeper.Keeper
+	// MinGasPrices raises the node minimum gas prices with the mempool occupancy, it is optional.
+	MinGasPrices *mempool.DynamicMinGasPrices
+}
+
+// NewAnteHandler returns the MiniApp ante handler.
+// It is the default SDK ante handler, with the base fee check and the dynamic minimum gas prices
+// happening before the fees are deducted. Transactions paying no fee within the free transaction
+// allowance of their fee payer skip those checks.
+func NewAnteHandler(options HandlerOptions) (sdk.AnteHandler, error) {
+	if options.AccountKeeper == nil {
+		return nil, errorsmod.Wrap(sdkerrors.ErrLogic, "account keeper is required for ante builder")
+	}
+
+	if options.BankKeeper == nil {
+		return nil, errorsmod.Wrap(sdkerrors.ErrLogic, "bank keeper is required for ante builder")
+	}
+
+	if options.SignModeHandler == nil {
+		return nil, errorsmod.Wrap(sdkerrors.ErrLogic, "sign mode handler is required for ante builder")
+	}
+
+	if options.BaseFeeKeeper == nil {
+		return nil, errorsmod.Wrap(sdkerrors.ErrLogic, "base fee keeper is required for ante builder")
+	}
+
+	if options.FreeTxKeeper == nil {
+		return nil, errorsmod.Wrap(sdkerrors.ErrLogic, "free tx keeper is required for ante builder")
+	}
+
+	if options.IBCKeeper == nil {
+		return nil, errorsmod.Wrap(sdkerrors.ErrLogic, "IBC keeper is required for ante builder")
+	}
+
+	anteDecorators := []sdk.AnteDecorator{
+		ante.NewSetUpContextDecorator(), // outermost AnteDecorator. SetUpContext must be called first
+		ante.NewExtensionOptionsDecorator(options.ExtensionOptionChecker),
+		ante.NewValidateBasicDecorator(),
+		ante.NewTxTimeoutHeightDecorator(),
+		ante.NewValidateMemoDecorator(options.AccountKeeper),
+		ante.NewConsumeGasForTxSizeDecorator(options.AccountKeeper),
+		freetxante.NewFreeTxDecorator(options.FreeTxKeeper), // must be called before the fee checks
+		freetxante.SkipFreeTx(basefeeante.NewBaseFeeDecorator(options.BaseFeeKeeper)),
+		freetxante.SkipFreeTx(mempool.NewMinGasPricesDecorator(options.MinGasPrices)), // must be called before DeductFeeDecorator
+		ante.NewDeductFeeDecorator(options.AccountKeeper, options.BankKeeper, options.FeegrantKeeper, options.TxFeeChecker),
+		ante.NewSetPubKeyDecorator(options.AccountKeeper), // SetPubKeyDecorator must be called before all signature verification decorators
+		ante.NewValidateSigCountDecorator(options.AccountKeeper),
+		ante.NewSigGasConsumeDecorator(options.AccountKeeper, options.SigGasConsumer),
+		ante.NewSigVerificationDecorator(options.AccountKeeper, options.SignModeHandler),
+		ante.NewIncrementSequenceDecorator(options.AccountKeeper),
+		ibcante.NewRedundantRelayDecorator(options.IBCKeeper), // rejects the relay txs whose packets were all already relayed
+	}
+
+	return sdk.ChainAnteDecorators(anteDecorators...), nil
+}
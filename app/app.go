@@ -1,17 +1,25 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"syscall"
+	"time"
 
 	"cosmossdk.io/depinject"
 	dbm "github.com/cometbft/cometbft-db"
+	abci "github.com/cometbft/cometbft/abci/types"
 	"github.com/cometbft/cometbft/libs/log"
+	gogogrpc "github.com/cosmos/gogoproto/grpc"
+	"github.com/spf13/cast"
 
 	"github.com/cosmos/cosmos-sdk/baseapp"
 	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/cosmos/cosmos-sdk/client/grpc/node"
 	"github.com/cosmos/cosmos-sdk/codec"
 	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
 	"github.com/cosmos/cosmos-sdk/runtime"
@@ -22,23 +30,54 @@ import (
 	"github.com/cosmos/cosmos-sdk/store/streaming"
 	storetypes "github.com/cosmos/cosmos-sdk/store/types"
 	"github.com/cosmos/cosmos-sdk/testutil/testdata"
-	sdkmempool "github.com/cosmos/cosmos-sdk/types/mempool"
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/types/module"
 	"github.com/cosmos/cosmos-sdk/x/auth"
+	"github.com/cosmos/cosmos-sdk/x/auth/ante"
 	authkeeper "github.com/cosmos/cosmos-sdk/x/auth/keeper"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	authzkeeper "github.com/cosmos/cosmos-sdk/x/authz/keeper"
+	authzmodule "github.com/cosmos/cosmos-sdk/x/authz/module"
 	"github.com/cosmos/cosmos-sdk/x/bank"
 	bankkeeper "github.com/cosmos/cosmos-sdk/x/bank/keeper"
+	"github.com/cosmos/cosmos-sdk/x/capability"
+	capabilitykeeper "github.com/cosmos/cosmos-sdk/x/capability/keeper"
 	"github.com/cosmos/cosmos-sdk/x/consensus"
 	consensuskeeper "github.com/cosmos/cosmos-sdk/x/consensus/keeper"
+	"github.com/cosmos/cosmos-sdk/x/crisis"
+	crisiskeeper "github.com/cosmos/cosmos-sdk/x/crisis/keeper"
 	distr "github.com/cosmos/cosmos-sdk/x/distribution"
 	distrkeeper "github.com/cosmos/cosmos-sdk/x/distribution/keeper"
+	"github.com/cosmos/cosmos-sdk/x/evidence"
+	evidencekeeper "github.com/cosmos/cosmos-sdk/x/evidence/keeper"
+	feegrantkeeper "github.com/cosmos/cosmos-sdk/x/feegrant/keeper"
+	feegrantmodule "github.com/cosmos/cosmos-sdk/x/feegrant/module"
 	"github.com/cosmos/cosmos-sdk/x/genutil"
 	genutiltypes "github.com/cosmos/cosmos-sdk/x/genutil/types"
+	"github.com/cosmos/cosmos-sdk/x/gov"
+	govclient "github.com/cosmos/cosmos-sdk/x/gov/client"
+	govkeeper "github.com/cosmos/cosmos-sdk/x/gov/keeper"
 	"github.com/cosmos/cosmos-sdk/x/mint"
+	mintkeeper "github.com/cosmos/cosmos-sdk/x/mint/keeper"
+	paramsmodule "github.com/cosmos/cosmos-sdk/x/params"
+	paramskeeper "github.com/cosmos/cosmos-sdk/x/params/keeper"
+	"github.com/cosmos/cosmos-sdk/x/slashing"
+	slashingkeeper "github.com/cosmos/cosmos-sdk/x/slashing/keeper"
 	"github.com/cosmos/cosmos-sdk/x/staking"
 	stakingkeeper "github.com/cosmos/cosmos-sdk/x/staking/keeper"
+	"github.com/cosmos/cosmos-sdk/x/upgrade"
+	upgradeclient "github.com/cosmos/cosmos-sdk/x/upgrade/client"
+	upgradekeeper "github.com/cosmos/cosmos-sdk/x/upgrade/keeper"
+	ibctransferkeeper "github.com/cosmos/ibc-go/v7/modules/apps/transfer/keeper"
+	ibckeeper "github.com/cosmos/ibc-go/v7/modules/core/keeper"
 
+	"github.com/julienrbrt/chain-minimal/app/params"
 	"github.com/julienrbrt/chain-minimal/mempool"
+	mempooltypes "github.com/julienrbrt/chain-minimal/mempool/types"
+	"github.com/julienrbrt/chain-minimal/x/basefee"
+	basefeekeeper "github.com/julienrbrt/chain-minimal/x/basefee/keeper"
+	"github.com/julienrbrt/chain-minimal/x/freetx"
+	freetxkeeper "github.com/julienrbrt/chain-minimal/x/freetx/keeper"
 )
 
 var (
@@ -48,15 +87,29 @@ var (
 	// ModuleBasics defines the module BasicManager is in charge of setting up basic,
 	// non-dependant module elements, such as codec registration
 	// and genesis verification.
-	ModuleBasics = module.NewBasicManager(
+	ModuleBasics = module.NewBasicManager(append([]module.AppModuleBasic{
 		auth.AppModuleBasic{},
 		genutil.NewAppModuleBasic(genutiltypes.DefaultMessageValidator),
 		bank.AppModuleBasic{},
 		staking.AppModuleBasic{},
 		mint.AppModuleBasic{},
 		distr.AppModuleBasic{},
+		slashing.AppModuleBasic{},
+		evidence.AppModuleBasic{},
+		authzmodule.AppModuleBasic{},
+		feegrantmodule.AppModuleBasic{},
+		gov.NewAppModuleBasic([]govclient.ProposalHandler{
+			upgradeclient.LegacyProposalHandler,
+			upgradeclient.LegacyCancelProposalHandler,
+		}),
+		upgrade.AppModuleBasic{},
+		crisis.AppModuleBasic{},
+		capability.AppModuleBasic{},
+		paramsmodule.AppModuleBasic{},
 		consensus.AppModuleBasic{},
-	)
+		basefee.AppModuleBasic{},
+		freetx.AppModuleBasic{},
+	}, ibcModuleBasics...)...)
 )
 
 var (
@@ -79,7 +132,46 @@ type MiniApp struct {
 	BankKeeper            bankkeeper.Keeper
 	StakingKeeper         *stakingkeeper.Keeper
 	DistrKeeper           distrkeeper.Keeper
+	SlashingKeeper        slashingkeeper.Keeper
+	EvidenceKeeper        evidencekeeper.Keeper
+	AuthzKeeper           authzkeeper.Keeper
+	FeeGrantKeeper        feegrantkeeper.Keeper
+	MintKeeper            mintkeeper.Keeper
+	GovKeeper             *govkeeper.Keeper
+	UpgradeKeeper         *upgradekeeper.Keeper
+	CrisisKeeper          *crisiskeeper.Keeper
+	CapabilityKeeper      *capabilitykeeper.Keeper
+	ParamsKeeper          paramskeeper.Keeper
 	ConsensusParamsKeeper consensuskeeper.Keeper
+	BaseFeeKeeper         basefeekeeper.Keeper
+	FreeTxKeeper          freetxkeeper.Keeper
+
+	// IBC keepers, created in NewMiniApp as they are not provided by AppConfig (see registerIBCModules)
+	IBCKeeper            *ibckeeper.Keeper
+	TransferKeeper       ibctransferkeeper.Keeper
+	ScopedIBCKeeper      capabilitykeeper.ScopedKeeper
+	ScopedTransferKeeper capabilitykeeper.ScopedKeeper
+
+	// keys of the stores not provided by AppConfig
+	keys map[string]*storetypes.KVStoreKey
+
+	// rechecker rechecks the app-side mempool after each commit
+	rechecker *mempool.Rechecker
+	// journal records the app-side mempool operations, nil when disabled
+	journal *mempool.JournalMempool
+	// stopWeightsReload stops reloading the message type weights of the fee mempool, nil when not reloaded
+	stopWeightsReload func()
+	// msgTypeWeights are the message type weights of the fee mempool, and weightsVersion their version it is ranked with
+	msgTypeWeights *mempool.MsgTypeWeights
+	weightsVersion uint64
+	// feeMempool is the fee mempool, nil for the other mempool types
+	feeMempool *mempool.FeeMempool
+	// bundles holds the atomic transaction bundles of the app-side mempool, nil when disabled
+	bundles *mempool.BundleMempool
+	// bundleServer submits the bundles received by the gRPC server, nil when bundles are disabled
+	bundleServer mempooltypes.ServiceServer
+	// minGasPrices computes the node minimum gas prices from the mempool occupancy, nil when disabled
+	minGasPrices *mempool.DynamicMinGasPrices
 
 	// simulation manager
 	sm *module.SimulationManager
@@ -119,37 +211,198 @@ func NewMiniApp(
 		&app.BankKeeper,
 		&app.StakingKeeper,
 		&app.DistrKeeper,
+		&app.SlashingKeeper,
+		&app.EvidenceKeeper,
+		&app.AuthzKeeper,
+		&app.FeeGrantKeeper,
+		&app.MintKeeper,
+		&app.GovKeeper,
+		&app.UpgradeKeeper,
+		&app.CrisisKeeper,
+		&app.CapabilityKeeper,
+		&app.ParamsKeeper,
 		&app.ConsensusParamsKeeper,
+		&app.BaseFeeKeeper,
+		&app.FreeTxKeeper,
 	); err != nil {
 		panic(err)
 	}
 
 	// Below we construct and set an application specific mempool.
-	// We use the default prepare and process proposal handlers
-	// that are already set in the SDK's BaseApp.
-	var selectedMempool sdkmempool.Mempool = sdkmempool.NoOpMempool{}
-	switch appOpts.Get(mempool.FlagMempoolType) {
-	case "none":
-		selectedMempool = sdkmempool.NoOpMempool{}
-	case "sender-nonce":
-		selectedMempool = sdkmempool.NewSenderNonceMempool()
-	case "priority-nonce":
-		selectedMempool = sdkmempool.DefaultPriorityMempool()
-	case "fee":
-		selectedMempool = mempool.NewFeeMempool(logger)
-	default:
-		panic(fmt.Errorf("mempool not supported, got: %s, want none|sender-nonce|priority-nonce|fee", appOpts.Get(mempool.FlagMempoolType)))
+	// We use the default process proposal handler that is already set in the SDK's BaseApp,
+	// and the app prepare proposal handler with an app-side mempool (see ProposalHandler).
+	mempoolType := cast.ToString(appOpts.Get(mempool.FlagMempoolType))
+	// mempool.max-txs only sets the occupancy of the dynamic minimum gas prices, the app-side mempools stay unbounded
+	maxTxs := cast.ToInt(appOpts.Get(server.FlagMempoolMaxTxs))
+
+	// the message type weights of the fee mempool are reloaded from app.toml on SIGHUP, when started by minid start
+	var msgTypeWeights *mempool.MsgTypeWeights
+	if mempoolType == mempool.TypeFee {
+		weights, err := mempool.NewMsgTypeWeights(mempool.WeightsConfig{
+			Mode:    cast.ToString(appOpts.Get(mempool.FlagWeightMode)),
+			Weights: cast.ToStringSlice(appOpts.Get(mempool.FlagMsgTypeWeights)),
+		})
+		if err != nil {
+			panic(fmt.Errorf("invalid mempool message type weights: %w", err))
+		}
+		msgTypeWeights = weights
+		app.msgTypeWeights, app.weightsVersion = weights, weights.Version()
+
+		homePath := cast.ToString(appOpts.Get(flags.FlagHome))
+		if cast.ToBool(appOpts.Get(mempool.OptWeightsReloadOnSignal)) && homePath != "" {
+			app.stopWeightsReload = weights.ReloadOnSignal(logger, filepath.Join(homePath, "config", "app.toml"), syscall.SIGHUP)
+		}
+	}
+
+	// the stakers get a capped priority boost, from their bonded tokens at insertion time
+	stakeBoost := mempool.StakeBoost{
+		Keeper:            app.StakingKeeper,
+		TokensPerPriority: sdk.ZeroInt(),
+		MaxBoost:          cast.ToInt64(appOpts.Get(mempool.FlagStakeBoostMax)),
+	}
+	if tokens := cast.ToString(appOpts.Get(mempool.FlagStakeBoostTokens)); tokens != "" {
+		var ok bool
+		if stakeBoost.TokensPerPriority, ok = sdk.NewIntFromString(tokens); !ok {
+			panic(fmt.Errorf("invalid stake boost tokens per priority: %s", tokens))
+		}
+	}
+
+	selectedMempool, err := mempool.NewMempool(logger, mempool.Config{
+		Type:             mempoolType,
+		MaxBytes:         cast.ToInt64(appOpts.Get(mempool.FlagMaxBytes)),
+		TxEncoder:        app.txConfig.TxEncoder(),
+		PriorityStrategy: mempool.PriorityStrategy(cast.ToString(appOpts.Get(mempool.FlagPriorityStrategy))),
+		BaseFeeKeeper:    app.BaseFeeKeeper,
+		MsgTypeWeights:   msgTypeWeights,
+		StakeBoost:       stakeBoost,
+		Denom:            params.DefaultBondDenom,
+		MaxFreeTxs:       cast.ToInt(appOpts.Get(mempool.FlagMaxFreeTxs)),
+		MaxTxsPerPayer:   cast.ToInt(appOpts.Get(mempool.FlagMaxTxsPerPayer)),
+		Aging: mempool.PriorityAging{
+			Curve: cast.ToString(appOpts.Get(mempool.FlagAgingCurve)),
+			Rate:  cast.ToInt64(appOpts.Get(mempool.FlagAgingRate)),
+		},
+	})
+	if err != nil {
+		panic(err)
 	}
 	logger.Info("selected mempool", "type", fmt.Sprintf("%T", selectedMempool))
+	app.feeMempool, _ = selectedMempool.(*mempool.FeeMempool)
+
+	// record the mempool operations, so that they can be replayed offline (see minid debug mempool-replay),
+	// each start appending a new session to the journal
+	if journalPath := cast.ToString(appOpts.Get(mempool.FlagJournal)); journalPath != "" {
+		journal, err := os.OpenFile(journalPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			panic(fmt.Errorf("failed to open mempool journal: %w", err))
+		}
+
+		app.journal = mempool.NewJournalMempool(logger, selectedMempool, journal, app.txConfig.TxEncoder())
+		selectedMempool = app.journal
+		logger.Info("recording mempool journal", "path", journalPath)
+	}
+
+	// the rechecker walks the mempool through a sender index, a negative budget disabling the recheck
+	recheckBudget := cast.ToInt(appOpts.Get(mempool.FlagRecheckBudget))
+	var senderIndex *mempool.SenderIndexMempool
+	if mempoolType != mempool.TypeNone && recheckBudget >= 0 {
+		senderIndex = mempool.NewSenderIndexMempool(selectedMempool)
+		selectedMempool = senderIndex
+	}
+
+	// bundles are not journaled, they are validated at submission, rechecked as a whole after each commit, and executed when proposing
+	appMempool := selectedMempool
+	if maxBundles := cast.ToInt(appOpts.Get(mempool.FlagMaxBundles)); mempoolType != mempool.TypeNone && maxBundles > 0 {
+		app.bundles = mempool.NewBundleMempool(selectedMempool, app.txConfig.TxEncoder(), params.DefaultBondDenom, maxBundles, cast.ToInt64(appOpts.Get(mempool.FlagBundleTTL)))
+		appMempool = app.bundles
+	}
 
 	mempoolOpt := func(app *baseapp.BaseApp) {
-		app.SetMempool(selectedMempool)
+		app.SetMempool(appMempool)
+
+		// BaseApp only recognizes sdkmempool.NoOpMempool, so the no-op handlers are set explicitly
+		if mempoolType == mempool.TypeNone {
+			app.SetPrepareProposal(baseapp.NoOpPrepareProposal())
+			app.SetProcessProposal(baseapp.NoOpProcessProposal())
+		}
 	}
 
 	baseAppOptions = append(baseAppOptions, mempoolOpt)
 
+	// the node minimum gas prices rise with the mempool occupancy when a curve is set
+	if curve := cast.ToString(appOpts.Get(mempool.FlagMinGasPricesCurve)); curve != "" && curve != mempool.CurveNone {
+		maxGasPrices, err := sdk.ParseDecCoins(cast.ToString(appOpts.Get(mempool.FlagMaxGasPrices)))
+		if err != nil {
+			panic(fmt.Errorf("invalid maximum gas prices: %w", err))
+		}
+
+		app.minGasPrices, err = mempool.NewDynamicMinGasPrices(
+			appMempool,
+			maxTxs,
+			curve,
+			cast.ToFloat64(appOpts.Get(mempool.FlagMinGasPricesLowOccupancy)),
+			cast.ToFloat64(appOpts.Get(mempool.FlagMinGasPricesHighOccupancy)),
+			maxGasPrices,
+		)
+		if err != nil {
+			panic(err)
+		}
+	}
+
 	app.App = appBuilder.Build(logger, db, traceStore, baseAppOptions...)
 
+	if err := app.registerIBCModules(); err != nil {
+		panic(err)
+	}
+
+	// The ante handler is built here instead of in the tx module (see AppConfig),
+	// so that it can be reused to recheck the app-side mempool after each commit.
+	anteHandler, err := NewAnteHandler(HandlerOptions{
+		HandlerOptions: ante.HandlerOptions{
+			AccountKeeper:   app.AccountKeeper,
+			BankKeeper:      app.BankKeeper,
+			FeegrantKeeper:  app.FeeGrantKeeper,
+			SignModeHandler: app.txConfig.SignModeHandler(),
+			SigGasConsumer:  ante.DefaultSigVerificationGasConsumer,
+			TxFeeChecker:    NewTxFeeChecker(app.BaseFeeKeeper),
+		},
+		BaseFeeKeeper: app.BaseFeeKeeper,
+		FreeTxKeeper:  app.FreeTxKeeper,
+		IBCKeeper:     app.IBCKeeper,
+		MinGasPrices:  app.minGasPrices,
+	})
+	if err != nil {
+		panic(err)
+	}
+	app.SetAnteHandler(anteHandler)
+
+	if mempoolType != mempool.TypeNone {
+		// the selection must end before the proposal timeout of CometBFT (config.toml), or the proposer misses its round
+		timeoutPropose := cast.ToDuration(appOpts.Get("consensus.timeout_propose"))
+		timeBudget := time.Duration(cast.ToFloat64(appOpts.Get(mempool.FlagPrepareProposalBudget)) * float64(timeoutPropose))
+
+		// the selected transactions are verified with the bytes recorded by the mempool, instead of being encoded again
+		txEncoder := NewRecordedTxEncoder(app.txConfig.TxEncoder())
+		app.SetTxEncoder(txEncoder.Encode)
+
+		proposalHandler := NewProposalHandler(appMempool, app.BaseApp, anteHandler, app.MsgServiceRouter(),
+			ProposalHandlerFreeTxReservationOpt(cast.ToInt(appOpts.Get(mempool.FlagFreeTxSlots)), cast.ToUint64(appOpts.Get(mempool.FlagFreeTxGas))),
+			ProposalHandlerTimeBudgetOpt(timeBudget),
+			ProposalHandlerRecordedTxEncoderOpt(txEncoder),
+		)
+		app.SetPrepareProposal(proposalHandler.PrepareProposalHandler())
+	}
+
+	if app.bundles != nil {
+		app.bundleServer = mempool.NewBundleServer(app.bundles, app.txConfig.TxDecoder(), anteHandler, func() (sdk.Context, error) {
+			return app.CreateQueryContext(0, false)
+		})
+	}
+
+	if senderIndex != nil {
+		app.rechecker = mempool.NewRechecker(logger, senderIndex, anteHandler, app.txConfig.TxEncoder(), recheckBudget)
+	}
+
 	// load state streaming if enabled
 	if _, _, err := streaming.LoadStreamingServices(app.App.BaseApp, appOpts, app.appCodec, logger, app.kvStoreKeys()); err != nil {
 		logger.Error("failed to load state streaming", "err", err)
@@ -158,6 +411,14 @@ func NewMiniApp(
 
 	/****  Module Options ****/
 
+	// register the invariants of the modules (e.g. bank, staking, distribution) in x/crisis, which checks them
+	// every inv-check-period blocks and from minid debug check-invariants
+	app.ModuleManager.RegisterInvariants(app.CrisisKeeper)
+
+	// register the upgrade handlers and, when an upgrade is pending, its store migrations (see Upgrades)
+	app.setupUpgradeHandlers()
+	app.setupUpgradeStoreLoaders()
+
 	// add test gRPC service for testing gRPC queries in isolation
 	testdata.RegisterQueryServer(app.GRPCQueryRouter(), testdata.QueryImpl{})
 
@@ -165,7 +426,12 @@ func NewMiniApp(
 	//
 	// NOTE: this is not required apps that don't use the simulator for fuzz testing
 	// transactions
-	app.sm = module.NewSimulationManagerFromAppModules(app.ModuleManager.Modules, make(map[string]module.AppModuleSimulation, 0))
+	authSubspace, _ := app.ParamsKeeper.GetSubspace(authtypes.ModuleName)
+	overrideModules := map[string]module.AppModuleSimulation{
+		// x/vesting is not wired, so the simulated genesis accounts are base accounts only
+		authtypes.ModuleName: auth.NewAppModule(app.appCodec, app.AccountKeeper, randomGenesisAccounts, authSubspace),
+	}
+	app.sm = module.NewSimulationManagerFromAppModules(app.ModuleManager.Modules, overrideModules)
 	app.sm.RegisterStoreDecoders()
 
 	if err := app.Load(loadLatest); err != nil {
@@ -175,6 +441,63 @@ func NewMiniApp(
 	return app
 }
 
+// Commit commits the block and rechecks the app-side mempool and its bundles against the newly committed state,
+// so that transactions and bundles made invalid by the block are removed before the next proposal.
+// The expired bundles are removed even when the recheck is disabled, the fee mempool is re-ranked once its
+// message type weights are reloaded, and the occupancy of the dynamic minimum gas prices is updated.
+//
+// NOTE: The recheck runs synchronously, before Commit returns, so it delays the next block by the time of up to
+// --mempool-recheck-budget ante handler runs (including signature verifications). It is not run in the background
+// as the app-side mempools are not safe for concurrent use, and CometBFT relies on the ABCI calls being serialized.
+// The duration of each pass is logged, lower the budget (or disable the recheck with -1) when it is significant.
+func (app *MiniApp) Commit() abci.ResponseCommit {
+	// the header is needed by the ante handler (e.g. chain-id) and is only available before commit
+	header := app.GetContextForDeliverTx(nil).BlockHeader()
+
+	res := app.App.Commit()
+	ctx := app.NewContext(true, header)
+
+	if app.rechecker != nil {
+		app.rechecker.Recheck(ctx)
+		if app.bundles != nil {
+			app.rechecker.RecheckBundles(ctx, app.bundles)
+		}
+	} else if app.bundles != nil {
+		app.bundles.Expire(header.Height)
+	}
+
+	// the delivered and invalid txs are removed, so the occupancy read by the node service is updated
+	if app.minGasPrices != nil {
+		app.minGasPrices.Update()
+	}
+
+	// the weights are reloaded outside of the ABCI calls, so the pooled txs are re-ranked here
+	if app.feeMempool != nil && app.msgTypeWeights != nil {
+		if version := app.msgTypeWeights.Version(); version != app.weightsVersion {
+			app.weightsVersion = version
+			changed := app.feeMempool.Reprioritize(ctx)
+			app.Logger().Info("re-ranked the mempool with the reloaded message type weights", "changed", changed)
+		}
+	}
+
+	return res
+}
+
+// Close flushes the mempool journal before closing the app.
+func (app *MiniApp) Close() error {
+	if app.stopWeightsReload != nil {
+		app.stopWeightsReload()
+	}
+
+	if app.journal != nil {
+		if err := app.journal.Close(); err != nil {
+			return err
+		}
+	}
+
+	return app.App.Close()
+}
+
 // Name returns the name of the App
 func (app *MiniApp) Name() string { return app.BaseApp.Name() }
 
@@ -183,8 +506,17 @@ func (app *MiniApp) LegacyAmino() *codec.LegacyAmino {
 	return app.legacyAmino
 }
 
+// AppCodec returns MiniApp's app codec.
+func (app *MiniApp) AppCodec() codec.Codec {
+	return app.appCodec
+}
+
 // GetKey returns the KVStoreKey for the provided store key.
 func (app *MiniApp) GetKey(storeKey string) *storetypes.KVStoreKey {
+	if key, ok := app.keys[storeKey]; ok {
+		return key
+	}
+
 	sk := app.UnsafeFindStoreKey(storeKey)
 	kvStoreKey, ok := sk.(*storetypes.KVStoreKey)
 	if !ok {
@@ -201,18 +533,68 @@ func (app *MiniApp) kvStoreKeys() map[string]*storetypes.KVStoreKey {
 		}
 	}
 
+	for name, kv := range app.keys {
+		keys[name] = kv
+	}
+
 	return keys
 }
 
+// BlockedAddresses returns the addresses of the module accounts that cannot receive funds.
+func BlockedAddresses() map[string]bool {
+	blockedAddrs := make(map[string]bool, len(blockAccAddrs))
+	for _, name := range blockAccAddrs {
+		blockedAddrs[authtypes.NewModuleAddress(name).String()] = true
+	}
+
+	return blockedAddrs
+}
+
+// randomGenesisAccounts returns the simulation accounts as base accounts, unlike the default of the auth module
+// which also returns vesting accounts.
+func randomGenesisAccounts(simState *module.SimulationState) authtypes.GenesisAccounts {
+	genesisAccs := make(authtypes.GenesisAccounts, len(simState.Accounts))
+	for i, acc := range simState.Accounts {
+		genesisAccs[i] = authtypes.NewBaseAccountWithAddress(acc.Address)
+	}
+
+	return genesisAccs
+}
+
 // SimulationManager implements the SimulationApp interface
 func (app *MiniApp) SimulationManager() *module.SimulationManager {
 	return app.sm
 }
 
+// RegisterNodeService registers the node gRPC service, reporting the dynamic minimum gas prices when enabled.
+func (app *MiniApp) RegisterNodeService(clientCtx client.Context) {
+	if app.minGasPrices == nil {
+		app.App.RegisterNodeService(clientCtx)
+		return
+	}
+
+	node.RegisterServiceServer(app.GRPCQueryRouter(), nodeQueryServer{minGasPrices: app.minGasPrices})
+}
+
+// RegisterGRPCServer registers the gRPC services of the app, and the bundle submission service when enabled.
+// The latter changes the app-side mempool, so it is served by the gRPC server only, not by the ABCI queries.
+func (app *MiniApp) RegisterGRPCServer(server gogogrpc.Server) {
+	app.App.RegisterGRPCServer(server)
+	if app.bundleServer != nil {
+		mempooltypes.RegisterServiceServer(server, app.bundleServer)
+	}
+}
+
 // RegisterAPIRoutes registers all application module routes with the provided
 // API server.
 func (app *MiniApp) RegisterAPIRoutes(apiSvr *api.Server, apiConfig config.APIConfig) {
 	app.App.RegisterAPIRoutes(apiSvr, apiConfig)
+	if app.bundles != nil {
+		if err := mempooltypes.RegisterServiceHandlerClient(context.Background(), apiSvr.GRPCGatewayRouter, mempooltypes.NewServiceClient(apiSvr.ClientCtx)); err != nil {
+			panic(err)
+		}
+	}
+
 	// register swagger API in app.go so that other applications can override easily
 	if err := server.RegisterSwaggerAPI(apiSvr.ClientCtx, apiSvr.Router, apiConfig.Swagger); err != nil {
 		panic(err)
@@ -0,0 +1,112 @@
+package app_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	dbm "github.com/cometbft/cometbft-db"
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/libs/log"
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	"github.com/cosmos/cosmos-sdk/baseapp"
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	simtestutil "github.com/cosmos/cosmos-sdk/testutil/sims"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	slashingtypes "github.com/cosmos/cosmos-sdk/x/slashing/types"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/julienrbrt/chain-minimal/app"
+	"github.com/julienrbrt/chain-minimal/mempool"
+)
+
+// newApp creates a MiniApp on the given database and home directory.
+func newApp(db dbm.DB, home string) *app.MiniApp {
+	return app.NewMiniApp(log.NewNopLogger(), db, nil, true, simtestutil.AppOptionsMap{
+		flags.FlagHome:            home,
+		mempool.FlagMempoolType:   mempool.TypeNone,
+		mempool.FlagMaxBundles:    0,
+		mempool.FlagRecheckBudget: -1,
+	}, baseapp.SetChainID("test"))
+}
+
+// initChain initializes the chain with a single validator delegated by the returned account, and commits the genesis.
+func initChain(t testing.TB, miniApp *app.MiniApp) sdk.AccAddress {
+	t.Helper()
+
+	valSet, err := simtestutil.CreateRandomValidatorSet()
+	require.NoError(t, err)
+
+	delegator := authtypes.NewBaseAccountWithAddress(sdk.AccAddress(secp256k1.GenPrivKey().PubKey().Address()))
+	balance := banktypes.Balance{
+		Address: delegator.GetAddress().String(),
+		Coins:   sdk.NewCoins(sdk.NewCoin(sdk.DefaultBondDenom, sdk.NewInt(100_000_000_000))),
+	}
+
+	genesisState, err := simtestutil.GenesisStateWithValSet(miniApp.AppCodec(), miniApp.DefaultGenesis(), valSet, []authtypes.GenesisAccount{delegator}, balance)
+	require.NoError(t, err)
+
+	// the validators are bonded in genesis without gentx, so their signing infos are not created by the staking hooks
+	slashingGenesis := slashingtypes.DefaultGenesisState()
+	for _, val := range valSet.Validators {
+		consAddr := sdk.ConsAddress(val.Address)
+		slashingGenesis.SigningInfos = append(slashingGenesis.SigningInfos, slashingtypes.SigningInfo{
+			Address:              consAddr.String(),
+			ValidatorSigningInfo: slashingtypes.NewValidatorSigningInfo(consAddr, 0, 0, time.Unix(0, 0), false, 0),
+		})
+	}
+	genesisState[slashingtypes.ModuleName] = miniApp.AppCodec().MustMarshalJSON(slashingGenesis)
+
+	stateBytes, err := json.Marshal(genesisState)
+	require.NoError(t, err)
+
+	miniApp.InitChain(abci.RequestInitChain{
+		ChainId:         "test",
+		ConsensusParams: simtestutil.DefaultConsensusParams,
+		AppStateBytes:   stateBytes,
+	})
+	miniApp.Commit()
+
+	return delegator.GetAddress()
+}
+
+// nextBlock begins the next block at the given time, with the given votes of the last commit and misbehaviors,
+// and returns its context.
+func nextBlock(miniApp *app.MiniApp, blockTime time.Time, votes []abci.VoteInfo, misbehaviors ...abci.Misbehavior) sdk.Context {
+	miniApp.BeginBlock(abci.RequestBeginBlock{
+		Header: cmtproto.Header{
+			ChainID: "test",
+			Height:  miniApp.LastBlockHeight() + 1,
+			Time:    blockTime,
+		},
+		LastCommitInfo:      abci.CommitInfo{Votes: votes},
+		ByzantineValidators: misbehaviors,
+	})
+
+	return miniApp.GetContextForDeliverTx(nil)
+}
+
+// endBlock ends and commits the current block.
+func endBlock(miniApp *app.MiniApp, ctx sdk.Context) {
+	miniApp.EndBlock(abci.RequestEndBlock{Height: ctx.BlockHeight()})
+	miniApp.Commit()
+}
+
+func TestBundleServiceRegistration(t *testing.T) {
+	miniApp := app.NewMiniApp(log.NewNopLogger(), dbm.NewMemDB(), nil, true, simtestutil.AppOptionsMap{
+		flags.FlagHome:            t.TempDir(),
+		mempool.FlagMempoolType:   mempool.TypeFee,
+		mempool.FlagMaxBundles:    10,
+		mempool.FlagRecheckBudget: -1,
+	}, baseapp.SetChainID("test"))
+
+	// the bundle submission changes the mempool, so it is served by the gRPC server only, not by the ABCI queries
+	server := grpc.NewServer()
+	miniApp.RegisterGRPCServer(server)
+	require.Contains(t, server.GetServiceInfo(), "mini.mempool.v1.Service")
+	require.Nil(t, miniApp.GRPCQueryRouter().Route("/mini.mempool.v1.Service/SubmitBundle"))
+}
@@ -2,12 +2,21 @@ package app
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"io"
+	"sort"
 
 	tmproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	"github.com/cosmos/cosmos-sdk/codec"
 	servertypes "github.com/cosmos/cosmos-sdk/server/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/module"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	distrtypes "github.com/cosmos/cosmos-sdk/x/distribution/types"
+	slashingtypes "github.com/cosmos/cosmos-sdk/x/slashing/types"
 	"github.com/cosmos/cosmos-sdk/x/staking"
 	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
 )
@@ -18,18 +27,17 @@ func (app *MiniApp) ExportAppStateAndValidators(
 	jailAllowedAddrs []string,
 	modulesToExport []string,
 ) (servertypes.ExportedApp, error) {
-	// as if they could withdraw from the start of the next block
-	ctx := app.NewContext(true, tmproto.Header{Height: app.LastBlockHeight()})
+	modulesToExport, err := app.modulesToExport(modulesToExport)
+	if err != nil {
+		return servertypes.ExportedApp{}, err
+	}
 
-	// We export at last height + 1, because that's the height at which
-	// CometBFT will start InitChain.
-	height := app.LastBlockHeight() + 1
-	if forZeroHeight {
-		height = 0
-		app.prepForZeroHeightGenesis(ctx, jailAllowedAddrs)
+	ctx, height, err := app.exportContext(forZeroHeight, jailAllowedAddrs)
+	if err != nil {
+		return servertypes.ExportedApp{}, err
 	}
 
-	genState := app.ModuleManager.ExportGenesis(ctx, app.appCodec)
+	genState := app.ModuleManager.ExportGenesisForModules(ctx, app.appCodec, modulesToExport)
 	appState, err := json.MarshalIndent(genState, "", "  ")
 	if err != nil {
 		return servertypes.ExportedApp{}, err
@@ -44,9 +52,391 @@ func (app *MiniApp) ExportAppStateAndValidators(
 	}, err
 }
 
-// prepare for fresh start at zero height
+// StreamAppStateAndValidators exports the state of the application as ExportAppStateAndValidators, but writes
+// the app state to w as a JSON object instead of returning it. The modules are exported one after the other, sorted
+// by name, and their genesis is written as soon as it is produced, so that only the genesis of a single module is
+// held in memory. The large lists (i.e. the accounts, the balances and the delegations) are also written one element
+// at a time (see genesisStreams). The returned ExportedApp has no app state.
+func (app *MiniApp) StreamAppStateAndValidators(
+	w io.Writer,
+	forZeroHeight bool,
+	jailAllowedAddrs []string,
+	modulesToExport []string,
+) (servertypes.ExportedApp, error) {
+	modulesToExport, err := app.modulesToExport(modulesToExport)
+	if err != nil {
+		return servertypes.ExportedApp{}, err
+	}
+
+	ctx, height, err := app.exportContext(forZeroHeight, jailAllowedAddrs)
+	if err != nil {
+		return servertypes.ExportedApp{}, err
+	}
+
+	// the modules are written in the order of the keys of the sorted genesis exported by minid export
+	names := append([]string{}, modulesToExport...)
+	sort.Strings(names)
+
+	if _, err := io.WriteString(w, "{"); err != nil {
+		return servertypes.ExportedApp{}, err
+	}
+
+	sep := ""
+	for _, name := range names {
+		module, ok := app.ModuleManager.Modules[name].(module.HasGenesis)
+		if !ok {
+			continue
+		}
+
+		if _, err := fmt.Fprintf(w, "%s%q:", sep, name); err != nil {
+			return servertypes.ExportedApp{}, err
+		}
+		sep = ","
+
+		// the accounts, balances and delegations are the bulk of large states, so they are written one by one
+		if stream, ok := app.genesisStreams()[name]; ok {
+			if err := stream(ctx, w); err != nil {
+				return servertypes.ExportedApp{}, fmt.Errorf("failed to export the genesis of module %s: %w", name, err)
+			}
+
+			continue
+		}
+
+		genesis := module.ExportGenesis(ctx.WithGasMeter(sdk.NewInfiniteGasMeter()), app.appCodec)
+		if len(genesis) == 0 {
+			// as marshaled by ExportAppStateAndValidators (e.g. x/consensus, whose state is in the consensus params)
+			genesis = []byte("null")
+		}
+
+		genesis, err := sdk.SortJSON(genesis)
+		if err != nil {
+			return servertypes.ExportedApp{}, fmt.Errorf("failed to export the genesis of module %s: %w", name, err)
+		}
+
+		if _, err := w.Write(genesis); err != nil {
+			return servertypes.ExportedApp{}, err
+		}
+	}
+
+	if _, err := io.WriteString(w, "}"); err != nil {
+		return servertypes.ExportedApp{}, err
+	}
+
+	validators, err := staking.WriteValidators(ctx, app.StakingKeeper)
+	return servertypes.ExportedApp{
+		Validators:      validators,
+		Height:          height,
+		ConsensusParams: app.BaseApp.GetConsensusParams(ctx),
+	}, err
+}
+
+// genesisStreams returns the functions writing the genesis of the modules whose state can be large,
+// by module name (see StreamAppStateAndValidators).
+func (app *MiniApp) genesisStreams() map[string]func(sdk.Context, io.Writer) error {
+	return map[string]func(sdk.Context, io.Writer) error{
+		authtypes.ModuleName:    app.streamAuthGenesis,
+		banktypes.ModuleName:    app.streamBankGenesis,
+		stakingtypes.ModuleName: app.streamStakingGenesis,
+	}
+}
+
+// streamAuthGenesis writes the genesis of the auth module to w as its ExportGenesis, with sorted keys,
+// but marshals the accounts one at a time instead of holding all of them in memory.
+func (app *MiniApp) streamAuthGenesis(ctx sdk.Context, w io.Writer) error {
+	genesis := authtypes.NewGenesisState(app.AccountKeeper.GetParams(ctx), nil)
+
+	return app.streamGenesis(w, genesis, map[string]genesisList{
+		"accounts": func(write func([]byte) error) (err error) {
+			app.AccountKeeper.IterateAccounts(ctx, func(account authtypes.AccountI) (stop bool) {
+				var bz []byte
+				if bz, err = app.appCodec.MarshalInterfaceJSON(account); err != nil {
+					return true
+				}
+
+				err = write(bz)
+				return err != nil
+			})
+
+			return err
+		},
+	})
+}
+
+// streamBankGenesis writes the genesis of the bank module to w as its ExportGenesis, with sorted keys,
+// but marshals the balances one account at a time instead of holding all of them in memory.
+func (app *MiniApp) streamBankGenesis(ctx sdk.Context, w io.Writer) error {
+	supply, _, err := app.BankKeeper.GetPaginatedTotalSupply(ctx, &query.PageRequest{Limit: query.MaxLimit})
+	if err != nil {
+		return fmt.Errorf("unable to fetch total supply %v", err)
+	}
+
+	genesis := banktypes.NewGenesisState(
+		app.BankKeeper.GetParams(ctx),
+		nil,
+		supply,
+		app.BankKeeper.GetAllDenomMetaData(ctx),
+		app.BankKeeper.GetAllSendEnabledEntries(ctx),
+	)
+
+	return app.streamGenesis(w, genesis, map[string]genesisList{
+		"balances": func(write func([]byte) error) (err error) {
+			// the balances of an account are stored next to each other, so an account is written
+			// once the balances of the next one are reached
+			var balance *banktypes.Balance
+			flush := func() error {
+				if balance == nil {
+					return nil
+				}
+
+				return write(app.appCodec.MustMarshalJSON(balance))
+			}
+
+			app.BankKeeper.IterateAllBalances(ctx, func(addr sdk.AccAddress, coin sdk.Coin) (stop bool) {
+				if balance != nil && balance.Address == addr.String() {
+					balance.Coins = balance.Coins.Add(coin)
+					return false
+				}
+
+				if err = flush(); err != nil {
+					return true
+				}
+
+				balance = &banktypes.Balance{Address: addr.String(), Coins: sdk.NewCoins(coin)}
+				return false
+			})
+			if err != nil {
+				return err
+			}
+
+			return flush()
+		},
+	})
+}
+
+// streamStakingGenesis writes the genesis of the staking module to w as its ExportGenesis, with sorted keys,
+// but marshals the delegations, unbonding delegations and redelegations one at a time instead of holding all of them in memory.
+func (app *MiniApp) streamStakingGenesis(ctx sdk.Context, w io.Writer) error {
+	var lastValidatorPowers []stakingtypes.LastValidatorPower
+	app.StakingKeeper.IterateLastValidatorPowers(ctx, func(addr sdk.ValAddress, power int64) (stop bool) {
+		lastValidatorPowers = append(lastValidatorPowers, stakingtypes.LastValidatorPower{Address: addr.String(), Power: power})
+		return false
+	})
+
+	genesis := &stakingtypes.GenesisState{
+		Params:              app.StakingKeeper.GetParams(ctx),
+		LastTotalPower:      app.StakingKeeper.GetLastTotalPower(ctx),
+		LastValidatorPowers: lastValidatorPowers,
+		Validators:          app.StakingKeeper.GetAllValidators(ctx),
+		Exported:            true,
+	}
+
+	return app.streamGenesis(w, genesis, map[string]genesisList{
+		"delegations": func(write func([]byte) error) (err error) {
+			app.StakingKeeper.IterateAllDelegations(ctx, func(delegation stakingtypes.Delegation) (stop bool) {
+				err = write(app.appCodec.MustMarshalJSON(&delegation))
+				return err != nil
+			})
+
+			return err
+		},
+		"unbonding_delegations": func(write func([]byte) error) (err error) {
+			app.StakingKeeper.IterateUnbondingDelegations(ctx, func(_ int64, ubd stakingtypes.UnbondingDelegation) (stop bool) {
+				err = write(app.appCodec.MustMarshalJSON(&ubd))
+				return err != nil
+			})
+
+			return err
+		},
+		"redelegations": func(write func([]byte) error) (err error) {
+			app.StakingKeeper.IterateRedelegations(ctx, func(_ int64, red stakingtypes.Redelegation) (stop bool) {
+				err = write(app.appCodec.MustMarshalJSON(&red))
+				return err != nil
+			})
+
+			return err
+		},
+	})
+}
+
+// genesisList writes the elements of a list of a module genesis, given as JSON to write.
+type genesisList func(write func([]byte) error) error
+
+// streamGenesis writes the genesis to w as JSON with sorted keys, but the lists of the given fields, empty in the
+// genesis, are written element by element.
+func (app *MiniApp) streamGenesis(w io.Writer, genesis codec.ProtoMarshaler, lists map[string]genesisList) error {
+	bz, err := app.appCodec.MarshalJSON(genesis)
+	if err != nil {
+		return err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(bz, &fields); err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for i, name := range names {
+		sep := ","
+		if i == 0 {
+			sep = "{"
+		}
+
+		if _, err := fmt.Fprintf(w, "%s%q:", sep, name); err != nil {
+			return err
+		}
+
+		list, ok := lists[name]
+		if !ok {
+			value, err := sdk.SortJSON(fields[name])
+			if err != nil {
+				return err
+			}
+
+			if _, err := w.Write(value); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if _, err := io.WriteString(w, "["); err != nil {
+			return err
+		}
+
+		elemSep := ""
+		if err := list(func(bz []byte) error {
+			bz, err := sdk.SortJSON(bz)
+			if err != nil {
+				return err
+			}
+
+			if _, err := fmt.Fprintf(w, "%s%s", elemSep, bz); err != nil {
+				return err
+			}
+			elemSep = ","
+
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		if _, err := io.WriteString(w, "]"); err != nil {
+			return err
+		}
+	}
+
+	_, err = io.WriteString(w, "}")
+	return err
+}
+
+// modulesToExport returns the modules to export, all of them when none is given.
+func (app *MiniApp) modulesToExport(modulesToExport []string) ([]string, error) {
+	if len(modulesToExport) == 0 {
+		return app.ModuleManager.OrderExportGenesis, nil
+	}
+
+	// the modules are checked before the export, as the module manager panics on unknown modules
+	for _, name := range modulesToExport {
+		if _, ok := app.ModuleManager.Modules[name]; !ok {
+			return nil, fmt.Errorf("module %s does not exist", name)
+		}
+	}
+
+	return modulesToExport, nil
+}
+
+// exportContext returns the context of the latest state to export and the height of the exported genesis,
+// preparing the state for a fresh start at height zero when requested.
+func (app *MiniApp) exportContext(forZeroHeight bool, jailAllowedAddrs []string) (sdk.Context, int64, error) {
+	// as if they could withdraw from the start of the next block
+	ctx := app.NewContext(true, tmproto.Header{Height: app.LastBlockHeight()})
+
+	// We export at last height + 1, because that's the height at which
+	// CometBFT will start InitChain.
+	height := app.LastBlockHeight() + 1
+	if forZeroHeight {
+		height = 0
+		if _, err := app.prepForZeroHeightGenesis(ctx, jailAllowedAddrs); err != nil {
+			return sdk.Context{}, 0, fmt.Errorf("failed to prepare the state for a zero height genesis: %w", err)
+		}
+	}
+
+	return ctx, height, nil
+}
+
+// ValidatorCoins are the commission withdrawn by a validator.
+type ValidatorCoins struct {
+	Validator string    `json:"validator"`
+	Amount    sdk.Coins `json:"amount"`
+}
+
+// ValidatorDecCoins are the fractions of the outstanding rewards of a validator donated to the community pool.
+type ValidatorDecCoins struct {
+	Validator string       `json:"validator"`
+	Amount    sdk.DecCoins `json:"amount"`
+}
+
+// DelegationCoins are the rewards withdrawn by a delegator from a validator.
+type DelegationCoins struct {
+	Delegator string    `json:"delegator"`
+	Validator string    `json:"validator"`
+	Amount    sdk.Coins `json:"amount"`
+}
+
+// ZeroHeightPreparation reports the changes made to the state by the preparation for a zero height genesis.
+type ZeroHeightPreparation struct {
+	// Height is the height of the prepared state.
+	Height int64 `json:"height"`
+	// Commissions are the commissions withdrawn by the validators.
+	Commissions []ValidatorCoins `json:"commissions"`
+	// Rewards are the rewards withdrawn by the delegators.
+	Rewards []DelegationCoins `json:"rewards"`
+	// CommunityPoolScraps are the fractions of the outstanding rewards of the validators donated to the community pool.
+	CommunityPoolScraps []ValidatorDecCoins `json:"community_pool_scraps"`
+	// JailedValidators are the operator addresses of the validators jailed as they are not in the jail allowed addresses.
+	JailedValidators []string `json:"jailed_validators"`
+	// ResetRedelegationEntries is the number of redelegation entries whose creation height is reset.
+	ResetRedelegationEntries int `json:"reset_redelegation_entries"`
+	// ResetUnbondingEntries is the number of unbonding delegation entries whose creation height is reset.
+	ResetUnbondingEntries int `json:"reset_unbonding_entries"`
+	// ResetValidators is the number of validators whose unbonding height is reset.
+	ResetValidators int `json:"reset_validators"`
+	// ResetSigningInfos is the number of validator signing infos whose start height is reset.
+	ResetSigningInfos int `json:"reset_signing_infos"`
+}
+
+// DryRunZeroHeightGenesis prepares the latest state for a zero height genesis as an export with --for-zero-height,
+// but in a cache of the state that is discarded, and reports the changes the preparation would make.
+func (app *MiniApp) DryRunZeroHeightGenesis(jailAllowedAddrs []string) (ZeroHeightPreparation, error) {
+	ctx := app.NewContext(true, tmproto.Header{Height: app.LastBlockHeight()})
+
+	// the cache is never written
+	cacheCtx, _ := ctx.CacheContext()
+	return app.prepForZeroHeightGenesis(cacheCtx, jailAllowedAddrs)
+}
+
+// prepare for fresh start at zero height, returning the panics of the keepers as errors
 // NOTE zero height genesis is a temporary feature, which will be deprecated in favour of export at a block height
-func (app *MiniApp) prepForZeroHeightGenesis(ctx sdk.Context, jailAllowedAddrs []string) {
+func (app *MiniApp) prepForZeroHeightGenesis(ctx sdk.Context, jailAllowedAddrs []string) (_ ZeroHeightPreparation, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	prep := ZeroHeightPreparation{
+		Height:              ctx.BlockHeight(),
+		Commissions:         []ValidatorCoins{},
+		Rewards:             []DelegationCoins{},
+		CommunityPoolScraps: []ValidatorDecCoins{},
+		JailedValidators:    []string{},
+	}
+
 	applyAllowedAddrs := false
 
 	// check if there is a allowed address list
@@ -59,7 +449,7 @@ func (app *MiniApp) prepForZeroHeightGenesis(ctx sdk.Context, jailAllowedAddrs [
 	for _, addr := range jailAllowedAddrs {
 		_, err := sdk.ValAddressFromBech32(addr)
 		if err != nil {
-			log.Fatal(err)
+			return ZeroHeightPreparation{}, fmt.Errorf("invalid jail allowed address %s: %w", addr, err)
 		}
 		allowedAddrsMap[addr] = true
 	}
@@ -68,24 +458,52 @@ func (app *MiniApp) prepForZeroHeightGenesis(ctx sdk.Context, jailAllowedAddrs [
 
 	// withdraw all validator commission
 	app.StakingKeeper.IterateValidators(ctx, func(_ int64, val stakingtypes.ValidatorI) (stop bool) {
-		_, _ = app.DistrKeeper.WithdrawValidatorCommission(ctx, val.GetOperator())
+		var commission sdk.Coins
+		commission, err = app.DistrKeeper.WithdrawValidatorCommission(ctx, val.GetOperator())
+		if errors.Is(err, distrtypes.ErrNoValidatorCommission) {
+			err = nil
+			return false
+		}
+		if err != nil {
+			err = fmt.Errorf("failed to withdraw the commission of validator %s: %w", val.GetOperator(), err)
+			return true
+		}
+
+		prep.Commissions = append(prep.Commissions, ValidatorCoins{
+			Validator: val.GetOperator().String(),
+			Amount:    commission,
+		})
 		return false
 	})
+	if err != nil {
+		return ZeroHeightPreparation{}, err
+	}
 
 	// withdraw all delegator rewards
 	dels := app.StakingKeeper.GetAllDelegations(ctx)
 	for _, delegation := range dels {
 		valAddr, err := sdk.ValAddressFromBech32(delegation.ValidatorAddress)
 		if err != nil {
-			panic(err)
+			return ZeroHeightPreparation{}, err
 		}
 
 		delAddr, err := sdk.AccAddressFromBech32(delegation.DelegatorAddress)
 		if err != nil {
-			panic(err)
+			return ZeroHeightPreparation{}, err
 		}
 
-		_, _ = app.DistrKeeper.WithdrawDelegationRewards(ctx, delAddr, valAddr)
+		rewards, err := app.DistrKeeper.WithdrawDelegationRewards(ctx, delAddr, valAddr)
+		if err != nil {
+			return ZeroHeightPreparation{}, fmt.Errorf("failed to withdraw the rewards of delegator %s from validator %s: %w", delAddr, valAddr, err)
+		}
+
+		if !rewards.IsZero() {
+			prep.Rewards = append(prep.Rewards, DelegationCoins{
+				Delegator: delegation.DelegatorAddress,
+				Validator: delegation.ValidatorAddress,
+				Amount:    rewards,
+			})
+		}
 	}
 
 	// clear validator slash events
@@ -106,32 +524,41 @@ func (app *MiniApp) prepForZeroHeightGenesis(ctx sdk.Context, jailAllowedAddrs [
 		feePool.CommunityPool = feePool.CommunityPool.Add(scraps...)
 		app.DistrKeeper.SetFeePool(ctx, feePool)
 
-		if err := app.DistrKeeper.Hooks().AfterValidatorCreated(ctx, val.GetOperator()); err != nil {
-			panic(err)
+		if !scraps.IsZero() {
+			prep.CommunityPoolScraps = append(prep.CommunityPoolScraps, ValidatorDecCoins{
+				Validator: val.GetOperator().String(),
+				Amount:    scraps,
+			})
+		}
+
+		if err = app.DistrKeeper.Hooks().AfterValidatorCreated(ctx, val.GetOperator()); err != nil {
+			err = fmt.Errorf("failed to reinitialize validator %s: %w", val.GetOperator(), err)
+			return true
 		}
 		return false
 	})
+	if err != nil {
+		return ZeroHeightPreparation{}, err
+	}
 
 	// reinitialize all delegations
 	for _, del := range dels {
 		valAddr, err := sdk.ValAddressFromBech32(del.ValidatorAddress)
 		if err != nil {
-			panic(err)
+			return ZeroHeightPreparation{}, err
 		}
 
 		delAddr, err := sdk.AccAddressFromBech32(del.DelegatorAddress)
 		if err != nil {
-			panic(err)
+			return ZeroHeightPreparation{}, err
 		}
 
 		if err := app.DistrKeeper.Hooks().BeforeDelegationCreated(ctx, delAddr, valAddr); err != nil {
-			// never called as BeforeDelegationCreated always returns nil
-			panic(fmt.Errorf("error while incrementing period: %w", err))
+			return ZeroHeightPreparation{}, fmt.Errorf("error while incrementing period: %w", err)
 		}
 
 		if err := app.DistrKeeper.Hooks().AfterDelegationModified(ctx, delAddr, valAddr); err != nil {
-			// never called as AfterDelegationModified always returns nil
-			panic(fmt.Errorf("error while creating a new delegation period record: %w", err))
+			return ZeroHeightPreparation{}, fmt.Errorf("error while creating a new delegation period record: %w", err)
 		}
 	}
 
@@ -145,6 +572,7 @@ func (app *MiniApp) prepForZeroHeightGenesis(ctx sdk.Context, jailAllowedAddrs [
 		for i := range red.Entries {
 			red.Entries[i].CreationHeight = 0
 		}
+		prep.ResetRedelegationEntries += len(red.Entries)
 		app.StakingKeeper.SetRedelegation(ctx, red)
 		return false
 	})
@@ -154,39 +582,62 @@ func (app *MiniApp) prepForZeroHeightGenesis(ctx sdk.Context, jailAllowedAddrs [
 		for i := range ubd.Entries {
 			ubd.Entries[i].CreationHeight = 0
 		}
+		prep.ResetUnbondingEntries += len(ubd.Entries)
 		app.StakingKeeper.SetUnbondingDelegation(ctx, ubd)
 		return false
 	})
 
 	// Iterate through validators by power descending, reset bond heights, and
 	// update bond intra-tx counters.
+	if err := app.resetValidators(ctx, applyAllowedAddrs, allowedAddrsMap, &prep); err != nil {
+		return ZeroHeightPreparation{}, err
+	}
+
+	if _, err := app.StakingKeeper.ApplyAndReturnValidatorSetUpdates(ctx); err != nil {
+		return ZeroHeightPreparation{}, fmt.Errorf("failed to apply the validator set updates: %w", err)
+	}
+
+	/* Handle slashing state. */
+
+	// reset start height on signing infos
+	app.SlashingKeeper.IterateValidatorSigningInfos(
+		ctx,
+		func(addr sdk.ConsAddress, info slashingtypes.ValidatorSigningInfo) (stop bool) {
+			info.StartHeight = 0
+			app.SlashingKeeper.SetValidatorSigningInfo(ctx, addr, info)
+			prep.ResetSigningInfos++
+			return false
+		},
+	)
+
+	return prep, nil
+}
+
+// resetValidators resets the unbonding height of the validators, and jails the ones missing from the allowed
+// addresses when they apply.
+func (app *MiniApp) resetValidators(ctx sdk.Context, applyAllowedAddrs bool, allowedAddrsMap map[string]bool, prep *ZeroHeightPreparation) error {
 	store := ctx.KVStore(app.GetKey(stakingtypes.StoreKey))
 	iter := sdk.KVStoreReversePrefixIterator(store, stakingtypes.ValidatorsKey)
-	counter := int16(0)
+	defer iter.Close()
 
 	for ; iter.Valid(); iter.Next() {
 		addr := sdk.ValAddress(stakingtypes.AddressFromValidatorsKey(iter.Key()))
 		validator, found := app.StakingKeeper.GetValidator(ctx, addr)
 		if !found {
-			panic("expected validator, not found")
+			return fmt.Errorf("expected validator %s, not found", addr)
 		}
 
 		validator.UnbondingHeight = 0
-		if applyAllowedAddrs && !allowedAddrsMap[addr.String()] {
+		if applyAllowedAddrs && !allowedAddrsMap[addr.String()] && !validator.Jailed {
+			// as when jailed by x/staking, jailed validators are not in the power index
+			app.StakingKeeper.DeleteValidatorByPowerIndex(ctx, validator)
 			validator.Jailed = true
+			prep.JailedValidators = append(prep.JailedValidators, addr.String())
 		}
 
 		app.StakingKeeper.SetValidator(ctx, validator)
-		counter++
-	}
-
-	if err := iter.Close(); err != nil {
-		app.Logger().Error("error while closing the key-value store reverse prefix iterator: ", err)
-		return
+		prep.ResetValidators++
 	}
 
-	_, err := app.StakingKeeper.ApplyAndReturnValidatorSetUpdates(ctx)
-	if err != nil {
-		log.Fatal(err)
-	}
+	return nil
 }
@@ -0,0 +1,281 @@
+package app_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"runtime"
+	"runtime/debug"
+	"testing"
+	"time"
+
+	dbm "github.com/cometbft/cometbft-db"
+	abci "github.com/cometbft/cometbft/abci/types"
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	minttypes "github.com/cosmos/cosmos-sdk/x/mint/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/julienrbrt/chain-minimal/app"
+)
+
+// benchmarkAccounts is the number of accounts of the synthetic state of the export benchmark.
+const benchmarkAccounts = 1_000_000
+
+func TestStreamAppStateAndValidators(t *testing.T) {
+	miniApp := newApp(dbm.NewMemDB(), t.TempDir())
+	delegator := initChain(t, miniApp)
+
+	// the delegator holds several denoms and unbonds a part of its delegation, so that the streamed lists
+	// have balances of several coins and unbonding delegations
+	ctx := nextBlock(miniApp, time.Now().UTC(), nil)
+	coins := sdk.NewCoins(sdk.NewInt64Coin("other", 1_000), sdk.NewInt64Coin("zzz", 10))
+	require.NoError(t, miniApp.BankKeeper.MintCoins(ctx, minttypes.ModuleName, coins))
+	require.NoError(t, miniApp.BankKeeper.SendCoinsFromModuleToAccount(ctx, minttypes.ModuleName, delegator, coins))
+
+	val := miniApp.StakingKeeper.GetAllValidators(ctx)[0]
+	_, err := miniApp.StakingKeeper.Undelegate(ctx, delegator, val.GetOperator(), sdk.NewDecWithPrec(5, 1))
+	require.NoError(t, err)
+	endBlock(miniApp, ctx)
+
+	exported, err := miniApp.ExportAppStateAndValidators(false, []string{}, []string{})
+	require.NoError(t, err)
+
+	var genState map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(exported.AppState, &genState))
+	var stakingGenesis stakingtypes.GenesisState
+	miniApp.AppCodec().MustUnmarshalJSON(genState[stakingtypes.ModuleName], &stakingGenesis)
+	require.Len(t, stakingGenesis.UnbondingDelegations, 1)
+
+	var buf bytes.Buffer
+	streamed, err := miniApp.StreamAppStateAndValidators(&buf, false, []string{}, []string{})
+	require.NoError(t, err)
+
+	// the streamed app state is the exported one, as compact JSON with sorted keys
+	require.JSONEq(t, string(exported.AppState), buf.String())
+	require.Equal(t, string(sdk.MustSortJSON(exported.AppState)), buf.String())
+	require.Nil(t, streamed.AppState)
+	require.Equal(t, exported.Height, streamed.Height)
+	require.Equal(t, exported.Validators, streamed.Validators)
+	require.Equal(t, exported.ConsensusParams, streamed.ConsensusParams)
+
+	// only the given modules are exported
+	buf.Reset()
+	_, err = miniApp.StreamAppStateAndValidators(&buf, false, []string{}, []string{banktypes.ModuleName, authtypes.ModuleName})
+	require.NoError(t, err)
+
+	var appState map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &appState))
+	require.Len(t, appState, 2)
+
+	var bankGenesis banktypes.GenesisState
+	miniApp.AppCodec().MustUnmarshalJSON(appState[banktypes.ModuleName], &bankGenesis)
+	require.Contains(t, bankGenesis.Balances, banktypes.Balance{
+		Address: delegator.String(),
+		Coins:   coins.Add(miniApp.BankKeeper.GetBalance(ctx, delegator, sdk.DefaultBondDenom)),
+	})
+	require.Contains(t, appState, banktypes.ModuleName)
+	require.Contains(t, appState, authtypes.ModuleName)
+
+	exported, err = miniApp.ExportAppStateAndValidators(false, []string{}, []string{banktypes.ModuleName})
+	require.NoError(t, err)
+	appState = nil
+	require.NoError(t, json.Unmarshal(exported.AppState, &appState))
+	require.Len(t, appState, 1)
+	require.Contains(t, appState, banktypes.ModuleName)
+
+	_, err = miniApp.StreamAppStateAndValidators(io.Discard, false, []string{}, []string{"unknown"})
+	require.ErrorContains(t, err, "module unknown does not exist")
+
+	_, err = miniApp.ExportAppStateAndValidators(false, []string{}, []string{"unknown"})
+	require.ErrorContains(t, err, "module unknown does not exist")
+
+	// a zero height genesis is streamed from the prepared state
+	streamed, err = miniApp.StreamAppStateAndValidators(io.Discard, true, []string{}, []string{})
+	require.NoError(t, err)
+	require.Zero(t, streamed.Height)
+}
+
+func TestDryRunZeroHeightGenesis(t *testing.T) {
+	miniApp := newApp(dbm.NewMemDB(), t.TempDir())
+	delegator := initChain(t, miniApp)
+	blockTime := time.Now().UTC()
+
+	ctx := nextBlock(miniApp, blockTime, nil)
+	val := miniApp.StakingKeeper.GetAllValidators(ctx)[0]
+	endBlock(miniApp, ctx)
+
+	// the minted tokens are allocated to the validator signing the blocks
+	for i := 0; i < 3; i++ {
+		blockTime = blockTime.Add(time.Second)
+		endBlock(miniApp, nextBlock(miniApp, blockTime, []abci.VoteInfo{validatorVote(val, true)}))
+	}
+
+	prep, err := miniApp.DryRunZeroHeightGenesis([]string{})
+	require.NoError(t, err)
+	require.Equal(t, miniApp.LastBlockHeight(), prep.Height)
+	require.NotEmpty(t, prep.Rewards)
+	require.Contains(t, delegatorsOf(prep.Rewards), delegator.String())
+	require.Empty(t, prep.JailedValidators)
+	require.Equal(t, 1, prep.ResetValidators)
+	require.Equal(t, 1, prep.ResetSigningInfos)
+
+	// the state is left untouched
+	ctx = miniApp.NewContext(true, cmtproto.Header{Height: miniApp.LastBlockHeight()})
+	require.False(t, miniApp.DistrKeeper.GetValidatorOutstandingRewardsCoins(ctx, val.GetOperator()).IsZero())
+
+	again, err := miniApp.DryRunZeroHeightGenesis([]string{})
+	require.NoError(t, err)
+	require.Equal(t, prep, again)
+
+	// the validators missing from the jail allowed addresses are jailed
+	prep, err = miniApp.DryRunZeroHeightGenesis([]string{sdk.ValAddress(make([]byte, 20)).String()})
+	require.NoError(t, err)
+	require.Equal(t, []string{val.OperatorAddress}, prep.JailedValidators)
+
+	prep, err = miniApp.DryRunZeroHeightGenesis([]string{val.OperatorAddress})
+	require.NoError(t, err)
+	require.Empty(t, prep.JailedValidators)
+
+	_, err = miniApp.DryRunZeroHeightGenesis([]string{"invalid"})
+	require.ErrorContains(t, err, "invalid jail allowed address invalid")
+
+	_, err = miniApp.ExportAppStateAndValidators(true, []string{"invalid"}, []string{})
+	require.ErrorContains(t, err, "invalid jail allowed address invalid")
+
+	exported, err := miniApp.ExportAppStateAndValidators(true, []string{}, []string{})
+	require.NoError(t, err)
+	require.Zero(t, exported.Height)
+}
+
+// delegatorsOf returns the delegators of the withdrawn rewards.
+func delegatorsOf(rewards []app.DelegationCoins) []string {
+	delegators := make([]string, 0, len(rewards))
+	for _, reward := range rewards {
+		delegators = append(delegators, reward.Delegator)
+	}
+
+	return delegators
+}
+
+// newBenchmarkApp returns a MiniApp whose committed state has benchmarkAccounts accounts, each with a balance.
+func newBenchmarkApp(b *testing.B) *app.MiniApp {
+	b.Helper()
+
+	// the state is too large to be held in memory alongside the exports
+	db, err := dbm.NewDB("application", dbm.GoLevelDBBackend, b.TempDir())
+	require.NoError(b, err)
+
+	miniApp := newApp(db, b.TempDir())
+	initChain(b, miniApp)
+
+	// the accounts are committed over several blocks, so that the cache of a block does not hold all of them
+	const accountsPerBlock = 50_000
+
+	blockTime := time.Now().UTC()
+	for i := uint64(0); i < benchmarkAccounts; i += accountsPerBlock {
+		blockTime = blockTime.Add(5 * time.Second)
+		ctx := nextBlock(miniApp, blockTime, nil)
+
+		// each account holds a balance, so that the bank genesis is as large as the auth one
+		coins := sdk.NewCoins(sdk.NewInt64Coin(sdk.DefaultBondDenom, 1_000_000))
+		supply := sdk.NewCoins(sdk.NewCoin(sdk.DefaultBondDenom, coins.AmountOf(sdk.DefaultBondDenom).MulRaw(accountsPerBlock)))
+		require.NoError(b, miniApp.BankKeeper.MintCoins(ctx, minttypes.ModuleName, supply))
+
+		for j := i; j < i+accountsPerBlock && j < benchmarkAccounts; j++ {
+			addr := make(sdk.AccAddress, 20)
+			binary.BigEndian.PutUint64(addr[12:], j+1)
+			miniApp.AccountKeeper.SetAccount(ctx, miniApp.AccountKeeper.NewAccountWithAddress(ctx, addr))
+			require.NoError(b, miniApp.BankKeeper.SendCoinsFromModuleToAccount(ctx, minttypes.ModuleName, addr, coins))
+		}
+
+		endBlock(miniApp, ctx)
+	}
+
+	return miniApp
+}
+
+// peakHeap runs f and returns the peak increase of the heap in use while it runs, sampled every 10ms.
+// The garbage collector runs more often meanwhile, so that the heap in use is close to the live heap.
+func peakHeap(f func()) uint64 {
+	defer debug.SetGCPercent(debug.SetGCPercent(5))
+	runtime.GC()
+
+	var before runtime.MemStats
+	runtime.ReadMemStats(&before)
+
+	peak := before.HeapInuse
+	sample := func() {
+		var stats runtime.MemStats
+		runtime.ReadMemStats(&stats)
+		if stats.HeapInuse > peak {
+			peak = stats.HeapInuse
+		}
+	}
+
+	done := make(chan struct{})
+	sampled := make(chan struct{})
+	go func() {
+		defer close(sampled)
+
+		ticker := time.NewTicker(10 * time.Millisecond)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				sample()
+			}
+		}
+	}()
+
+	f()
+	close(done)
+	<-sampled
+	sample()
+
+	return peak - before.HeapInuse
+}
+
+// BenchmarkExportAppState compares the in-memory export, sorted as by minid export, with the streamed export.
+func BenchmarkExportAppState(b *testing.B) {
+	miniApp := newBenchmarkApp(b)
+
+	b.Run("in-memory", func(b *testing.B) {
+		b.ReportAllocs()
+
+		var peak uint64
+		for i := 0; i < b.N; i++ {
+			peak = peakHeap(func() {
+				exported, err := miniApp.ExportAppStateAndValidators(false, []string{}, []string{})
+				require.NoError(b, err)
+
+				appState, err := sdk.SortJSON(exported.AppState)
+				require.NoError(b, err)
+
+				_, err = io.Discard.Write(appState)
+				require.NoError(b, err)
+			})
+		}
+		b.ReportMetric(float64(peak), "peak-heap-B")
+	})
+
+	b.Run("stream", func(b *testing.B) {
+		b.ReportAllocs()
+
+		var peak uint64
+		for i := 0; i < b.N; i++ {
+			peak = peakHeap(func() {
+				_, err := miniApp.StreamAppStateAndValidators(io.Discard, false, []string{}, []string{})
+				require.NoError(b, err)
+			})
+		}
+		b.ReportMetric(float64(peak), "peak-heap-B")
+	})
+}
@@ -0,0 +1,57 @@
+package app
+
+import (
+	errorsmod "cosmossdk.io/errors"
+	sdkmath "cosmossdk.io/math"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/x/auth/ante"
+
+	"github.com/julienrbrt/chain-minimal/mempool"
+	basefeeante "github.com/julienrbrt/chain-minimal/x/basefee/ante"
+	freetxante "github.com/julienrbrt/chain-minimal/x/freetx/ante"
+)
+
+// NewTxFeeChecker returns the MiniApp TxFeeChecker.
+// Like the SDK default one, it checks the fees against the node minimum gas prices during CheckTx.
+// The priority of a transaction is the tip it pays above the base fee, the same as in the fee mempool,
+// so that the priority logic lives in one place for both CheckTx and mempool ordering (see mempool.PriorityStrategyAnte).
+// Free transactions, accepted within the allowance of their fee payer, are exempted from the minimum gas prices.
+func NewTxFeeChecker(baseFeeKeeper basefeeante.BaseFeeKeeper) ante.TxFeeChecker {
+	return func(ctx sdk.Context, tx sdk.Tx) (sdk.Coins, int64, error) {
+		feeTx, ok := tx.(sdk.FeeTx)
+		if !ok {
+			return nil, 0, errorsmod.Wrap(sdkerrors.ErrTxDecode, "Tx must be a FeeTx")
+		}
+
+		feeCoins := feeTx.GetFee()
+		gas := feeTx.GetGas()
+
+		// the node minimum gas prices are only checked for local mempool purposes, thus only in CheckTx
+		if ctx.IsCheckTx() && !freetxante.IsFreeTx(ctx) {
+			minGasPrices := ctx.MinGasPrices()
+			if !minGasPrices.IsZero() {
+				requiredFees := make(sdk.Coins, len(minGasPrices))
+
+				// fee = ceil(minGasPrice * gasLimit)
+				glDec := sdkmath.LegacyNewDecFromInt(sdkmath.NewIntFromUint64(gas))
+				for i, gp := range minGasPrices {
+					requiredFees[i] = sdk.NewCoin(gp.Denom, gp.Amount.Mul(glDec).Ceil().RoundInt())
+				}
+
+				if !feeCoins.IsAnyGTE(requiredFees) {
+					return nil, 0, errorsmod.Wrapf(sdkerrors.ErrInsufficientFee, "insufficient fees; got: %s required: %s", feeCoins, requiredFees)
+				}
+			}
+		}
+
+		// genesis transactions are delivered before the base fee is initialized
+		if ctx.BlockHeight() == 0 {
+			return feeCoins, 0, nil
+		}
+
+		priority := mempool.GetTxTip(feeCoins, gas, baseFeeKeeper.GetBaseFee(ctx))
+		return feeCoins, priority, nil
+	}
+}
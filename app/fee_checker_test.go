@@ -0,0 +1,101 @@
+package app_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	storetypes "github.com/cosmos/cosmos-sdk/store/types"
+	"github.com/cosmos/cosmos-sdk/testutil"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/julienrbrt/chain-minimal/app"
+	freetxante "github.com/julienrbrt/chain-minimal/x/freetx/ante"
+	freetxkeeper "github.com/julienrbrt/chain-minimal/x/freetx/keeper"
+	freetxtypes "github.com/julienrbrt/chain-minimal/x/freetx/types"
+)
+
+type testBaseFeeKeeper struct {
+	baseFee sdk.DecCoin
+}
+
+func (k testBaseFeeKeeper) GetBaseFee(sdk.Context) sdk.DecCoin {
+	return k.baseFee
+}
+
+// testFeeTx is a dummy implementation of FeeTx used for testing.
+type testFeeTx struct {
+	sdk.FeeTx
+
+	fee   sdk.Coins
+	gas   uint64
+	payer sdk.AccAddress
+}
+
+func (tx testFeeTx) GetFee() sdk.Coins { return tx.fee }
+
+func (tx testFeeTx) GetGas() uint64 { return tx.gas }
+
+func (tx testFeeTx) FeePayer() sdk.AccAddress { return tx.payer }
+
+func TestTxFeeChecker(t *testing.T) {
+	ctx := testutil.DefaultContext(storetypes.NewKVStoreKey("test"), storetypes.NewTransientStoreKey("transient_test")).
+		WithBlockHeight(1).
+		WithIsCheckTx(true).
+		WithMinGasPrices(sdk.NewDecCoins(sdk.NewDecCoin("mini", sdk.NewInt(2))))
+
+	checker := app.NewTxFeeChecker(testBaseFeeKeeper{baseFee: sdk.NewDecCoin("mini", sdk.NewInt(1))})
+
+	newTx := func(fee int64) sdk.Tx {
+		return testFeeTx{fee: sdk.NewCoins(sdk.NewInt64Coin("mini", fee)), gas: 100}
+	}
+
+	// the priority is the tip above the base fee
+	fee, priority, err := checker(ctx, newTx(250))
+	require.NoError(t, err)
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("mini", 250)), fee)
+	require.Equal(t, int64(150), priority)
+
+	// the node minimum gas prices are only enforced in CheckTx
+	_, _, err = checker(ctx, newTx(150))
+	require.ErrorIs(t, err, sdkerrors.ErrInsufficientFee)
+
+	_, priority, err = checker(ctx.WithIsCheckTx(false), newTx(150))
+	require.NoError(t, err)
+	require.Equal(t, int64(50), priority)
+}
+
+func TestTxFeeCheckerFreeTx(t *testing.T) {
+	key := storetypes.NewKVStoreKey(freetxtypes.StoreKey)
+	ctx := testutil.DefaultContext(key, storetypes.NewTransientStoreKey("transient_test")).
+		WithBlockHeight(1).
+		WithBlockTime(time.Unix(3600, 0)).
+		WithIsCheckTx(true).
+		WithMinGasPrices(sdk.NewDecCoins(sdk.NewDecCoin("mini", sdk.NewInt(2))))
+
+	freeTxKeeper := freetxkeeper.NewKeeper(codec.NewProtoCodec(codectypes.NewInterfaceRegistry()), key, authtypes.NewModuleAddress(govtypes.ModuleName).String())
+	require.NoError(t, freeTxKeeper.SetParams(ctx, freetxtypes.NewParams(1, time.Hour, 200_000)))
+
+	checker := app.NewTxFeeChecker(testBaseFeeKeeper{baseFee: sdk.NewDecCoin("mini", sdk.NewInt(1))})
+	anteHandler := func(ctx sdk.Context, tx sdk.Tx) error {
+		_, err := freetxante.NewFreeTxDecorator(freeTxKeeper).AnteHandle(ctx, tx, false, func(ctx sdk.Context, tx sdk.Tx, _ bool) (sdk.Context, error) {
+			_, _, err := checker(ctx, tx)
+			return ctx, err
+		})
+		return err
+	}
+
+	freeTx := testFeeTx{gas: 100, payer: sdk.AccAddress("payer_______________")}
+
+	// a free transaction accepted within the allowance is exempted from the node minimum gas prices
+	require.NoError(t, anteHandler(ctx, freeTx))
+	require.ErrorIs(t, anteHandler(ctx, freeTx), freetxtypes.ErrAllowanceExhausted)
+
+	// a fee-paying transaction is not
+	require.ErrorIs(t, anteHandler(ctx, testFeeTx{fee: sdk.NewCoins(sdk.NewInt64Coin("mini", 1)), gas: 100, payer: freeTx.payer}), sdkerrors.ErrInsufficientFee)
+}
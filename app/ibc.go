@@ -0,0 +1,118 @@
+package app
+
+import (
+	"github.com/cosmos/cosmos-sdk/baseapp"
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/module"
+	capabilitykeeper "github.com/cosmos/cosmos-sdk/x/capability/keeper"
+	"github.com/cosmos/ibc-go/v7/modules/apps/transfer"
+	ibctransferkeeper "github.com/cosmos/ibc-go/v7/modules/apps/transfer/keeper"
+	ibctransfertypes "github.com/cosmos/ibc-go/v7/modules/apps/transfer/types"
+	ibc "github.com/cosmos/ibc-go/v7/modules/core"
+	porttypes "github.com/cosmos/ibc-go/v7/modules/core/05-port/types"
+	ibcexported "github.com/cosmos/ibc-go/v7/modules/core/exported"
+	ibckeeper "github.com/cosmos/ibc-go/v7/modules/core/keeper"
+	ibctm "github.com/cosmos/ibc-go/v7/modules/light-clients/07-tendermint"
+	ibctestingtypes "github.com/cosmos/ibc-go/v7/testing/types"
+)
+
+// ibcModuleBasics are the IBC modules. They do not support depinject, so they are not part of AppConfig
+// and are registered in NewMiniApp instead (see registerIBCModules).
+var ibcModuleBasics = []module.AppModuleBasic{
+	ibc.AppModuleBasic{},
+	ibctm.AppModuleBasic{},
+	transfer.AppModuleBasic{},
+}
+
+// RegisterIBCInterfaces registers the interfaces and the amino types of the IBC modules,
+// which are not provided by AppConfig.
+func RegisterIBCInterfaces(registry codectypes.InterfaceRegistry, legacyAmino *codec.LegacyAmino) {
+	for _, basic := range ibcModuleBasics {
+		basic.RegisterInterfaces(registry)
+		basic.RegisterLegacyAminoCodec(legacyAmino)
+	}
+}
+
+// registerIBCModules creates the IBC core and ICS-20 transfer keepers alongside the keepers injected by depinject,
+// mounts their stores and registers their modules. It must be called before the app is loaded.
+func (app *MiniApp) registerIBCModules() error {
+	app.keys = sdk.NewKVStoreKeys(ibcexported.StoreKey, ibctransfertypes.StoreKey)
+	app.MountKVStores(app.keys)
+
+	// the capability keeper is sealed at the first BeginBlock, so the IBC modules are scoped before
+	app.ScopedIBCKeeper = app.CapabilityKeeper.ScopeToModule(ibcexported.ModuleName)
+	app.ScopedTransferKeeper = app.CapabilityKeeper.ScopeToModule(ibctransfertypes.ModuleName)
+
+	app.IBCKeeper = ibckeeper.NewKeeper(
+		app.appCodec,
+		app.keys[ibcexported.StoreKey],
+		app.ParamsKeeper.Subspace(ibcexported.ModuleName),
+		app.StakingKeeper,
+		app.UpgradeKeeper,
+		app.ScopedIBCKeeper,
+	)
+
+	app.TransferKeeper = ibctransferkeeper.NewKeeper(
+		app.appCodec,
+		app.keys[ibctransfertypes.StoreKey],
+		app.ParamsKeeper.Subspace(ibctransfertypes.ModuleName),
+		app.IBCKeeper.ChannelKeeper, // no middleware, the transfer module sends its packets to core IBC
+		app.IBCKeeper.ChannelKeeper,
+		&app.IBCKeeper.PortKeeper,
+		app.AccountKeeper,
+		app.BankKeeper,
+		app.ScopedTransferKeeper,
+	)
+
+	ibcRouter := porttypes.NewRouter().
+		AddRoute(ibctransfertypes.ModuleName, transfer.NewIBCModule(app.TransferKeeper))
+	app.IBCKeeper.SetRouter(ibcRouter)
+
+	modules := []module.AppModule{
+		ibc.NewAppModule(app.IBCKeeper),
+		transfer.NewAppModule(app.TransferKeeper),
+	}
+	if err := app.RegisterModules(modules...); err != nil {
+		return err
+	}
+
+	// the light clients have no module, only their interfaces are registered
+	ibctm.AppModuleBasic{}.RegisterInterfaces(app.interfaceRegistry)
+
+	// the services of the modules provided by AppConfig are registered when the app is built
+	for _, m := range modules {
+		if m, ok := m.(module.HasServices); ok {
+			m.RegisterServices(app.Configurator())
+		}
+	}
+
+	return nil
+}
+
+// GetBaseApp returns the BaseApp of the MiniApp, for the IBC testing package.
+func (app *MiniApp) GetBaseApp() *baseapp.BaseApp {
+	return app.BaseApp
+}
+
+// GetStakingKeeper returns the staking keeper, for the IBC testing package.
+func (app *MiniApp) GetStakingKeeper() ibctestingtypes.StakingKeeper {
+	return app.StakingKeeper
+}
+
+// GetIBCKeeper returns the IBC keeper, for the IBC testing package.
+func (app *MiniApp) GetIBCKeeper() *ibckeeper.Keeper {
+	return app.IBCKeeper
+}
+
+// GetScopedIBCKeeper returns the capability keeper scoped to IBC, for the IBC testing package.
+func (app *MiniApp) GetScopedIBCKeeper() capabilitykeeper.ScopedKeeper {
+	return app.ScopedIBCKeeper
+}
+
+// GetTxConfig returns the transaction config of the MiniApp, for the IBC testing package.
+func (app *MiniApp) GetTxConfig() client.TxConfig {
+	return app.txConfig
+}
@@ -0,0 +1,117 @@
+package app_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	dbm "github.com/cometbft/cometbft-db"
+	simtestutil "github.com/cosmos/cosmos-sdk/testutil/sims"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	transfertypes "github.com/cosmos/ibc-go/v7/modules/apps/transfer/types"
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	ibctesting "github.com/cosmos/ibc-go/v7/testing"
+	"github.com/stretchr/testify/require"
+
+	"github.com/julienrbrt/chain-minimal/app"
+	freetxtypes "github.com/julienrbrt/chain-minimal/x/freetx/types"
+)
+
+// newIBCCoordinator returns a coordinator of two in-process MiniApp chains, whose packets are relayed
+// by the test instead of an external relayer.
+func newIBCCoordinator(t *testing.T) (*ibctesting.Coordinator, *ibctesting.TestChain, *ibctesting.TestChain) {
+	t.Helper()
+
+	testingAppInit := ibctesting.DefaultTestingAppInit
+	t.Cleanup(func() { ibctesting.DefaultTestingAppInit = testingAppInit })
+
+	ibctesting.DefaultTestingAppInit = func() (ibctesting.TestingApp, map[string]json.RawMessage) {
+		miniApp := newApp(dbm.NewMemDB(), t.TempDir())
+		genesis := miniApp.DefaultGenesis()
+
+		// the txs of the testing package pay no fee, with the default gas limit
+		freeTxGenesis := freetxtypes.DefaultGenesisState()
+		freeTxGenesis.Params.MaxTxsPerWindow = 1_000
+		freeTxGenesis.Params.MaxGas = simtestutil.DefaultGenTxGas
+		genesis[freetxtypes.ModuleName] = miniApp.AppCodec().MustMarshalJSON(freeTxGenesis)
+
+		return miniApp, genesis
+	}
+
+	coordinator := ibctesting.NewCoordinator(t, 2)
+	return coordinator, coordinator.GetChain(ibctesting.GetChainID(1)), coordinator.GetChain(ibctesting.GetChainID(2))
+}
+
+// newTransferPath returns an unordered ICS-20 path between the two chains.
+func newTransferPath(chainA, chainB *ibctesting.TestChain) *ibctesting.Path {
+	path := ibctesting.NewPath(chainA, chainB)
+	path.EndpointA.ChannelConfig.PortID = ibctesting.TransferPort
+	path.EndpointB.ChannelConfig.PortID = ibctesting.TransferPort
+	path.EndpointA.ChannelConfig.Version = transfertypes.Version
+	path.EndpointB.ChannelConfig.Version = transfertypes.Version
+
+	return path
+}
+
+// transfer sends the coin from the sender of the source endpoint to the sender of its counterparty,
+// and relays the packet and its acknowledgement.
+func transfer(t *testing.T, source *ibctesting.Endpoint, coin sdk.Coin) {
+	t.Helper()
+
+	msg := transfertypes.NewMsgTransfer(
+		source.ChannelConfig.PortID,
+		source.ChannelID,
+		coin,
+		source.Chain.SenderAccount.GetAddress().String(),
+		source.Counterparty.Chain.SenderAccount.GetAddress().String(),
+		clienttypes.NewHeight(1, 110),
+		0,
+		"",
+	)
+
+	res, err := source.Chain.SendMsgs(msg)
+	require.NoError(t, err)
+
+	packet, err := ibctesting.ParsePacketFromEvents(res.GetEvents())
+	require.NoError(t, err)
+
+	path := &ibctesting.Path{EndpointA: source, EndpointB: source.Counterparty}
+	require.NoError(t, path.RelayPacket(packet))
+}
+
+func TestIBCTransfer(t *testing.T) {
+	coordinator, chainA, chainB := newIBCCoordinator(t)
+	appA, appB := chainA.App.(*app.MiniApp), chainB.App.(*app.MiniApp)
+
+	path := newTransferPath(chainA, chainB)
+	coordinator.Setup(path)
+
+	senderA, senderB := chainA.SenderAccount.GetAddress(), chainB.SenderAccount.GetAddress()
+	balanceA := appA.BankKeeper.GetBalance(chainA.GetContext(), senderA, sdk.DefaultBondDenom)
+	coin := sdk.NewInt64Coin(sdk.DefaultBondDenom, 100)
+
+	// the tokens sent from A are escrowed, and minted on B as vouchers of their denom trace
+	transfer(t, path.EndpointA, coin)
+
+	escrow := transfertypes.GetEscrowAddress(path.EndpointA.ChannelConfig.PortID, path.EndpointA.ChannelID)
+	require.Equal(t, coin, appA.BankKeeper.GetBalance(chainA.GetContext(), escrow, sdk.DefaultBondDenom))
+	require.Equal(t, balanceA.Sub(coin), appA.BankKeeper.GetBalance(chainA.GetContext(), senderA, sdk.DefaultBondDenom))
+
+	trace := transfertypes.ParseDenomTrace(transfertypes.GetPrefixedDenom(path.EndpointB.ChannelConfig.PortID, path.EndpointB.ChannelID, sdk.DefaultBondDenom))
+	require.Equal(t, "transfer/"+path.EndpointB.ChannelID+"/"+sdk.DefaultBondDenom, trace.GetFullDenomPath())
+
+	voucherDenom := trace.IBCDenom()
+	require.Regexp(t, "^ibc/[0-9A-F]{64}$", voucherDenom)
+	require.Equal(t, sdk.NewCoin(voucherDenom, coin.Amount), appB.BankKeeper.GetBalance(chainB.GetContext(), senderB, voucherDenom))
+
+	storedTrace, found := appB.TransferKeeper.GetDenomTrace(chainB.GetContext(), trace.Hash())
+	require.True(t, found)
+	require.Equal(t, trace, storedTrace)
+
+	// the vouchers sent back to A are burnt on B, and the escrowed tokens are released on A
+	transfer(t, path.EndpointB, sdk.NewCoin(voucherDenom, coin.Amount))
+
+	require.True(t, appB.BankKeeper.GetBalance(chainB.GetContext(), senderB, voucherDenom).IsZero())
+	require.True(t, appB.BankKeeper.GetSupply(chainB.GetContext(), voucherDenom).IsZero())
+	require.True(t, appA.BankKeeper.GetBalance(chainA.GetContext(), escrow, sdk.DefaultBondDenom).IsZero())
+	require.Equal(t, balanceA, appA.BankKeeper.GetBalance(chainA.GetContext(), senderA, sdk.DefaultBondDenom))
+}
@@ -0,0 +1,37 @@
+package app
+
+import (
+	"sort"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// InvariantResult is the result of a module invariant registered in x/crisis.
+type InvariantResult struct {
+	// Route is the route of the invariant, as module/name.
+	Route string `json:"route"`
+	// Broken reports whether the invariant is broken.
+	Broken bool `json:"broken"`
+	// Message describes the checked state.
+	Message string `json:"message,omitempty"`
+}
+
+// CheckInvariants runs all the invariants registered in x/crisis against the state of the given context.
+// Contrary to x/crisis, it does not halt on the first broken invariant but reports all of them, sorted by route.
+func (app *MiniApp) CheckInvariants(ctx sdk.Context) []InvariantResult {
+	routes := app.CrisisKeeper.Routes()
+
+	results := make([]InvariantResult, 0, len(routes))
+	for _, route := range routes {
+		msg, broken := route.Invar(ctx)
+		results = append(results, InvariantResult{
+			Route:   route.FullRoute(),
+			Broken:  broken,
+			Message: msg,
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Route < results[j].Route })
+
+	return results
+}
@@ -0,0 +1,49 @@
+package app_test
+
+import (
+	"testing"
+	"time"
+
+	dbm "github.com/cometbft/cometbft-db"
+	"github.com/cosmos/cosmos-sdk/store/prefix"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/julienrbrt/chain-minimal/app"
+)
+
+// brokenRoutes returns the routes of the broken invariants.
+func brokenRoutes(results []app.InvariantResult) []string {
+	var routes []string
+	for _, result := range results {
+		if result.Broken {
+			routes = append(routes, result.Route)
+		}
+	}
+
+	return routes
+}
+
+func TestCheckInvariants(t *testing.T) {
+	miniApp := newApp(dbm.NewMemDB(), t.TempDir())
+	initChain(t, miniApp)
+
+	ctx := nextBlock(miniApp, time.Now().UTC(), nil)
+	results := miniApp.CheckInvariants(ctx)
+	require.Empty(t, brokenRoutes(results))
+
+	var routes []string
+	for _, result := range results {
+		routes = append(routes, result.Route)
+	}
+	require.Subset(t, routes, []string{"bank/total-supply", "staking/module-accounts", "distribution/module-account"})
+
+	// a supply not matching the balances breaks the bank total supply invariant
+	supply := miniApp.BankKeeper.GetSupply(ctx, sdk.DefaultBondDenom)
+	bz, err := supply.Amount.AddRaw(1).Marshal()
+	require.NoError(t, err)
+	prefix.NewStore(ctx.KVStore(miniApp.GetKey(banktypes.StoreKey)), banktypes.SupplyKey).Set([]byte(sdk.DefaultBondDenom), bz)
+
+	require.Equal(t, []string{"bank/total-supply"}, brokenRoutes(miniApp.CheckInvariants(ctx)))
+}
@@ -0,0 +1,60 @@
+package app_test
+
+import (
+	"testing"
+	"time"
+
+	dbm "github.com/cometbft/cometbft-db"
+	abci "github.com/cometbft/cometbft/abci/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	distrkeeper "github.com/cosmos/cosmos-sdk/x/distribution/keeper"
+	distrtypes "github.com/cosmos/cosmos-sdk/x/distribution/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMintDistribution(t *testing.T) {
+	miniApp := newApp(dbm.NewMemDB(), t.TempDir())
+	delegator := initChain(t, miniApp)
+	blockTime := time.Now().UTC()
+
+	ctx := nextBlock(miniApp, blockTime, nil)
+	val := miniApp.StakingKeeper.GetAllValidators(ctx)[0]
+	vote := validatorVote(val, true)
+
+	params := miniApp.MintKeeper.GetParams(ctx)
+	supply := miniApp.BankKeeper.GetSupply(ctx, params.MintDenom)
+	endBlock(miniApp, ctx)
+
+	feeCollector := miniApp.AccountKeeper.GetModuleAddress(authtypes.FeeCollectorName)
+	rewards := sdk.ZeroDec()
+	for i := 0; i < 3; i++ {
+		blockTime = blockTime.Add(5 * time.Second)
+		ctx = nextBlock(miniApp, blockTime, []abci.VoteInfo{vote})
+
+		// each block mints its provision
+		provision := miniApp.MintKeeper.GetMinter(ctx).BlockProvision(params)
+		require.True(t, provision.IsPositive())
+
+		newSupply := miniApp.BankKeeper.GetSupply(ctx, params.MintDenom)
+		require.Equal(t, supply.Add(provision), newSupply)
+		supply = newSupply
+
+		// which is distributed in the same block, mint running before distribution
+		require.True(t, miniApp.BankKeeper.GetBalance(ctx, feeCollector, params.MintDenom).IsZero())
+
+		outstanding := miniApp.DistrKeeper.GetValidatorOutstandingRewards(ctx, val.GetOperator()).Rewards.AmountOf(params.MintDenom)
+		require.True(t, outstanding.GT(rewards))
+		rewards = outstanding
+
+		endBlock(miniApp, ctx)
+	}
+
+	// the rewards accrue to the delegator of the validator
+	res, err := distrkeeper.NewQuerier(miniApp.DistrKeeper).DelegationRewards(sdk.WrapSDKContext(ctx), &distrtypes.QueryDelegationRewardsRequest{
+		DelegatorAddress: delegator.String(),
+		ValidatorAddress: val.GetOperator().String(),
+	})
+	require.NoError(t, err)
+	require.True(t, res.Rewards.AmountOf(params.MintDenom).IsPositive())
+}
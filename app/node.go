@@ -0,0 +1,26 @@
+package app
+
+import (
+	"context"
+
+	"github.com/cosmos/cosmos-sdk/client/grpc/node"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/julienrbrt/chain-minimal/mempool"
+)
+
+var _ node.ServiceServer = nodeQueryServer{}
+
+// nodeQueryServer is the node gRPC service, returning the dynamic minimum gas prices
+// instead of the static ones, so that clients can price their transactions during congestion.
+type nodeQueryServer struct {
+	minGasPrices *mempool.DynamicMinGasPrices
+}
+
+func (s nodeQueryServer) Config(ctx context.Context, _ *node.ConfigRequest) (*node.ConfigResponse, error) {
+	sdkCtx := sdk.UnwrapSDKContext(ctx)
+
+	return &node.ConfigResponse{
+		MinimumGasPrice: s.minGasPrices.MinGasPrices(sdkCtx.MinGasPrices()).String(),
+	}, nil
+}
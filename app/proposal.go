@@ -0,0 +1,368 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+
+	"github.com/cosmos/cosmos-sdk/baseapp"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkmempool "github.com/cosmos/cosmos-sdk/types/mempool"
+
+	"github.com/julienrbrt/chain-minimal/mempool"
+)
+
+// ProposalHandler builds block proposals from the app-side mempool like the SDK default proposal handler,
+// with the following additions:
+//
+//   - The bundles of a mempool.BundleMempool are included atomically: the transactions of a bundle are executed
+//     in order, messages included, on a branch of the proposal state, and the bundle is included contiguously
+//     only when all of them succeed. Otherwise the whole bundle is dropped from the proposal and the mempool.
+//   - When a free transaction reservation is set, zero-fee transactions are included first, up to the reserved
+//     number of transactions and gas, whatever the paid load. They cannot use more than the reservation.
+//   - The gas of the selected transactions is bounded by the block max gas.
+//   - The transactions too large for the rest of the block are skipped before being verified, when the mempool
+//     records their size (see mempool.SizedIterator), so that smaller transactions can still fill the block.
+//     With a RecordedTxEncoder, the transactions are verified with the bytes recorded by the mempool, without
+//     being encoded again.
+//   - When a time budget is set, the selection stops once it is spent, and the block is proposed with the
+//     transactions selected so far, so that a large mempool cannot make the proposer miss its round.
+//
+// NOTE: Atomicity and reservation are enforced when this node proposes. The other validators process the block
+// with the default ProcessProposal handler, which only runs the ante handler of each transaction.
+type ProposalHandler struct {
+	mempool     sdkmempool.Mempool
+	txVerifier  baseapp.ProposalTxVerifier
+	anteHandler sdk.AnteHandler
+	msgRouter   *baseapp.MsgServiceRouter
+
+	// freeTxSlots and freeTxGas are the number of transactions and the gas reserved to zero-fee transactions
+	freeTxSlots int
+	freeTxGas   uint64
+	// timeBudget is the maximum duration of the transaction selection, 0 for unlimited
+	timeBudget time.Duration
+	// txEncoder is the tx encoder of the tx verifier, nil when it does not use the recorded bytes
+	txEncoder *RecordedTxEncoder
+}
+
+// ProposalHandlerOption is an option of the ProposalHandler.
+type ProposalHandlerOption func(*ProposalHandler)
+
+// ProposalHandlerFreeTxReservationOpt Option to reserve a number of transactions (slots) and/or an amount of gas
+// per block to zero-fee transactions. A value of 0 does not limit the reservation on that dimension, and a
+// reservation of 0 slots and 0 gas disables it.
+func ProposalHandlerFreeTxReservationOpt(slots int, gas uint64) ProposalHandlerOption {
+	return func(h *ProposalHandler) {
+		h.freeTxSlots = slots
+		h.freeTxGas = gas
+	}
+}
+
+// ProposalHandlerTimeBudgetOpt Option to bound the duration of the transaction selection of PrepareProposal.
+// A value of 0 does not bound it.
+func ProposalHandlerTimeBudgetOpt(budget time.Duration) ProposalHandlerOption {
+	return func(h *ProposalHandler) {
+		h.timeBudget = budget
+	}
+}
+
+// ProposalHandlerRecordedTxEncoderOpt Option to verify the transactions with the bytes recorded by the mempool,
+// given the tx encoder of the tx verifier (see RecordedTxEncoder).
+func ProposalHandlerRecordedTxEncoderOpt(txEncoder *RecordedTxEncoder) ProposalHandlerOption {
+	return func(h *ProposalHandler) {
+		h.txEncoder = txEncoder
+	}
+}
+
+// RecordedTxEncoder is the tx encoder of BaseApp returning the bytes recorded by the mempool for the transaction
+// verified by the ProposalHandler, instead of encoding it again. BaseApp only encodes transactions in
+// PrepareProposalVerifyTx, which is called from the serialized PrepareProposal ABCI call.
+type RecordedTxEncoder struct {
+	txEncoder sdk.TxEncoder
+	// recorded is the encoded transaction being verified, nil when unknown
+	recorded []byte
+}
+
+// NewRecordedTxEncoder creates a RecordedTxEncoder encoding the transactions without recorded bytes with the given encoder.
+func NewRecordedTxEncoder(txEncoder sdk.TxEncoder) *RecordedTxEncoder {
+	return &RecordedTxEncoder{txEncoder: txEncoder}
+}
+
+// Encode returns the recorded bytes of the transaction being verified if any, or else encodes the transaction.
+func (e *RecordedTxEncoder) Encode(tx sdk.Tx) ([]byte, error) {
+	if e.recorded != nil {
+		return e.recorded, nil
+	}
+
+	return e.txEncoder(tx)
+}
+
+// NewProposalHandler creates a new ProposalHandler.
+func NewProposalHandler(mp sdkmempool.Mempool, txVerifier baseapp.ProposalTxVerifier, anteHandler sdk.AnteHandler, msgRouter *baseapp.MsgServiceRouter, opts ...ProposalHandlerOption) *ProposalHandler {
+	h := &ProposalHandler{
+		mempool:     mp,
+		txVerifier:  txVerifier,
+		anteHandler: anteHandler,
+		msgRouter:   msgRouter,
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// proposal is a block proposal being built.
+type proposal struct {
+	txs     [][]byte
+	size    int64
+	gas     uint64
+	maxSize int64
+	// maxGas is the block max gas, 0 for unlimited
+	maxGas uint64
+}
+
+func newProposal(ctx sdk.Context, maxTxBytes int64) *proposal {
+	p := &proposal{maxSize: maxTxBytes}
+	if params := ctx.ConsensusParams(); params != nil && params.Block != nil && params.Block.MaxGas > 0 {
+		p.maxGas = uint64(params.Block.MaxGas)
+	}
+
+	return p
+}
+
+// fits returns whether transactions of the given size and gas fit in the rest of the block.
+func (p *proposal) fits(size int64, gas uint64) bool {
+	return p.size+size <= p.maxSize && (p.maxGas == 0 || p.gas+gas <= p.maxGas)
+}
+
+func (p *proposal) add(gas uint64, txs ...[]byte) {
+	for _, bz := range txs {
+		p.size += int64(len(bz))
+	}
+
+	p.gas += gas
+	p.txs = append(p.txs, txs...)
+}
+
+// PrepareProposalHandler returns the PrepareProposal handler.
+func (h *ProposalHandler) PrepareProposalHandler() sdk.PrepareProposalHandler {
+	return func(ctx sdk.Context, req abci.RequestPrepareProposal) abci.ResponsePrepareProposal {
+		p := newProposal(ctx, req.MaxTxBytes)
+
+		// the mempool iterators stop once the context is done
+		if h.timeBudget > 0 {
+			parent := ctx.Context()
+			if parent == nil {
+				parent = context.Background()
+			}
+
+			goCtx, cancel := context.WithTimeout(parent, h.timeBudget)
+			defer cancel()
+
+			ctx = ctx.WithContext(goCtx)
+		}
+
+		reserved := h.freeTxSlots > 0 || h.freeTxGas > 0
+		if reserved && !h.selectFreeTxs(ctx, req, p) {
+			return abci.ResponsePrepareProposal{Txs: p.txs}
+		}
+
+		// the invalid transactions are removed once the iteration is over, as it is not safe to remove them while iterating
+		var invalidTxs []sdk.Tx
+		defer func() {
+			for _, tx := range invalidTxs {
+				h.remove(tx)
+			}
+		}()
+
+		iterator := h.mempool.Select(ctx, req.Txs)
+
+		for iterator != nil {
+			var bundle *mempool.Bundle
+			if bundleIterator, ok := iterator.(mempool.BundleIterator); ok {
+				bundle = bundleIterator.Bundle()
+			}
+
+			if bundle == nil {
+				memTx := iterator.Tx()
+				size, recorded := mempool.IteratorSize(iterator), mempool.IteratorTxBytes(iterator)
+				iterator = iterator.Next()
+
+				// zero-fee transactions only use the reserved capacity
+				if reserved && mempool.IsZeroFee(memTx) {
+					continue
+				}
+
+				// the size recorded by the mempool saves verifying (i.e. encoding) a transaction too large for the rest of the block
+				gas := txGas(memTx)
+				if !p.fits(size, gas) {
+					continue
+				}
+
+				bz, err := h.verifyTx(memTx, recorded)
+				if err != nil {
+					invalidTxs = append(invalidTxs, memTx)
+					continue
+				}
+
+				if !p.fits(int64(len(bz)), gas) {
+					// We've reached capacity per req.MaxTxBytes so we cannot select any more transactions.
+					break
+				}
+
+				p.add(gas, bz)
+				continue
+			}
+
+			// skip the other transactions of the bundle, it is handled as a whole
+			for i := 0; i < len(bundle.Txs) && iterator != nil; i++ {
+				iterator = iterator.Next()
+			}
+
+			// a bundle too large for the rest of the block is kept for a next block
+			var gas uint64
+			for _, tx := range bundle.Txs {
+				gas += txGas(tx)
+			}
+
+			if !p.fits(bundle.Size(), gas) {
+				continue
+			}
+
+			if err := h.executeBundle(ctx, bundle); err != nil {
+				ctx.Logger().Info("dropping bundle", "id", bundle.ID, "err", err)
+				invalidTxs = append(invalidTxs, bundle.Txs[0])
+				continue
+			}
+
+			p.add(gas, bundle.TxBytes...)
+		}
+
+		if mempool.Done(ctx) {
+			ctx.Logger().Info("prepare proposal time budget spent", "budget", h.timeBudget, "txs", len(p.txs))
+		}
+
+		return abci.ResponsePrepareProposal{Txs: p.txs}
+	}
+}
+
+// selectFreeTxs adds the zero-fee transactions of the mempool to the proposal, up to the reservation.
+// It returns false when the proposal is full.
+func (h *ProposalHandler) selectFreeTxs(ctx sdk.Context, req abci.RequestPrepareProposal, p *proposal) bool {
+	var (
+		count int
+		gas   uint64
+	)
+
+	for iterator := h.mempool.Select(ctx, req.Txs); iterator != nil; iterator = iterator.Next() {
+		if h.freeTxSlots > 0 && count >= h.freeTxSlots {
+			break
+		}
+
+		// bundles are included as a whole with the other transactions
+		if bundleIterator, ok := iterator.(mempool.BundleIterator); ok && bundleIterator.Bundle() != nil {
+			continue
+		}
+
+		memTx := iterator.Tx()
+		if !mempool.IsZeroFee(memTx) {
+			continue
+		}
+
+		memTxGas := txGas(memTx)
+		if (h.freeTxGas > 0 && gas+memTxGas > h.freeTxGas) || !p.fits(mempool.IteratorSize(iterator), memTxGas) {
+			continue
+		}
+
+		// an invalid free transaction is kept, as it can be valid after the paid transactions
+		// of the same sender (e.g. with a higher sequence), it is removed by the recheck otherwise
+		bz, err := h.verifyTx(memTx, mempool.IteratorTxBytes(iterator))
+		if err != nil {
+			continue
+		}
+
+		if !p.fits(int64(len(bz)), memTxGas) {
+			return false
+		}
+
+		p.add(memTxGas, bz)
+		count++
+		gas += memTxGas
+	}
+
+	return true
+}
+
+// verifyTx verifies the transaction, with its bytes recorded by the mempool if any, and returns its bytes.
+func (h *ProposalHandler) verifyTx(tx sdk.Tx, recorded []byte) ([]byte, error) {
+	if h.txEncoder != nil && recorded != nil {
+		h.txEncoder.recorded = recorded
+		defer func() { h.txEncoder.recorded = nil }()
+	}
+
+	return h.txVerifier.PrepareProposalVerifyTx(tx)
+}
+
+// executeBundle executes the transactions of the bundle on a branch of the proposal state,
+// and writes the branch back only when all of them succeed.
+func (h *ProposalHandler) executeBundle(ctx sdk.Context, bundle *mempool.Bundle) error {
+	cacheCtx, write := ctx.CacheContext()
+
+	for i, tx := range bundle.Txs {
+		if err := h.executeTx(cacheCtx.WithTxBytes(bundle.TxBytes[i]), tx); err != nil {
+			return fmt.Errorf("transaction %d: %w", i, err)
+		}
+	}
+
+	write()
+
+	return nil
+}
+
+// executeTx runs the ante handler and the messages of the transaction.
+func (h *ProposalHandler) executeTx(ctx sdk.Context, tx sdk.Tx) (err error) {
+	// the gas meter set by the ante handler panics when running out of gas
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	ctx, err = h.anteHandler(ctx, tx, false)
+	if err != nil {
+		return err
+	}
+
+	for _, msg := range tx.GetMsgs() {
+		handler := h.msgRouter.Handler(msg)
+		if handler == nil {
+			return fmt.Errorf("no message handler found for %s", sdk.MsgTypeURL(msg))
+		}
+
+		if _, err := handler(ctx, msg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (h *ProposalHandler) remove(tx sdk.Tx) {
+	if err := h.mempool.Remove(tx); err != nil && !errors.Is(err, sdkmempool.ErrTxNotFound) {
+		panic(err)
+	}
+}
+
+// txGas returns the gas limit of the transaction.
+func txGas(tx sdk.Tx) uint64 {
+	if feeTx, ok := tx.(sdk.FeeTx); ok {
+		return feeTx.GetGas()
+	}
+
+	return 0
+}
@@ -0,0 +1,256 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/libs/log"
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	moduletestutil "github.com/cosmos/cosmos-sdk/types/module/testutil"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	"github.com/cosmos/cosmos-sdk/x/bank"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/julienrbrt/chain-minimal/app"
+	"github.com/julienrbrt/chain-minimal/mempool"
+)
+
+// priorityTxVerifier verifies transactions by computing their priority, like the ante handler TxFeeChecker.
+type priorityTxVerifier struct {
+	txEncoder    sdk.TxEncoder
+	priorityFunc mempool.PriorityFunc
+}
+
+func (v priorityTxVerifier) PrepareProposalVerifyTx(tx sdk.Tx) ([]byte, error) {
+	if _, err := v.priorityFunc(sdk.Context{}, tx); err != nil {
+		return nil, err
+	}
+
+	return v.txEncoder(tx)
+}
+
+func (v priorityTxVerifier) ProcessProposalVerifyTx([]byte) (sdk.Tx, error) {
+	panic("not implemented")
+}
+
+// slowPriority computes the priority with the given function, taking the given delay at its nth call.
+func slowPriority(fn mempool.PriorityFunc, nth int, delay time.Duration) mempool.PriorityFunc {
+	var calls int
+	return func(ctx context.Context, tx sdk.Tx) (int64, error) {
+		if calls++; calls == nth {
+			time.Sleep(delay)
+		}
+
+		return fn(ctx, tx)
+	}
+}
+
+func TestPrepareProposalTimeBudget(t *testing.T) {
+	const (
+		txCount = 40
+		budget  = 20 * time.Millisecond
+	)
+
+	txConfig := moduletestutil.MakeTestEncodingConfig(bank.AppModuleBasic{}).TxConfig
+	workload, err := mempool.GenerateWorkload(mempool.SimConfig{
+		Senders:         txCount,
+		Nonces:          1,
+		FeeDistribution: mempool.FeeDistributionUniform,
+		MinFee:          1,
+		MaxFee:          100,
+		ArrivalRate:     1,
+		BlockSize:       1,
+		MaxBlocks:       1,
+		Denom:           "mini",
+	}, txConfig)
+	require.NoError(t, err)
+
+	pool := mempool.NewFeeMempool(log.NewNopLogger())
+	for _, simTx := range workload {
+		require.NoError(t, pool.Insert(sdk.Context{}, simTx.Tx))
+	}
+
+	ctx := sdk.NewContext(nil, cmtproto.Header{}, false, log.NewNopLogger())
+	req := abci.RequestPrepareProposal{MaxTxBytes: 1 << 20}
+
+	// without budget, the whole mempool is selected, however slow the priority computation
+	verifier := priorityTxVerifier{txEncoder: txConfig.TxEncoder(), priorityFunc: slowPriority(mempool.FeePriority(nil), 10, budget)}
+	handler := app.NewProposalHandler(pool, verifier, nil, nil).PrepareProposalHandler()
+	require.Len(t, handler(ctx, req).Txs, txCount)
+
+	// with a budget, the block is proposed with the transactions selected in time: the 10th priority computation
+	// spends the budget, and only the transaction already selected at that time is verified after it
+	verifier.priorityFunc = slowPriority(mempool.FeePriority(nil), 10, budget)
+	handler = app.NewProposalHandler(pool, verifier, nil, nil, app.ProposalHandlerTimeBudgetOpt(budget)).PrepareProposalHandler()
+	require.Len(t, handler(ctx, req).Txs, 11)
+}
+
+// rejectingTxVerifier rejects the given transaction.
+type rejectingTxVerifier struct {
+	txEncoder sdk.TxEncoder
+	invalid   sdk.Tx
+}
+
+func (v rejectingTxVerifier) PrepareProposalVerifyTx(tx sdk.Tx) ([]byte, error) {
+	if tx == v.invalid {
+		return nil, errors.New("invalid transaction")
+	}
+
+	return v.txEncoder(tx)
+}
+
+func (v rejectingTxVerifier) ProcessProposalVerifyTx([]byte) (sdk.Tx, error) {
+	panic("not implemented")
+}
+
+func TestPrepareProposalInvalidTx(t *testing.T) {
+	const txCount = 5
+
+	txConfig := moduletestutil.MakeTestEncodingConfig(bank.AppModuleBasic{}).TxConfig
+	workload, err := mempool.GenerateWorkload(mempool.SimConfig{
+		Senders:         txCount,
+		Nonces:          1,
+		FeeDistribution: mempool.FeeDistributionUniform,
+		MinFee:          1,
+		MaxFee:          100,
+		ArrivalRate:     1,
+		BlockSize:       1,
+		MaxBlocks:       1,
+		Denom:           "mini",
+	}, txConfig)
+	require.NoError(t, err)
+
+	pool := mempool.NewFeeMempool(log.NewNopLogger())
+	for _, simTx := range workload {
+		require.NoError(t, pool.Insert(sdk.Context{}, simTx.Tx))
+	}
+
+	var ordered []sdk.Tx
+	for it := pool.Select(sdk.Context{}, nil); it != nil; it = it.Next() {
+		ordered = append(ordered, it.Tx())
+	}
+	require.Len(t, ordered, txCount)
+
+	// an invalid transaction in the middle of the mempool is removed, without skipping the next ones
+	verifier := rejectingTxVerifier{txEncoder: txConfig.TxEncoder(), invalid: ordered[2]}
+	ctx := sdk.NewContext(nil, cmtproto.Header{}, false, log.NewNopLogger())
+	res := app.NewProposalHandler(pool, verifier, nil, nil).PrepareProposalHandler()(ctx, abci.RequestPrepareProposal{MaxTxBytes: 1 << 20})
+
+	var expected [][]byte
+	for i, tx := range ordered {
+		if i == 2 {
+			continue
+		}
+
+		bz, err := txConfig.TxEncoder()(tx)
+		require.NoError(t, err)
+		expected = append(expected, bz)
+	}
+	require.Equal(t, expected, res.Txs)
+	require.Equal(t, txCount-1, pool.CountTx())
+}
+
+// newTestTx returns an unsigned bank send of the given sender, paying the given fee.
+func newTestTx(t *testing.T, txConfig client.TxConfig, sender int, fee int64, gas uint64) sdk.Tx {
+	t.Helper()
+
+	pubKey := secp256k1.GenPrivKeyFromSecret([]byte(fmt.Sprintf("sender-%d", sender))).PubKey()
+	addr := sdk.AccAddress(pubKey.Address())
+
+	txBuilder := txConfig.NewTxBuilder()
+	require.NoError(t, txBuilder.SetMsgs(banktypes.NewMsgSend(addr, addr, sdk.NewCoins(sdk.NewInt64Coin("mini", 1)))))
+	txBuilder.SetGasLimit(gas)
+	txBuilder.SetFeeAmount(sdk.NewCoins(sdk.NewInt64Coin("mini", fee)))
+	require.NoError(t, txBuilder.SetSignatures(signing.SignatureV2{
+		PubKey: pubKey,
+		Data:   &signing.SingleSignatureData{SignMode: signing.SignMode_SIGN_MODE_DIRECT},
+	}))
+
+	return txBuilder.GetTx()
+}
+
+func TestPrepareProposalFreeTxReservation(t *testing.T) {
+	txConfig := moduletestutil.MakeTestEncodingConfig(bank.AppModuleBasic{}).TxConfig
+	verifier := rejectingTxVerifier{txEncoder: txConfig.TxEncoder()}
+	ctx := sdk.NewContext(nil, cmtproto.Header{}, false, log.NewNopLogger())
+
+	pool := mempool.NewFeeMempool(log.NewNopLogger())
+	free := []sdk.Tx{newTestTx(t, txConfig, 0, 0, 100_000), newTestTx(t, txConfig, 1, 0, 100_000), newTestTx(t, txConfig, 2, 0, 100_000)}
+	paid := []sdk.Tx{newTestTx(t, txConfig, 3, 300, 100_000), newTestTx(t, txConfig, 4, 200, 100_000), newTestTx(t, txConfig, 5, 100, 100_000)}
+	for _, tx := range append(append([]sdk.Tx{}, free...), paid...) {
+		require.NoError(t, pool.Insert(sdk.Context{}, tx))
+	}
+
+	encode := func(txs ...sdk.Tx) [][]byte {
+		var bzs [][]byte
+		for _, tx := range txs {
+			bz, err := txConfig.TxEncoder()(tx)
+			require.NoError(t, err)
+			bzs = append(bzs, bz)
+		}
+		return bzs
+	}
+	txSize := int64(len(encode(paid[0])[0]))
+
+	// the free transactions are included first, up to the reserved slots, and never beyond
+	handler := app.NewProposalHandler(pool, verifier, nil, nil, app.ProposalHandlerFreeTxReservationOpt(2, 0)).PrepareProposalHandler()
+	res := handler(ctx, abci.RequestPrepareProposal{MaxTxBytes: 1 << 20})
+	require.Equal(t, encode(free[0], free[1], paid[0], paid[1], paid[2]), res.Txs)
+
+	// the reservation holds when the paid transactions could fill the block
+	res = handler(ctx, abci.RequestPrepareProposal{MaxTxBytes: 3 * txSize})
+	require.Equal(t, encode(free[0], free[1], paid[0]), res.Txs)
+
+	// the reserved gas bounds the free transactions too
+	handler = app.NewProposalHandler(pool, verifier, nil, nil, app.ProposalHandlerFreeTxReservationOpt(0, 150_000)).PrepareProposalHandler()
+	res = handler(ctx, abci.RequestPrepareProposal{MaxTxBytes: 1 << 20})
+	require.Equal(t, encode(free[0], paid[0], paid[1], paid[2]), res.Txs)
+
+	// without reservation, free transactions compete with the paid ones
+	handler = app.NewProposalHandler(pool, verifier, nil, nil).PrepareProposalHandler()
+	res = handler(ctx, abci.RequestPrepareProposal{MaxTxBytes: 3 * txSize})
+	require.Equal(t, encode(paid...), res.Txs)
+}
+
+func TestPrepareProposalRecordedTxBytes(t *testing.T) {
+	txConfig := moduletestutil.MakeTestEncodingConfig(bank.AppModuleBasic{}).TxConfig
+	ctx := sdk.NewContext(nil, cmtproto.Header{}, false, log.NewNopLogger())
+	req := abci.RequestPrepareProposal{MaxTxBytes: 1 << 20}
+
+	// the txs are inserted with their CheckTx bytes, like BaseApp does
+	pool := mempool.NewFeeMempool(log.NewNopLogger())
+	var recorded [][]byte
+	for i := 0; i < 3; i++ {
+		tx := newTestTx(t, txConfig, i, int64(300-100*i), 100_000)
+		bz, err := txConfig.TxEncoder()(tx)
+		require.NoError(t, err)
+
+		require.NoError(t, pool.Insert(sdk.Context{}.WithTxBytes(bz), tx))
+		recorded = append(recorded, bz)
+	}
+
+	var encoded int
+	txEncoder := app.NewRecordedTxEncoder(func(tx sdk.Tx) ([]byte, error) {
+		encoded++
+		return txConfig.TxEncoder()(tx)
+	})
+	verifier := rejectingTxVerifier{txEncoder: txEncoder.Encode}
+
+	// the txs are verified and proposed with the recorded bytes
+	handler := app.NewProposalHandler(pool, verifier, nil, nil, app.ProposalHandlerRecordedTxEncoderOpt(txEncoder)).PrepareProposalHandler()
+	require.Equal(t, recorded, handler(ctx, req).Txs)
+	require.Zero(t, encoded)
+
+	// while they are encoded again without the option
+	handler = app.NewProposalHandler(pool, verifier, nil, nil).PrepareProposalHandler()
+	require.Equal(t, recorded, handler(ctx, req).Txs)
+	require.Equal(t, 3, encoded)
+}
@@ -0,0 +1,286 @@
+package app_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+	"testing"
+
+	dbm "github.com/cometbft/cometbft-db"
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/libs/log"
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	"github.com/cosmos/cosmos-sdk/baseapp"
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/cosmos/cosmos-sdk/server"
+	"github.com/cosmos/cosmos-sdk/store"
+	simtestutil "github.com/cosmos/cosmos-sdk/testutil/sims"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	simtypes "github.com/cosmos/cosmos-sdk/types/simulation"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	authzkeeper "github.com/cosmos/cosmos-sdk/x/authz/keeper"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	capabilitytypes "github.com/cosmos/cosmos-sdk/x/capability/types"
+	distrtypes "github.com/cosmos/cosmos-sdk/x/distribution/types"
+	evidencetypes "github.com/cosmos/cosmos-sdk/x/evidence/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant"
+	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types"
+	minttypes "github.com/cosmos/cosmos-sdk/x/mint/types"
+	paramstypes "github.com/cosmos/cosmos-sdk/x/params/types"
+	"github.com/cosmos/cosmos-sdk/x/simulation"
+	simcli "github.com/cosmos/cosmos-sdk/x/simulation/client/cli"
+	slashingtypes "github.com/cosmos/cosmos-sdk/x/slashing/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	ibctransfertypes "github.com/cosmos/ibc-go/v7/modules/apps/transfer/types"
+	ibcexported "github.com/cosmos/ibc-go/v7/modules/core/exported"
+	"github.com/stretchr/testify/require"
+
+	"github.com/julienrbrt/chain-minimal/app"
+	"github.com/julienrbrt/chain-minimal/mempool"
+	basefeetypes "github.com/julienrbrt/chain-minimal/x/basefee/types"
+	freetxtypes "github.com/julienrbrt/chain-minimal/x/freetx/types"
+)
+
+// simChainID is the chain-id of the simulated chains.
+const simChainID = "simulation-app"
+
+// the simulation flags (e.g. -Enabled, -NumBlocks, -Seed) are registered for every run
+func init() {
+	simcli.GetSimulatorFlags()
+}
+
+// newSimApp creates a MiniApp for the simulations on the given database and home directory.
+func newSimApp(logger log.Logger, db dbm.DB, home string, baseAppOptions ...func(*baseapp.BaseApp)) *app.MiniApp {
+	appOptions := simtestutil.AppOptionsMap{
+		flags.FlagHome:            home,
+		server.FlagInvCheckPeriod: simcli.FlagPeriodValue,
+		mempool.FlagMempoolType:   mempool.TypeNone,
+		mempool.FlagMaxBundles:    0,
+		mempool.FlagRecheckBudget: -1,
+	}
+
+	return app.NewMiniApp(logger, db, nil, true, appOptions, append(baseAppOptions, baseapp.SetChainID(simChainID))...)
+}
+
+// fauxMerkleModeOpt uses a dbStoreAdapter instead of an IAVL store, for faster simulations.
+func fauxMerkleModeOpt(bapp *baseapp.BaseApp) {
+	bapp.SetFauxMerkleMode()
+}
+
+// interBlockCacheOpt sets the persistent inter-block write-through cache.
+func interBlockCacheOpt() func(*baseapp.BaseApp) {
+	return baseapp.SetInterBlockCache(store.NewCommitKVStoreCacheManager())
+}
+
+// setupSimulation returns the config from the simulation flags and a database for the simulation,
+// skipping the test unless the simulations are enabled.
+func setupSimulation(t *testing.T, dirPrefix, dbName string) (simtypes.Config, dbm.DB, log.Logger) {
+	t.Helper()
+
+	config := simcli.NewConfigFromFlags()
+	config.ChainID = simChainID
+
+	db, dir, logger, skip, err := simtestutil.SetupSimulation(config, dirPrefix, dbName, simcli.FlagVerboseValue, simcli.FlagEnabledValue)
+	if skip {
+		t.Skip("skipping application simulation")
+	}
+	require.NoError(t, err, "simulation setup failed")
+
+	t.Cleanup(func() {
+		require.NoError(t, db.Close())
+		require.NoError(t, os.RemoveAll(dir))
+	})
+
+	return config, db, logger
+}
+
+// simulate runs a randomized simulation of the app from the seed of the config.
+func simulate(t *testing.T, miniApp *app.MiniApp, config simtypes.Config) (bool, simtypes.Params, error) {
+	t.Helper()
+
+	return simulation.SimulateFromSeed(
+		t,
+		os.Stdout,
+		miniApp.BaseApp,
+		simtestutil.AppStateFn(miniApp.AppCodec(), miniApp.SimulationManager(), miniApp.DefaultGenesis()),
+		simtypes.RandomAccounts,
+		simtestutil.SimulationOperations(miniApp, miniApp.AppCodec(), config),
+		app.BlockedAddresses(),
+		config,
+		miniApp.AppCodec(),
+	)
+}
+
+// simulateAndExport runs a randomized simulation of a new app and exports its state and parameters when requested
+// by the flags. It returns the app and its config, and whether the simulation stopped early.
+func simulateAndExport(t *testing.T, dirPrefix, dbName string) (*app.MiniApp, simtypes.Config, bool) {
+	t.Helper()
+
+	config, db, logger := setupSimulation(t, dirPrefix, dbName)
+	miniApp := newSimApp(logger, db, t.TempDir(), fauxMerkleModeOpt)
+
+	stopEarly, simParams, simErr := simulate(t, miniApp, config)
+
+	// export state and simParams before the simulation error is checked
+	require.NoError(t, simtestutil.CheckExportSimulation(miniApp, config, simParams))
+	require.NoError(t, simErr)
+
+	if config.Commit {
+		simtestutil.PrintStats(db)
+	}
+
+	return miniApp, config, stopEarly
+}
+
+func TestFullAppSimulation(t *testing.T) {
+	simulateAndExport(t, "leveldb-app-sim", "Simulation")
+}
+
+func TestAppImportExport(t *testing.T) {
+	miniApp, _, _ := simulateAndExport(t, "leveldb-app-sim", "Simulation")
+
+	t.Log("exporting genesis...")
+	exported, err := miniApp.ExportAppStateAndValidators(false, []string{}, []string{})
+	require.NoError(t, err)
+
+	t.Log("importing genesis...")
+	_, newDB, _ := setupSimulation(t, "leveldb-app-sim-2", "Simulation-2")
+	newApp := newSimApp(log.NewNopLogger(), newDB, t.TempDir(), fauxMerkleModeOpt)
+
+	var genesisState map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(exported.AppState, &genesisState))
+
+	ctxA := miniApp.NewContext(true, cmtproto.Header{Height: miniApp.LastBlockHeight()})
+	ctxB := newApp.NewContext(true, cmtproto.Header{Height: miniApp.LastBlockHeight()})
+
+	initGenesis(t, newApp, ctxB, genesisState)
+	newApp.StoreConsensusParams(ctxB, exported.ConsensusParams)
+
+	t.Log("comparing stores...")
+	storeKeysPrefixes := []struct {
+		name     string
+		prefixes [][]byte
+	}{
+		{authtypes.StoreKey, [][]byte{}},
+		{
+			stakingtypes.StoreKey, [][]byte{
+				stakingtypes.UnbondingQueueKey, stakingtypes.RedelegationQueueKey, stakingtypes.ValidatorQueueKey,
+				stakingtypes.HistoricalInfoKey, stakingtypes.UnbondingIDKey, stakingtypes.UnbondingIndexKey,
+				stakingtypes.UnbondingTypeKey, stakingtypes.ValidatorUpdatesKey,
+			},
+		}, // ordering may change but it doesn't matter
+		{slashingtypes.StoreKey, [][]byte{}},
+		{minttypes.StoreKey, [][]byte{}},
+		{distrtypes.StoreKey, [][]byte{}},
+		{banktypes.StoreKey, [][]byte{banktypes.BalancesPrefix}},
+		{paramstypes.StoreKey, [][]byte{}},
+		{govtypes.StoreKey, [][]byte{}},
+		{evidencetypes.StoreKey, [][]byte{}},
+		{capabilitytypes.StoreKey, [][]byte{}},
+		{authzkeeper.StoreKey, [][]byte{authzkeeper.GrantKey, authzkeeper.GrantQueuePrefix}},
+		{feegrant.StoreKey, [][]byte{feegrant.FeeAllowanceQueueKeyPrefix}},
+		{basefeetypes.StoreKey, [][]byte{}},
+		{freetxtypes.StoreKey, [][]byte{}},
+		{ibcexported.StoreKey, [][]byte{}},
+		{ibctransfertypes.StoreKey, [][]byte{}},
+	}
+
+	for _, skp := range storeKeysPrefixes {
+		storeA := ctxA.KVStore(miniApp.GetKey(skp.name))
+		storeB := ctxB.KVStore(newApp.GetKey(skp.name))
+
+		failedKVAs, failedKVBs := sdk.DiffKVStores(storeA, storeB, skp.prefixes)
+		require.Equal(t, len(failedKVAs), len(failedKVBs), "unequal sets of key-values to compare")
+
+		t.Logf("compared %d different key/value pairs of %s", len(failedKVAs), skp.name)
+		require.Equal(t, 0, len(failedKVAs), simtestutil.GetSimulationLog(skp.name, miniApp.SimulationManager().StoreDecoders, failedKVAs, failedKVBs))
+	}
+}
+
+// initGenesis initializes the app from the exported genesis state. The import fails when all the validators
+// were unbonded by the simulation, in which case the test is skipped.
+func initGenesis(t *testing.T, miniApp *app.MiniApp, ctx sdk.Context, genesisState map[string]json.RawMessage) {
+	t.Helper()
+
+	defer func() {
+		if r := recover(); r != nil {
+			if !strings.Contains(fmt.Sprintf("%v", r), "validator set is empty after InitGenesis") {
+				panic(r)
+			}
+
+			t.Skip("skipping application import/export simulation as all validators have been unbonded")
+		}
+	}()
+
+	miniApp.ModuleManager.InitGenesis(ctx, miniApp.AppCodec(), genesisState)
+}
+
+func TestAppSimulationAfterImport(t *testing.T) {
+	miniApp, config, stopEarly := simulateAndExport(t, "leveldb-app-sim", "Simulation")
+	if stopEarly {
+		t.Skip("can't export or import a zero-validator genesis, exiting test...")
+	}
+
+	t.Log("exporting genesis...")
+	exported, err := miniApp.ExportAppStateAndValidators(true, []string{}, []string{})
+	require.NoError(t, err)
+
+	t.Log("importing genesis...")
+	_, newDB, _ := setupSimulation(t, "leveldb-app-sim-2", "Simulation-2")
+	newApp := newSimApp(log.NewNopLogger(), newDB, t.TempDir(), fauxMerkleModeOpt)
+
+	newApp.InitChain(abci.RequestInitChain{
+		ChainId:       simChainID,
+		AppStateBytes: exported.AppState,
+	})
+
+	_, _, err = simulate(t, newApp, config)
+	require.NoError(t, err)
+}
+
+func TestAppStateDeterminism(t *testing.T) {
+	if !simcli.FlagEnabledValue {
+		t.Skip("skipping application simulation")
+	}
+
+	config := simcli.NewConfigFromFlags()
+	config.InitialBlockHeight = 1
+	config.ExportParamsPath = ""
+	config.OnOperation = false
+	config.AllInvariants = false
+	config.ChainID = simChainID
+
+	numSeeds := 3
+	numTimesToRunPerSeed := 5
+	appHashList := make([][]byte, numTimesToRunPerSeed)
+
+	for i := 0; i < numSeeds; i++ {
+		config.Seed = rand.Int63()
+
+		for j := 0; j < numTimesToRunPerSeed; j++ {
+			logger := log.NewNopLogger()
+			if simcli.FlagVerboseValue {
+				logger = log.TestingLogger()
+			}
+
+			db := dbm.NewMemDB()
+			miniApp := newSimApp(logger, db, t.TempDir(), interBlockCacheOpt())
+
+			t.Logf("running non-determinism simulation; seed %d: %d/%d, attempt: %d/%d", config.Seed, i+1, numSeeds, j+1, numTimesToRunPerSeed)
+
+			_, _, err := simulate(t, miniApp, config)
+			require.NoError(t, err)
+
+			if config.Commit {
+				simtestutil.PrintStats(db)
+			}
+
+			appHashList[j] = miniApp.LastCommitID().Hash
+			if j != 0 {
+				require.Equal(t, appHashList[0], appHashList[j], "non-determinism in seed %d: %d/%d, attempt: %d/%d", config.Seed, i+1, numSeeds, j+1, numTimesToRunPerSeed)
+			}
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package app_test
+
+import (
+	"testing"
+	"time"
+
+	dbm "github.com/cometbft/cometbft-db"
+	abci "github.com/cometbft/cometbft/abci/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"github.com/stretchr/testify/require"
+)
+
+// validatorVote returns the vote of the validator in the last commit.
+func validatorVote(val stakingtypes.Validator, signed bool) abci.VoteInfo {
+	consAddr, err := val.GetConsAddr()
+	if err != nil {
+		panic(err)
+	}
+
+	return abci.VoteInfo{
+		Validator:       abci.Validator{Address: consAddr, Power: val.ConsensusPower(sdk.DefaultPowerReduction)},
+		SignedLastBlock: signed,
+	}
+}
+
+func TestDowntimeJailing(t *testing.T) {
+	miniApp := newApp(dbm.NewMemDB(), t.TempDir())
+	initChain(t, miniApp)
+	blockTime := time.Now().UTC()
+
+	ctx := nextBlock(miniApp, blockTime, nil)
+	params := miniApp.SlashingKeeper.GetParams(ctx)
+	params.SignedBlocksWindow = 10
+	params.MinSignedPerWindow = sdk.NewDecWithPrec(5, 1)
+	require.NoError(t, miniApp.SlashingKeeper.SetParams(ctx, params))
+
+	val := miniApp.StakingKeeper.GetAllValidators(ctx)[0]
+	endBlock(miniApp, ctx)
+
+	// the validator is jailed once it missed more than half of the blocks of the window
+	for i := 0; i < 10; i++ {
+		blockTime = blockTime.Add(time.Second)
+		ctx = nextBlock(miniApp, blockTime, []abci.VoteInfo{validatorVote(val, false)})
+		endBlock(miniApp, ctx)
+	}
+
+	jailed, found := miniApp.StakingKeeper.GetValidator(ctx, val.GetOperator())
+	require.True(t, found)
+	require.True(t, jailed.IsJailed())
+	require.Equal(t, sdk.OneDec().Sub(params.SlashFractionDowntime).MulInt(val.Tokens).TruncateInt(), jailed.Tokens)
+
+	consAddr, err := val.GetConsAddr()
+	require.NoError(t, err)
+
+	signingInfo, found := miniApp.SlashingKeeper.GetValidatorSigningInfo(ctx, consAddr)
+	require.True(t, found)
+	require.True(t, signingInfo.JailedUntil.After(ctx.BlockTime()))
+	require.False(t, signingInfo.Tombstoned)
+}
+
+func TestDoubleSignTombstoning(t *testing.T) {
+	miniApp := newApp(dbm.NewMemDB(), t.TempDir())
+	initChain(t, miniApp)
+	blockTime := time.Now().UTC()
+
+	ctx := nextBlock(miniApp, blockTime, nil)
+	val := miniApp.StakingKeeper.GetAllValidators(ctx)[0]
+	vote := validatorVote(val, true)
+	endBlock(miniApp, ctx)
+
+	// the evidence of a double sign at the previous height slashes, jails and tombstones the validator
+	blockTime = blockTime.Add(time.Second)
+	ctx = nextBlock(miniApp, blockTime, []abci.VoteInfo{vote}, abci.Misbehavior{
+		Type:             abci.MisbehaviorType_DUPLICATE_VOTE,
+		Validator:        vote.Validator,
+		Height:           miniApp.LastBlockHeight(),
+		Time:             blockTime.Add(-time.Second),
+		TotalVotingPower: vote.Validator.Power,
+	})
+	endBlock(miniApp, ctx)
+
+	slashed, found := miniApp.StakingKeeper.GetValidator(ctx, val.GetOperator())
+	require.True(t, found)
+	require.True(t, slashed.IsJailed())
+
+	slashFraction := miniApp.SlashingKeeper.SlashFractionDoubleSign(ctx)
+	require.Equal(t, sdk.OneDec().Sub(slashFraction).MulInt(val.Tokens).TruncateInt(), slashed.Tokens)
+
+	consAddr, err := val.GetConsAddr()
+	require.NoError(t, err)
+	require.True(t, miniApp.SlashingKeeper.IsTombstoned(ctx, consAddr))
+}
@@ -0,0 +1,46 @@
+package app
+
+import (
+	"fmt"
+
+	upgradetypes "github.com/cosmos/cosmos-sdk/x/upgrade/types"
+
+	"github.com/julienrbrt/chain-minimal/app/upgrades"
+)
+
+// Upgrades are the software upgrades applied by the binary. The binary running before an upgrade must not
+// know it (x/upgrade panics otherwise once it is scheduled), so a new upgrade is declared in its own package
+// of app/upgrades and added here in the release it is applied with.
+var Upgrades = []upgrades.Upgrade{}
+
+// setupUpgradeHandlers registers the handlers of the upgrades in the upgrade keeper.
+func (app *MiniApp) setupUpgradeHandlers() {
+	for _, upgrade := range Upgrades {
+		app.UpgradeKeeper.SetUpgradeHandler(
+			upgrade.Name,
+			upgrade.CreateUpgradeHandler(app.ModuleManager, app.Configurator()),
+		)
+	}
+}
+
+// setupUpgradeStoreLoaders sets the store loader applying the store migrations of the upgrade written
+// to disk by x/upgrade, when the node halted at its height. It must be called before loading the app.
+func (app *MiniApp) setupUpgradeStoreLoaders() {
+	upgradeInfo, err := app.UpgradeKeeper.ReadUpgradeInfoFromDisk()
+	if err != nil {
+		panic(fmt.Errorf("failed to read upgrade info from disk: %w", err))
+	}
+
+	if upgradeInfo.Name == "" || app.UpgradeKeeper.IsSkipHeight(upgradeInfo.Height) {
+		return
+	}
+
+	for _, upgrade := range Upgrades {
+		if upgrade.Name != upgradeInfo.Name {
+			continue
+		}
+
+		storeUpgrades := upgrade.StoreUpgrades
+		app.SetStoreLoader(upgradetypes.UpgradeStoreLoader(upgradeInfo.Height, &storeUpgrades))
+	}
+}
@@ -0,0 +1,30 @@
+// Package upgrades defines the software upgrades of the chain, coordinated on-chain with x/upgrade.
+package upgrades
+
+import (
+	storetypes "github.com/cosmos/cosmos-sdk/store/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/module"
+	upgradetypes "github.com/cosmos/cosmos-sdk/x/upgrade/types"
+)
+
+// Upgrade defines a software upgrade, applied at the height of the upgrade plan of the same name.
+type Upgrade struct {
+	// Name is the name of the upgrade plan, e.g. submitted with a software-upgrade proposal.
+	Name string
+
+	// StoreUpgrades are the stores added, renamed or deleted by the upgrade, applied when loading the
+	// new binary at the upgrade height.
+	StoreUpgrades storetypes.StoreUpgrades
+
+	// CreateUpgradeHandler creates the handler run at the upgrade height, before the begin blockers.
+	CreateUpgradeHandler func(mm *module.Manager, configurator module.Configurator) upgradetypes.UpgradeHandler
+}
+
+// CreateDefaultUpgradeHandler creates an upgrade handler running the migrations of the modules whose
+// consensus version changed.
+func CreateDefaultUpgradeHandler(mm *module.Manager, configurator module.Configurator) upgradetypes.UpgradeHandler {
+	return func(ctx sdk.Context, _ upgradetypes.Plan, fromVM module.VersionMap) (module.VersionMap, error) {
+		return mm.RunMigrations(ctx, configurator, fromVM)
+	}
+}
@@ -0,0 +1,103 @@
+package app_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	dbm "github.com/cometbft/cometbft-db"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/module"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	govkeeper "github.com/cosmos/cosmos-sdk/x/gov/keeper"
+	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types"
+	govv1 "github.com/cosmos/cosmos-sdk/x/gov/types/v1"
+	upgradetypes "github.com/cosmos/cosmos-sdk/x/upgrade/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/julienrbrt/chain-minimal/app"
+	"github.com/julienrbrt/chain-minimal/app/upgrades"
+)
+
+func TestSoftwareUpgradeProposal(t *testing.T) {
+	const upgradeName = "v2"
+	db, home := dbm.NewMemDB(), t.TempDir()
+
+	// the upgrades are extended below to simulate the upgraded binary
+	knownUpgrades := app.Upgrades
+	t.Cleanup(func() { app.Upgrades = knownUpgrades })
+
+	oldApp := newApp(db, home)
+	delegator := initChain(t, oldApp)
+	msgServer := govkeeper.NewMsgServerImpl(oldApp.GovKeeper)
+	blockTime := time.Now().UTC()
+
+	// submit and vote a software upgrade proposal
+	ctx := nextBlock(oldApp, blockTime, nil)
+	upgradeHeight := ctx.BlockHeight() + 3
+
+	govParams := oldApp.GovKeeper.GetParams(ctx)
+	proposal, err := govv1.NewMsgSubmitProposal(
+		[]sdk.Msg{&upgradetypes.MsgSoftwareUpgrade{
+			Authority: authtypes.NewModuleAddress(govtypes.ModuleName).String(),
+			Plan:      upgradetypes.Plan{Name: upgradeName, Height: upgradeHeight},
+		}},
+		govParams.MinDeposit,
+		delegator.String(),
+		"",
+		"Upgrade to v2",
+		"Upgrade the chain to v2",
+	)
+	require.NoError(t, err)
+
+	res, err := msgServer.SubmitProposal(ctx, proposal)
+	require.NoError(t, err)
+
+	_, err = msgServer.Vote(ctx, govv1.NewMsgVote(delegator, res.ProposalId, govv1.OptionYes, ""))
+	require.NoError(t, err)
+	endBlock(oldApp, ctx)
+
+	// the proposal passes at the end of the voting period, and schedules the upgrade
+	blockTime = blockTime.Add(*govParams.VotingPeriod)
+	ctx = nextBlock(oldApp, blockTime, nil)
+	endBlock(oldApp, ctx)
+
+	blockTime = blockTime.Add(time.Second)
+	ctx = nextBlock(oldApp, blockTime, nil)
+	passed, found := oldApp.GovKeeper.GetProposal(ctx, res.ProposalId)
+	require.True(t, found)
+	require.Equal(t, govv1.StatusPassed, passed.Status)
+
+	plan, found := oldApp.UpgradeKeeper.GetUpgradePlan(ctx)
+	require.True(t, found)
+	require.Equal(t, upgradeName, plan.Name)
+	endBlock(oldApp, ctx)
+
+	// the binary running before the upgrade halts at the upgrade height
+	blockTime = blockTime.Add(time.Second)
+	require.PanicsWithValue(t, fmt.Sprintf("UPGRADE %q NEEDED at height: %d: ", upgradeName, upgradeHeight), func() {
+		nextBlock(oldApp, blockTime, nil)
+	})
+
+	// the upgraded binary runs the upgrade handler at the upgrade height, and the chain continues
+	var handlerHeight int64
+	app.Upgrades = append(knownUpgrades, upgrades.Upgrade{
+		Name: upgradeName,
+		CreateUpgradeHandler: func(mm *module.Manager, configurator module.Configurator) upgradetypes.UpgradeHandler {
+			return func(ctx sdk.Context, plan upgradetypes.Plan, fromVM module.VersionMap) (module.VersionMap, error) {
+				handlerHeight = ctx.BlockHeight()
+				return upgrades.CreateDefaultUpgradeHandler(mm, configurator)(ctx, plan, fromVM)
+			}
+		},
+	})
+	upgradedApp := newApp(db, home)
+	require.Equal(t, upgradeHeight-1, upgradedApp.LastBlockHeight())
+
+	ctx = nextBlock(upgradedApp, blockTime, nil)
+	require.Equal(t, upgradeHeight, handlerHeight)
+	require.Equal(t, upgradeHeight, upgradedApp.UpgradeKeeper.GetDoneHeight(ctx, upgradeName))
+
+	_, found = upgradedApp.UpgradeKeeper.GetUpgradePlan(ctx)
+	require.False(t, found)
+	endBlock(upgradedApp, ctx)
+}
@@ -0,0 +1,211 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	dbm "github.com/cometbft/cometbft-db"
+	"github.com/cometbft/cometbft/libs/log"
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	storetypes "github.com/cosmos/cosmos-sdk/store/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/kv"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+
+	"github.com/julienrbrt/chain-minimal/mempool"
+)
+
+// StoreMismatch is a key of a module store whose value differs between the state of the app
+// and the state imported from its export.
+type StoreMismatch struct {
+	// Store is the name of the module store.
+	Store string `json:"store"`
+	// Key is the hex-encoded key.
+	Key string `json:"key"`
+	// Exported is the hex-encoded value in the state of the app, empty when the key is missing.
+	Exported string `json:"exported,omitempty"`
+	// Imported is the hex-encoded value in the imported state, empty when the key is missing.
+	Imported string `json:"imported,omitempty"`
+	// Decoded compares the decoded values, when the module registers a store decoder for the key.
+	Decoded string `json:"decoded,omitempty"`
+}
+
+// ExportVerification is the result of an export and import round trip of the state of the app.
+type ExportVerification struct {
+	// Height is the height of the exported state.
+	Height int64 `json:"height"`
+	// Stores are the names of the compared module stores, sorted.
+	Stores []string `json:"stores"`
+	// Mismatches are the keys whose values differ, sorted by store and key.
+	Mismatches []StoreMismatch `json:"mismatches"`
+}
+
+// verifyExportSkippedPrefixes are the key prefixes of the module stores that are not compared, as they are either
+// not exported (e.g. the staking historical info) or rebuilt in a different order by the genesis of the module.
+var verifyExportSkippedPrefixes = map[string][][]byte{
+	stakingtypes.StoreKey: {
+		stakingtypes.UnbondingQueueKey, stakingtypes.RedelegationQueueKey, stakingtypes.ValidatorQueueKey,
+		stakingtypes.HistoricalInfoKey, stakingtypes.UnbondingIDKey, stakingtypes.UnbondingIndexKey,
+		stakingtypes.UnbondingTypeKey, stakingtypes.ValidatorUpdatesKey,
+	},
+}
+
+// verifyExportAppOptions are the options of the app importing the exported state. It has no app-side mempool,
+// journal or state streaming, as it only initializes the state from the genesis.
+type verifyExportAppOptions map[string]interface{}
+
+// Get implements servertypes.AppOptions.
+func (o verifyExportAppOptions) Get(key string) interface{} {
+	return o[key]
+}
+
+// VerifyExport exports the latest state of the app, imports it in a new in-memory app, and compares all the module
+// stores of both apps key by key, but the prefixes of verifyExportSkippedPrefixes. The values of the mismatching keys are decoded with the store decoders of the
+// simulation manager, so that the state lost by an export (e.g. before an upgrade) can be told apart.
+func (app *MiniApp) VerifyExport() (ExportVerification, error) {
+	ctx := app.NewContext(true, cmtproto.Header{Height: app.LastBlockHeight()})
+
+	exported, err := app.ExportAppStateAndValidators(false, []string{}, []string{})
+	if err != nil {
+		return ExportVerification{}, fmt.Errorf("failed to export the state: %w", err)
+	}
+
+	var genesisState map[string]json.RawMessage
+	if err := json.Unmarshal(exported.AppState, &genesisState); err != nil {
+		return ExportVerification{}, err
+	}
+
+	importApp := NewMiniApp(log.NewNopLogger(), dbm.NewMemDB(), nil, true, verifyExportAppOptions{
+		flags.FlagHome:            "",
+		mempool.FlagMempoolType:   mempool.TypeNone,
+		mempool.FlagMaxBundles:    0,
+		mempool.FlagRecheckBudget: -1,
+	})
+	defer importApp.Close()
+
+	importCtx := importApp.NewContext(true, cmtproto.Header{Height: ctx.BlockHeight()})
+	if err := importApp.initGenesis(importCtx, genesisState); err != nil {
+		return ExportVerification{}, fmt.Errorf("failed to import the exported state: %w", err)
+	}
+	importApp.StoreConsensusParams(importCtx, exported.ConsensusParams)
+
+	result := ExportVerification{
+		Height:     exported.Height - 1,
+		Stores:     []string{},
+		Mismatches: []StoreMismatch{},
+	}
+
+	keys := app.kvStoreKeys()
+	for name := range keys {
+		result.Stores = append(result.Stores, name)
+	}
+	sort.Strings(result.Stores)
+
+	for _, name := range result.Stores {
+		importKey := importApp.GetKey(name)
+		if importKey == nil {
+			return ExportVerification{}, fmt.Errorf("store %s is not mounted by the imported app", name)
+		}
+
+		mismatches := diffStores(name, ctx.KVStore(keys[name]), importCtx.KVStore(importKey), verifyExportSkippedPrefixes[name], app.sm.StoreDecoders[name])
+		result.Mismatches = append(result.Mismatches, mismatches...)
+	}
+
+	return result, nil
+}
+
+// initGenesis initializes the modules from the genesis state, returning the panics of the modules as errors
+// (e.g. when the validator set is empty after the genesis).
+func (app *MiniApp) initGenesis(ctx sdk.Context, genesisState map[string]json.RawMessage) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	app.ModuleManager.InitGenesis(ctx, app.appCodec, genesisState)
+	return nil
+}
+
+// diffStores returns the keys whose values differ between the two stores, but the skipped prefixes,
+// iterating both of them in key order.
+func diffStores(name string, storeA, storeB storetypes.KVStore, skippedPrefixes [][]byte, decoder func(kvA, kvB kv.Pair) string) []StoreMismatch {
+	iterA := storeA.Iterator(nil, nil)
+	defer iterA.Close()
+
+	iterB := storeB.Iterator(nil, nil)
+	defer iterB.Close()
+
+	var mismatches []StoreMismatch
+	for iterA.Valid() || iterB.Valid() {
+		var kvA, kvB kv.Pair
+
+		switch {
+		case !iterB.Valid() || (iterA.Valid() && bytes.Compare(iterA.Key(), iterB.Key()) < 0):
+			kvA = kv.Pair{Key: iterA.Key(), Value: iterA.Value()}
+			iterA.Next()
+		case !iterA.Valid() || bytes.Compare(iterA.Key(), iterB.Key()) > 0:
+			kvB = kv.Pair{Key: iterB.Key(), Value: iterB.Value()}
+			iterB.Next()
+		default:
+			kvA = kv.Pair{Key: iterA.Key(), Value: iterA.Value()}
+			kvB = kv.Pair{Key: iterB.Key(), Value: iterB.Value()}
+			iterA.Next()
+			iterB.Next()
+
+			if bytes.Equal(kvA.Value, kvB.Value) {
+				continue
+			}
+		}
+
+		key := kvA.Key
+		if key == nil {
+			key = kvB.Key
+		}
+
+		if hasPrefix(key, skippedPrefixes) {
+			continue
+		}
+
+		mismatches = append(mismatches, StoreMismatch{
+			Store:    name,
+			Key:      fmt.Sprintf("%X", key),
+			Exported: fmt.Sprintf("%X", kvA.Value),
+			Imported: fmt.Sprintf("%X", kvB.Value),
+			Decoded:  decodeMismatch(decoder, kv.Pair{Key: key, Value: kvA.Value}, kv.Pair{Key: key, Value: kvB.Value}),
+		})
+	}
+
+	return mismatches
+}
+
+// hasPrefix reports whether the key starts with one of the prefixes.
+func hasPrefix(key []byte, prefixes [][]byte) bool {
+	for _, prefix := range prefixes {
+		if bytes.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// decodeMismatch decodes the values of a mismatching key with the store decoder of its module. The decoders are
+// written for the simulations and panic on the keys they do not know (or missing values), in which case the
+// values are not decoded.
+func decodeMismatch(decoder func(kvA, kvB kv.Pair) string, kvA, kvB kv.Pair) (decoded string) {
+	if decoder == nil {
+		return ""
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			decoded = ""
+		}
+	}()
+
+	return decoder(kvA, kvB)
+}
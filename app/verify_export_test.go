@@ -0,0 +1,41 @@
+package app_test
+
+import (
+	"testing"
+	"time"
+
+	dbm "github.com/cometbft/cometbft-db"
+	"github.com/stretchr/testify/require"
+
+	freetxtypes "github.com/julienrbrt/chain-minimal/x/freetx/types"
+)
+
+func TestVerifyExport(t *testing.T) {
+	miniApp := newApp(dbm.NewMemDB(), t.TempDir())
+	initChain(t, miniApp)
+
+	blockTime := time.Now().UTC()
+	for i := 0; i < 3; i++ {
+		blockTime = blockTime.Add(5 * time.Second)
+		endBlock(miniApp, nextBlock(miniApp, blockTime, nil))
+	}
+
+	result, err := miniApp.VerifyExport()
+	require.NoError(t, err)
+	require.Equal(t, miniApp.LastBlockHeight(), result.Height)
+	require.Subset(t, result.Stores, []string{"bank", "staking", "freetx", "ibc", "transfer"})
+	require.Empty(t, result.Mismatches)
+
+	// a key that is not exported by its module is lost by the round trip
+	ctx := nextBlock(miniApp, blockTime.Add(5*time.Second), nil)
+	ctx.KVStore(miniApp.GetKey(freetxtypes.StoreKey)).Set([]byte{0xFF}, []byte{0x01})
+	endBlock(miniApp, ctx)
+
+	result, err = miniApp.VerifyExport()
+	require.NoError(t, err)
+	require.Len(t, result.Mismatches, 1)
+	require.Equal(t, freetxtypes.StoreKey, result.Mismatches[0].Store)
+	require.Equal(t, "FF", result.Mismatches[0].Key)
+	require.Equal(t, "01", result.Mismatches[0].Exported)
+	require.Empty(t, result.Mismatches[0].Imported)
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	authclient "github.com/cosmos/cosmos-sdk/x/auth/client"
+
+	"github.com/julienrbrt/chain-minimal/mempool"
+	"github.com/julienrbrt/chain-minimal/mempool/types"
+)
+
+// submitBundleCommand returns the command submitting an atomic bundle of signed transactions to a node.
+func submitBundleCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "submit-bundle [signed-tx-file]...",
+		Short: "Submit an atomic bundle of signed transactions to the app-side mempool of a node",
+		Long: `Submit an ordered bundle of signed transactions, possibly from different senders, to the app-side mempool of a node.
+The transactions are included in a block contiguously and in the given order, or not at all, but only in the
+blocks proposed by the node receiving the bundle: bundles are not gossiped to the other validators, which do not
+enforce this when processing a proposal. A bundle is thus only included once that node proposes a block.
+Each transaction must be signed with the sequence it will have once the previous transactions of the bundle are executed.
+The bundle is submitted to the gRPC server of the node, given by --grpc-addr.`,
+		Args: cobra.RangeArgs(1, mempool.MaxBundleTxs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clientCtx, err := client.GetClientQueryContext(cmd)
+			if err != nil {
+				return err
+			}
+
+			// the bundles are not served by the ABCI queries, as they change the mempool
+			if clientCtx.GRPCClient == nil {
+				return fmt.Errorf("--%s is required", flags.FlagGRPC)
+			}
+
+			req := &types.SubmitBundleRequest{}
+			for _, file := range args {
+				tx, err := authclient.ReadTxFromFile(clientCtx, file)
+				if err != nil {
+					return err
+				}
+
+				bz, err := clientCtx.TxConfig.TxEncoder()(tx)
+				if err != nil {
+					return err
+				}
+
+				req.Txs = append(req.Txs, bz)
+			}
+
+			res, err := types.NewServiceClient(clientCtx).SubmitBundle(cmd.Context(), req)
+			if err != nil {
+				return err
+			}
+
+			return clientCtx.PrintProto(res)
+		},
+	}
+
+	flags.AddQueryFlagsToCmd(cmd)
+
+	return cmd
+}
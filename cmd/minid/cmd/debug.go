@@ -0,0 +1,431 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	dbm "github.com/cometbft/cometbft-db"
+	"github.com/cometbft/cometbft/libs/log"
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	"github.com/spf13/cobra"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/debug"
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/cosmos/cosmos-sdk/server"
+
+	"github.com/julienrbrt/chain-minimal/app"
+	"github.com/julienrbrt/chain-minimal/app/params"
+	"github.com/julienrbrt/chain-minimal/mempool"
+)
+
+const (
+	flagSeed            = "seed"
+	flagSenders         = "senders"
+	flagNonces          = "nonces"
+	flagFeeDistribution = "fee-distribution"
+	flagMinFee          = "min-fee"
+	flagMaxFee          = "max-fee"
+	flagArrivalRate     = "arrival-rate"
+	flagBlockSize       = "block-size"
+	flagMaxBlocks       = "max-blocks"
+	flagDenom           = "denom"
+	flagShowBlocks      = "show-blocks"
+)
+
+// debugCommand returns the SDK debug command, extended with the mempool debugging commands.
+func debugCommand() *cobra.Command {
+	cmd := debug.Cmd()
+
+	cmd.AddCommand(
+		mempoolReplayCommand(),
+		mempoolSimCommand(),
+		checkInvariantsCommand(),
+		verifyExportCommand(),
+	)
+
+	return cmd
+}
+
+// mempoolReplayCommand returns the command replaying a mempool journal against a mempool.
+func mempoolReplayCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "mempool-replay [journal]",
+		Short: "Replay a mempool journal against a mempool and print the block orderings it produces",
+		Long: fmt.Sprintf(`Replay a mempool journal, recorded by a node started with --%s, against the mempool selected with --%s.
+The orderings produced by the replayed mempool are printed next to the recorded ones.
+
+Transactions are inserted with the recorded ante handler priority. As the chain state is not available,
+the fee mempool ranks transactions by their fee, not by their tip above the base fee.`, mempool.FlagJournal, mempool.FlagMempoolType),
+		Example: fmt.Sprintf("minid debug mempool-replay mempool.journal --%s fee", mempool.FlagMempoolType),
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clientCtx := client.GetClientContextFromCmd(cmd)
+
+			mempoolType, _ := cmd.Flags().GetString(mempool.FlagMempoolType)
+			priorityStrategy, _ := cmd.Flags().GetString(mempool.FlagPriorityStrategy)
+			maxTxs, _ := cmd.Flags().GetInt(server.FlagMempoolMaxTxs)
+			seed, _ := cmd.Flags().GetInt64(flagSeed)
+			output, _ := cmd.Flags().GetString(flags.FlagOutput)
+
+			mp, err := mempool.NewMempool(log.NewNopLogger(), mempool.Config{
+				Type:             mempoolType,
+				MaxTxs:           maxTxs,
+				PriorityStrategy: mempool.PriorityStrategy(priorityStrategy),
+				TxEncoder:        clientCtx.TxConfig.TxEncoder(),
+				Denom:            params.DefaultBondDenom,
+				Aging:            agingFromFlags(cmd),
+				Seed:             seed,
+			})
+			if err != nil {
+				return err
+			}
+
+			journal, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer journal.Close()
+
+			selects, err := mempool.ReplayJournal(journal, mp, clientCtx.TxConfig.TxDecoder(), clientCtx.TxConfig.TxEncoder())
+			if err != nil {
+				return fmt.Errorf("failed to replay journal: %w", err)
+			}
+
+			if output == "json" {
+				bz, err := json.Marshal(selects)
+				if err != nil {
+					return err
+				}
+
+				return clientCtx.PrintRaw(bz)
+			}
+
+			var differ int
+			for _, s := range selects {
+				if len(s.Recorded) == 0 && len(s.Replayed) == 0 {
+					continue
+				}
+
+				status := "same"
+				if !s.Equal() {
+					status = "differs"
+					differ++
+				}
+
+				cmd.Printf("height %d: recorded %v, replayed %v (%s)\n", s.Height, s.Recorded, s.Replayed, status)
+			}
+			cmd.Printf("replayed %d selections with the %s mempool, %d differ from the journal\n", len(selects), mempoolType, differ)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().Int(server.FlagMempoolMaxTxs, 0, "Maximum number of transactions of the replayed mempool (0 for unbounded)")
+	cmd.Flags().Int64(flagSeed, 0, "Random seed of the sender-nonce mempool (0 for a random seed)")
+	cmd.Flags().StringP(flags.FlagOutput, "o", "text", "Output format (text|json)")
+
+	return cmd
+}
+
+// mempoolSimCommand returns the command simulating a synthetic workload against all the mempool types.
+func mempoolSimCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "mempool-sim",
+		Short: "Simulate a synthetic workload against all the mempool types and compare them",
+		Long: `Generate a synthetic workload of fake signed transactions and feed it block by block into the fee, sender-nonce,
+priority-nonce and no-op mempools. The per-block composition, fee revenue, latency (in blocks) and fairness
+(Jain's index of the mean latency per sender, 1 being perfectly fair) are reported side by side.
+
+A transaction selected before a lower nonce of its sender is skipped, as it would fail in the block.`,
+		Example: "minid debug mempool-sim --senders 50 --nonces 3 --fee-distribution bimodal --arrival-rate 30 --block-size 20",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			clientCtx := client.GetClientContextFromCmd(cmd)
+
+			var cfg mempool.SimConfig
+			cfg.Senders, _ = cmd.Flags().GetInt(flagSenders)
+			cfg.Nonces, _ = cmd.Flags().GetInt(flagNonces)
+			cfg.FeeDistribution, _ = cmd.Flags().GetString(flagFeeDistribution)
+			cfg.MinFee, _ = cmd.Flags().GetInt64(flagMinFee)
+			cfg.MaxFee, _ = cmd.Flags().GetInt64(flagMaxFee)
+			cfg.ArrivalRate, _ = cmd.Flags().GetFloat64(flagArrivalRate)
+			cfg.BlockSize, _ = cmd.Flags().GetInt(flagBlockSize)
+			cfg.MaxBlocks, _ = cmd.Flags().GetInt(flagMaxBlocks)
+			cfg.Denom, _ = cmd.Flags().GetString(flagDenom)
+			cfg.Seed, _ = cmd.Flags().GetInt64(flagSeed)
+			output, _ := cmd.Flags().GetString(flags.FlagOutput)
+			showBlocks, _ := cmd.Flags().GetBool(flagShowBlocks)
+
+			workload, err := mempool.GenerateWorkload(cfg, clientCtx.TxConfig)
+			if err != nil {
+				return err
+			}
+
+			var results []mempool.SimResult
+			for _, mempoolType := range []string{mempool.TypeFee, mempool.TypeSenderNonce, mempool.TypePriorityNonce, mempool.TypeNone} {
+				mpCfg := mempool.Config{Type: mempoolType, Seed: cfg.Seed, TxEncoder: clientCtx.TxConfig.TxEncoder()}
+				if mempoolType == mempool.TypeFee {
+					priorityStrategy, _ := cmd.Flags().GetString(mempool.FlagPriorityStrategy)
+					mpCfg.PriorityStrategy = mempool.PriorityStrategy(priorityStrategy)
+					mpCfg.Denom = cfg.Denom
+					mpCfg.Aging = agingFromFlags(cmd)
+				}
+
+				mp, err := mempool.NewMempool(log.NewNopLogger(), mpCfg)
+				if err != nil {
+					return err
+				}
+
+				results = append(results, mempool.Simulate(mempoolType, mp, workload, cfg))
+			}
+
+			if output == "json" {
+				bz, err := json.Marshal(results)
+				if err != nil {
+					return err
+				}
+
+				return clientCtx.PrintRaw(bz)
+			}
+
+			printSimResults(cmd.OutOrStdout(), len(workload), results, showBlocks)
+			return nil
+		},
+	}
+
+	cmd.Flags().Int(flagSenders, 20, "Number of senders")
+	cmd.Flags().Int(flagNonces, 5, "Number of transactions (nonce chain length) per sender")
+	cmd.Flags().String(flagFeeDistribution, mempool.FeeDistributionUniform, "Fee distribution (uniform|exponential|bimodal)")
+	cmd.Flags().Int64(flagMinFee, 1, "Minimum transaction fee")
+	cmd.Flags().Int64(flagMaxFee, 1000, "Maximum transaction fee")
+	cmd.Flags().Float64(flagArrivalRate, 20, "Mean number of transactions arriving per block")
+	cmd.Flags().Int(flagBlockSize, 10, "Maximum number of transactions per block")
+	cmd.Flags().Int(flagMaxBlocks, 1000, "Maximum number of simulated blocks")
+	cmd.Flags().String(flagDenom, "mini", "Fee denomination, an IBC voucher can be given by its full path (e.g. transfer/channel-0/uatom)")
+	cmd.Flags().Int64(flagSeed, 1, "Random seed of the workload and of the sender-nonce mempool")
+	cmd.Flags().Bool(flagShowBlocks, false, "Print the composition of each block (txs/senders/fees)")
+	cmd.Flags().StringP(flags.FlagOutput, "o", "text", "Output format (text|json)")
+
+	return cmd
+}
+
+// checkInvariantsCommand returns the command running the module invariants against the latest state of a stopped node.
+func checkInvariantsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "check-invariants",
+		Short: "Run all the module invariants against the latest state of the node",
+		Long: `Load the latest state of the node from its home directory and run all the invariants registered in x/crisis
+(e.g. bank total supply, staking and distribution module accounts). All the broken invariants are reported,
+and the command fails when at least one of them is broken. The node must be stopped.`,
+		Example: "minid debug check-invariants --home ~/.minid",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			clientCtx := client.GetClientContextFromCmd(cmd)
+			serverCtx := server.GetServerContextFromCmd(cmd)
+			output, _ := cmd.Flags().GetString(flags.FlagOutput)
+
+			db, err := dbm.NewDB("application", server.GetAppDBBackend(serverCtx.Viper), filepath.Join(serverCtx.Config.RootDir, "data"))
+			if err != nil {
+				return err
+			}
+
+			// the app-side mempool is not used offline
+			serverCtx.Viper.Set(mempool.FlagMempoolType, mempool.TypeNone)
+
+			miniApp := app.NewMiniApp(log.NewNopLogger(), db, nil, true, serverCtx.Viper)
+			defer miniApp.Close()
+
+			height := miniApp.LastBlockHeight()
+			results := miniApp.CheckInvariants(miniApp.NewContext(true, cmtproto.Header{Height: height}))
+
+			var broken int
+			for _, result := range results {
+				if result.Broken {
+					broken++
+				}
+			}
+
+			if output == "json" {
+				bz, err := json.Marshal(results)
+				if err != nil {
+					return err
+				}
+
+				if err := clientCtx.PrintRaw(bz); err != nil {
+					return err
+				}
+			} else {
+				for _, result := range results {
+					status := "ok"
+					if result.Broken {
+						status = "broken"
+					}
+
+					cmd.Printf("%s: %s\n", result.Route, status)
+					if result.Broken {
+						cmd.Println(result.Message)
+					}
+				}
+			}
+
+			if broken > 0 {
+				return fmt.Errorf("%d of %d invariants broken at height %d", broken, len(results), height)
+			}
+
+			if output != "json" {
+				cmd.Printf("all %d invariants hold at height %d\n", len(results), height)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP(flags.FlagOutput, "o", "text", "Output format (text|json)")
+
+	return cmd
+}
+
+// verifyExportCommand returns the command verifying that the latest state of a stopped node survives an export and import.
+func verifyExportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify-export",
+		Short: "Verify that the latest state of the node is preserved by an export and import round trip",
+		Long: `Load the latest state of the node from its home directory, export it as with minid export, and import the
+exported genesis in a fresh in-memory app. All the module stores of both apps are then compared key by key, and
+the mismatching keys are reported with their values decoded by the store decoders of the modules when available.
+The command fails when at least one key differs. The node must be stopped.`,
+		Example: "minid debug verify-export --home ~/.minid",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			clientCtx := client.GetClientContextFromCmd(cmd)
+			serverCtx := server.GetServerContextFromCmd(cmd)
+			output, _ := cmd.Flags().GetString(flags.FlagOutput)
+
+			db, err := dbm.NewDB("application", server.GetAppDBBackend(serverCtx.Viper), filepath.Join(serverCtx.Config.RootDir, "data"))
+			if err != nil {
+				return err
+			}
+
+			// the app-side mempool is not used offline
+			serverCtx.Viper.Set(mempool.FlagMempoolType, mempool.TypeNone)
+
+			miniApp := app.NewMiniApp(log.NewNopLogger(), db, nil, true, serverCtx.Viper)
+			defer miniApp.Close()
+
+			result, err := miniApp.VerifyExport()
+			if err != nil {
+				return err
+			}
+
+			if output == "json" {
+				bz, err := json.Marshal(result)
+				if err != nil {
+					return err
+				}
+
+				if err := clientCtx.PrintRaw(bz); err != nil {
+					return err
+				}
+			} else {
+				for _, mismatch := range result.Mismatches {
+					cmd.Printf("%s: %s\n", mismatch.Store, mismatch.Key)
+					if mismatch.Decoded != "" {
+						cmd.Println(mismatch.Decoded)
+					} else {
+						cmd.Printf("exported: %s\nimported: %s\n", orMissing(mismatch.Exported), orMissing(mismatch.Imported))
+					}
+				}
+			}
+
+			if len(result.Mismatches) > 0 {
+				stores := make(map[string]bool)
+				for _, mismatch := range result.Mismatches {
+					stores[mismatch.Store] = true
+				}
+
+				return fmt.Errorf("%d keys differ in %d of %d stores at height %d", len(result.Mismatches), len(stores), len(result.Stores), result.Height)
+			}
+
+			if output != "json" {
+				cmd.Printf("all %d stores match at height %d\n", len(result.Stores), result.Height)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP(flags.FlagOutput, "o", "text", "Output format (text|json)")
+
+	return cmd
+}
+
+// orMissing returns the value, or a placeholder when it is empty.
+func orMissing(value string) string {
+	if value == "" {
+		return "<missing>"
+	}
+
+	return value
+}
+
+// printSimResults prints the simulation results side by side.
+func printSimResults(out io.Writer, workloadSize int, results []mempool.SimResult, showBlocks bool) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+
+	row := func(name string, value func(r mempool.SimResult) string) {
+		fmt.Fprintf(w, "%s\t", name)
+		for _, r := range results {
+			fmt.Fprintf(w, "%s\t", value(r))
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintf(out, "workload of %d transactions\n\n", workloadSize)
+	row("mempool", func(r mempool.SimResult) string { return r.Mempool })
+	row("blocks", func(r mempool.SimResult) string { return fmt.Sprint(len(r.Blocks)) })
+	row("included", func(r mempool.SimResult) string { return fmt.Sprint(r.Included) })
+	row("pending", func(r mempool.SimResult) string { return fmt.Sprint(r.Pending) })
+	row("rejected", func(r mempool.SimResult) string { return fmt.Sprint(r.Rejected) })
+	row("out of order", func(r mempool.SimResult) string { return fmt.Sprint(r.OutOfOrder) })
+	row("fee revenue", func(r mempool.SimResult) string { return fmt.Sprint(r.FeeRevenue) })
+	row("mean latency", func(r mempool.SimResult) string { return fmt.Sprintf("%.2f", r.MeanLatency) })
+	row("max latency", func(r mempool.SimResult) string { return fmt.Sprint(r.MaxLatency) })
+	row("fairness", func(r mempool.SimResult) string { return fmt.Sprintf("%.3f", r.Fairness) })
+
+	if showBlocks {
+		var maxBlocks int
+		for _, r := range results {
+			if len(r.Blocks) > maxBlocks {
+				maxBlocks = len(r.Blocks)
+			}
+		}
+
+		fmt.Fprintln(w)
+		row("height", func(r mempool.SimResult) string { return r.Mempool })
+		for h := 0; h < maxBlocks; h++ {
+			row(fmt.Sprint(h+1), func(r mempool.SimResult) string {
+				if h >= len(r.Blocks) {
+					return "-"
+				}
+
+				b := r.Blocks[h]
+				return fmt.Sprintf("%d/%d/%d", b.Txs, b.Senders, b.Fees)
+			})
+		}
+	}
+
+	_ = w.Flush()
+}
+
+// agingFromFlags returns the priority aging of the fee mempool set by the persistent flags.
+func agingFromFlags(cmd *cobra.Command) mempool.PriorityAging {
+	curve, _ := cmd.Flags().GetString(mempool.FlagAgingCurve)
+	rate, _ := cmd.Flags().GetInt64(mempool.FlagAgingRate)
+
+	return mempool.PriorityAging{Curve: curve, Rate: rate}
+}
@@ -0,0 +1,354 @@
+package cmd
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	dbm "github.com/cometbft/cometbft-db"
+	cmtjson "github.com/cometbft/cometbft/libs/json"
+	cmttypes "github.com/cometbft/cometbft/types"
+	"github.com/spf13/cobra"
+
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/cosmos/cosmos-sdk/server"
+	servertypes "github.com/cosmos/cosmos-sdk/server/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+const (
+	flagStream = "stream"
+	flagGzip   = "gzip"
+	flagDryRun = "dry-run"
+)
+
+// extendExportCommand extends the SDK export command with a streaming mode, which writes the genesis of each module
+// as soon as it is exported instead of building the whole genesis in memory, and a dry run of the preparation
+// for a zero height genesis.
+func extendExportCommand(rootCmd *cobra.Command) {
+	cmd, _, err := rootCmd.Find([]string{"export"})
+	if err != nil {
+		panic(err)
+	}
+
+	exportRunE := cmd.RunE
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		stream, _ := cmd.Flags().GetBool(flagStream)
+		compress, _ := cmd.Flags().GetBool(flagGzip)
+		dryRun, _ := cmd.Flags().GetBool(flagDryRun)
+
+		if dryRun {
+			forZeroHeight, _ := cmd.Flags().GetBool(server.FlagForZeroHeight)
+			if !forZeroHeight {
+				return fmt.Errorf("--%s requires --%s", flagDryRun, server.FlagForZeroHeight)
+			}
+
+			if stream || compress {
+				return fmt.Errorf("--%s cannot be used with --%s or --%s", flagDryRun, flagStream, flagGzip)
+			}
+
+			return dryRunExport(cmd)
+		}
+
+		if !stream {
+			if compress {
+				return fmt.Errorf("--%s requires --%s", flagGzip, flagStream)
+			}
+
+			return exportRunE(cmd, args)
+		}
+
+		return streamExport(cmd, compress)
+	}
+
+	cmd.Flags().Bool(flagStream, false, "Stream the genesis of each module to the output as it is exported, with a memory use bounded by the largest module genesis (compact JSON)")
+	cmd.Flags().Bool(flagGzip, false, "Compress the streamed genesis with gzip (requires --stream)")
+	cmd.Flags().Bool(flagDryRun, false, "Print the changes the preparation for a zero height genesis would make to the state, as JSON, instead of exporting it (requires --for-zero-height)")
+}
+
+// streamExport exports the state of the node as the SDK export command does, but streams it to the output document
+// (STDOUT by default). The output document is removed when the export fails.
+func streamExport(cmd *cobra.Command, compress bool) (err error) {
+	serverCtx := server.GetServerContextFromCmd(cmd)
+	config := serverCtx.Config
+
+	homeDir, _ := cmd.Flags().GetString(flags.FlagHome)
+	config.SetRoot(homeDir)
+
+	height, _ := cmd.Flags().GetInt64(server.FlagHeight)
+	forZeroHeight, _ := cmd.Flags().GetBool(server.FlagForZeroHeight)
+	jailAllowedAddrs, _ := cmd.Flags().GetStringSlice(server.FlagJailAllowedAddrs)
+	modulesToExport, _ := cmd.Flags().GetStringSlice(server.FlagModulesToExport)
+	outputDocument, _ := cmd.Flags().GetString(server.FlagOutputDocument)
+
+	doc, err := genesisDocHeaderFromFile(config.GenesisFile())
+	if err != nil {
+		return err
+	}
+
+	db, err := dbm.NewDB("application", server.GetAppDBBackend(serverCtx.Viper), filepath.Join(config.RootDir, "data"))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	miniApp, err := loadExportApp(serverCtx.Logger, db, nil, height, serverCtx.Viper)
+	if err != nil {
+		return fmt.Errorf("error exporting state: %v", err)
+	}
+	defer miniApp.Close()
+
+	out := cmd.OutOrStdout()
+	if outputDocument != "" {
+		file, createErr := os.Create(outputDocument)
+		if createErr != nil {
+			return createErr
+		}
+
+		defer func() {
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+
+			if err != nil {
+				os.Remove(outputDocument)
+			}
+		}()
+
+		out = file
+	}
+
+	buf := bufio.NewWriter(out)
+	var w io.Writer = buf
+
+	var zw *gzip.Writer
+	if compress {
+		zw = gzip.NewWriter(buf)
+		w = zw
+	}
+
+	if err := writeGenesisDoc(w, doc, func(w io.Writer) (servertypes.ExportedApp, error) {
+		return miniApp.StreamAppStateAndValidators(w, forZeroHeight, jailAllowedAddrs, modulesToExport)
+	}); err != nil {
+		return fmt.Errorf("error exporting state: %v", err)
+	}
+
+	if zw != nil {
+		if err := zw.Close(); err != nil {
+			return err
+		}
+	}
+
+	return buf.Flush()
+}
+
+// genesisDocHeaderFromFile reads the genesis document of the given file as cmttypes.GenesisDocFromFile, but
+// without its app state, which is skipped token by token instead of being loaded in memory.
+func genesisDocHeaderFromFile(genFile string) (*cmttypes.GenesisDoc, error) {
+	file, err := os.Open(genFile)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read GenesisDoc file: %w", err)
+	}
+	defer file.Close()
+
+	dec := json.NewDecoder(bufio.NewReader(file))
+	if err := expectDelim(dec, '{'); err != nil {
+		return nil, err
+	}
+
+	header := map[string]json.RawMessage{}
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		name, ok := token.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid genesis document: unexpected %v", token)
+		}
+
+		if name == "app_state" {
+			if err := skipJSONValue(dec); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		header[name] = value
+	}
+
+	if err := expectDelim(dec, '}'); err != nil {
+		return nil, err
+	}
+
+	bz, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := cmttypes.GenesisDocFromJSON(bz)
+	if err != nil {
+		return nil, fmt.Errorf("error reading GenesisDoc at %s: %w", genFile, err)
+	}
+
+	return doc, nil
+}
+
+// expectDelim reads the next token of the decoder, which must be the given delimiter.
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	if token != delim {
+		return fmt.Errorf("invalid genesis document: expected %v, got %v", delim, token)
+	}
+
+	return nil
+}
+
+// skipJSONValue reads the next value of the decoder token by token, without holding it in memory.
+func skipJSONValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// dryRunExport prints the changes the preparation for a zero height genesis would make to the state of the node,
+// without exporting it.
+func dryRunExport(cmd *cobra.Command) error {
+	serverCtx := server.GetServerContextFromCmd(cmd)
+	config := serverCtx.Config
+
+	homeDir, _ := cmd.Flags().GetString(flags.FlagHome)
+	config.SetRoot(homeDir)
+
+	height, _ := cmd.Flags().GetInt64(server.FlagHeight)
+	jailAllowedAddrs, _ := cmd.Flags().GetStringSlice(server.FlagJailAllowedAddrs)
+
+	db, err := dbm.NewDB("application", server.GetAppDBBackend(serverCtx.Viper), filepath.Join(config.RootDir, "data"))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	miniApp, err := loadExportApp(serverCtx.Logger, db, nil, height, serverCtx.Viper)
+	if err != nil {
+		return fmt.Errorf("error preparing the state: %v", err)
+	}
+	defer miniApp.Close()
+
+	prep, err := miniApp.DryRunZeroHeightGenesis(jailAllowedAddrs)
+	if err != nil {
+		return fmt.Errorf("error preparing the state: %v", err)
+	}
+
+	bz, err := json.MarshalIndent(prep, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(cmd.OutOrStdout(), string(bz))
+	return err
+}
+
+// writeGenesisDoc writes the genesis document with the app state streamed by streamAppState, as compact JSON with
+// sorted keys. The fields of the document following the app state are only known once it is exported
+// (e.g. the validators of a zero height genesis), so they are written after it.
+func writeGenesisDoc(w io.Writer, doc *cmttypes.GenesisDoc, streamAppState func(io.Writer) (servertypes.ExportedApp, error)) error {
+	appHash, err := cmtjson.Marshal(doc.AppHash)
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(w, `{"app_hash":%s,"app_state":`, appHash); err != nil {
+		return err
+	}
+
+	exported, err := streamAppState(w)
+	if err != nil {
+		return err
+	}
+
+	doc.AppState = nil
+	doc.Validators = exported.Validators
+	doc.InitialHeight = exported.Height
+	doc.ConsensusParams = &cmttypes.ConsensusParams{
+		Block: cmttypes.BlockParams{
+			MaxBytes: exported.ConsensusParams.Block.MaxBytes,
+			MaxGas:   exported.ConsensusParams.Block.MaxGas,
+		},
+		Evidence: cmttypes.EvidenceParams{
+			MaxAgeNumBlocks: exported.ConsensusParams.Evidence.MaxAgeNumBlocks,
+			MaxAgeDuration:  exported.ConsensusParams.Evidence.MaxAgeDuration,
+			MaxBytes:        exported.ConsensusParams.Evidence.MaxBytes,
+		},
+		Validator: cmttypes.ValidatorParams{
+			PubKeyTypes: exported.ConsensusParams.Validator.PubKeyTypes,
+		},
+	}
+
+	// NOTE: CometBFT uses a custom JSON encoder for GenesisDoc
+	encoded, err := cmtjson.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		return err
+	}
+
+	if _, ok := fields["app_hash"]; !ok {
+		return errors.New("genesis document without app hash")
+	}
+	delete(fields, "app_hash")
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value, err := sdk.SortJSON(fields[name])
+		if err != nil {
+			return err
+		}
+
+		if _, err := fmt.Fprintf(w, ",%q:%s", name, value); err != nil {
+			return err
+		}
+	}
+
+	_, err = io.WriteString(w, "}\n")
+	return err
+}
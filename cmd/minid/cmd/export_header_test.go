@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testGenesis is a genesis document whose app state nests objects and arrays, with delimiters in its strings,
+// and is followed by other fields.
+const testGenesis = `{
+  "genesis_time": "2023-06-01T00:00:00Z",
+  "chain_id": "demo",
+  "app_state": {
+    "bank": {"balances": [{"address": "a", "coins": [{"denom": "mini", "amount": "1"}]}, {"address": "b", "coins": []}]},
+    "nested": [[[]], [{}], [[{"a": [1, 2, {"b": null}]}]]],
+    "strings": ["}", "]", "{\"app_state\": [", "\\"]
+  },
+  "initial_height": "7",
+  "app_hash": "",
+  "consensus_params": {
+    "block": {"max_bytes": "22020096", "max_gas": "-1"},
+    "evidence": {"max_age_num_blocks": "100000", "max_age_duration": "172800000000000", "max_bytes": "1048576"},
+    "validator": {"pub_key_types": ["ed25519"]},
+    "version": {"app": "0"}
+  }
+}
+`
+
+func TestGenesisDocHeaderFromFile(t *testing.T) {
+	genFile := filepath.Join(t.TempDir(), "genesis.json")
+	require.NoError(t, os.WriteFile(genFile, []byte(testGenesis), 0o600))
+
+	doc, err := genesisDocHeaderFromFile(genFile)
+	require.NoError(t, err)
+	require.Equal(t, "demo", doc.ChainID)
+	require.Equal(t, int64(7), doc.InitialHeight)
+	require.Equal(t, int64(22020096), doc.ConsensusParams.Block.MaxBytes)
+	require.Equal(t, []string{"ed25519"}, doc.ConsensusParams.Validator.PubKeyTypes)
+	require.Nil(t, doc.AppState)
+
+	// a truncated genesis is reported, wherever it is cut
+	for _, n := range []int{0, 1, 60, 200, 300, len(testGenesis) - 3} {
+		require.NoError(t, os.WriteFile(genFile, []byte(testGenesis[:n]), 0o600))
+
+		_, err := genesisDocHeaderFromFile(genFile)
+		require.Error(t, err, "truncated at %d", n)
+	}
+
+	// the document must be an object
+	require.NoError(t, os.WriteFile(genFile, []byte(`["chain_id"]`), 0o600))
+	_, err = genesisDocHeaderFromFile(genFile)
+	require.Error(t, err)
+
+	_, err = genesisDocHeaderFromFile(filepath.Join(t.TempDir(), "missing.json"))
+	require.Error(t, err)
+}
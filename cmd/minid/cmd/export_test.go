@@ -0,0 +1,174 @@
+package cmd_test
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	dbm "github.com/cometbft/cometbft-db"
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/libs/log"
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	cmttypes "github.com/cometbft/cometbft/types"
+	"github.com/cosmos/cosmos-sdk/baseapp"
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	simtestutil "github.com/cosmos/cosmos-sdk/testutil/sims"
+	"github.com/stretchr/testify/require"
+
+	"github.com/julienrbrt/chain-minimal/app"
+	"github.com/julienrbrt/chain-minimal/mempool"
+)
+
+// initNode initializes a single validator node in the given home, and commits its first block.
+func initNode(t *testing.T, home string) {
+	t.Helper()
+
+	require.NoError(t, execute(t, home, "init", "test", "--chain-id", "demo", "--default-denom", "mini"))
+	require.NoError(t, execute(t, home, "keys", "add", "val", "--keyring-backend", "test"))
+	require.NoError(t, execute(t, home, "genesis", "add-genesis-account", "val", "100000000mini", "--keyring-backend", "test"))
+	require.NoError(t, execute(t, home, "genesis", "gentx", "val", "1000000mini", "--chain-id", "demo", "--keyring-backend", "test"))
+	require.NoError(t, execute(t, home, "genesis", "collect-gentxs"))
+
+	genDoc, err := cmttypes.GenesisDocFromFile(filepath.Join(home, "config", "genesis.json"))
+	require.NoError(t, err)
+
+	db, err := dbm.NewDB("application", dbm.GoLevelDBBackend, filepath.Join(home, "data"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	miniApp := app.NewMiniApp(log.NewNopLogger(), db, nil, true, simtestutil.AppOptionsMap{
+		flags.FlagHome:            home,
+		mempool.FlagMempoolType:   mempool.TypeNone,
+		mempool.FlagMaxBundles:    0,
+		mempool.FlagRecheckBudget: -1,
+	}, baseapp.SetChainID(genDoc.ChainID))
+	defer miniApp.Close()
+
+	consensusParams := genDoc.ConsensusParams.ToProto()
+	miniApp.InitChain(abci.RequestInitChain{
+		Time:            genDoc.GenesisTime,
+		ChainId:         genDoc.ChainID,
+		ConsensusParams: &consensusParams,
+		AppStateBytes:   genDoc.AppState,
+		InitialHeight:   genDoc.InitialHeight,
+	})
+	miniApp.BeginBlock(abci.RequestBeginBlock{Header: cmtproto.Header{ChainID: genDoc.ChainID, Height: 1, Time: genDoc.GenesisTime}})
+	miniApp.EndBlock(abci.RequestEndBlock{Height: 1})
+	miniApp.Commit()
+}
+
+// copyHome copies the given home to a new temporary directory.
+func copyHome(t *testing.T, home string) string {
+	t.Helper()
+
+	dst := t.TempDir()
+	require.NoError(t, filepath.Walk(home, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(home, path)
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			return os.MkdirAll(filepath.Join(dst, rel), info.Mode())
+		}
+
+		bz, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		return os.WriteFile(filepath.Join(dst, rel), bz, info.Mode())
+	}))
+
+	return dst
+}
+
+// exportGenesis runs the export command of the node in the given home with the given flags, and returns
+// the exported genesis document along with its raw bytes. The export runs on a copy of the home, as the SDK
+// export command leaves its database open.
+func exportGenesis(t *testing.T, home string, args ...string) (*cmttypes.GenesisDoc, []byte) {
+	t.Helper()
+
+	home = copyHome(t, home)
+	output := filepath.Join(t.TempDir(), "genesis.json")
+	args = append([]string{"export", "--mempool-type", mempool.TypeNone, "--output-document", output}, args...)
+	require.NoError(t, execute(t, home, args...))
+
+	file, err := os.Open(output)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var r io.Reader = file
+	for _, arg := range args {
+		if arg == "--gzip" {
+			zr, err := gzip.NewReader(file)
+			require.NoError(t, err)
+			r = zr
+		}
+	}
+
+	bz, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	doc, err := cmttypes.GenesisDocFromJSON(bz)
+	require.NoError(t, err)
+
+	return doc, bz
+}
+
+func TestStreamExport(t *testing.T) {
+	home := t.TempDir()
+	initNode(t, home)
+
+	for _, forZeroHeight := range []bool{false, true} {
+		var args []string
+		if forZeroHeight {
+			args = append(args, "--for-zero-height")
+		}
+
+		expected, _ := exportGenesis(t, home, args...)
+		require.Equal(t, "demo", expected.ChainID)
+		require.Len(t, expected.Validators, 1)
+
+		for _, streamArgs := range [][]string{{"--stream"}, {"--stream", "--gzip"}} {
+			doc, bz := exportGenesis(t, home, append(streamArgs, args...)...)
+
+			// the streamed document is compact JSON
+			require.NotContains(t, string(bz), "\n ")
+
+			require.Equal(t, expected.ChainID, doc.ChainID)
+			require.Equal(t, expected.GenesisTime, doc.GenesisTime)
+			require.Equal(t, expected.InitialHeight, doc.InitialHeight)
+			require.Equal(t, expected.ConsensusParams, doc.ConsensusParams)
+			require.Equal(t, expected.Validators, doc.Validators)
+			require.Equal(t, expected.AppHash, doc.AppHash)
+			require.JSONEq(t, string(expected.AppState), string(doc.AppState))
+		}
+	}
+}
+
+func TestStreamExportErrors(t *testing.T) {
+	home := t.TempDir()
+	initNode(t, home)
+
+	output := filepath.Join(t.TempDir(), "genesis.json")
+	require.Error(t, execute(t, home, "export", "--mempool-type", mempool.TypeNone, "--gzip", "--output-document", output))
+	require.Error(t, execute(t, home, "export", "--mempool-type", mempool.TypeNone, "--dry-run"))
+	require.Error(t, execute(t, home, "export", "--mempool-type", mempool.TypeNone, "--dry-run", "--for-zero-height", "--stream"))
+
+	// the genesis header is read before anything is written
+	genesis := filepath.Join(home, "config", "genesis.json")
+	bz, err := os.ReadFile(genesis)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(genesis, bz[:len(bz)/2], 0o600))
+
+	require.Error(t, execute(t, home, "export", "--mempool-type", mempool.TypeNone, "--stream", "--output-document", output))
+	_, err = os.Stat(output)
+	require.True(t, os.IsNotExist(err))
+}
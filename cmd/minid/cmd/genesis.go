@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/cosmos/cosmos-sdk/server"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/genutil"
+	genutiltypes "github.com/cosmos/cosmos-sdk/x/genutil/types"
+	minttypes "github.com/cosmos/cosmos-sdk/x/mint/types"
+)
+
+const (
+	flagInflation           = "inflation"
+	flagInflationMin        = "inflation-min"
+	flagInflationMax        = "inflation-max"
+	flagInflationRateChange = "inflation-rate-change"
+	flagGoalBonded          = "goal-bonded"
+	flagBlocksPerYear       = "blocks-per-year"
+	flagMintDenom           = "mint-denom"
+)
+
+// setInflationCommand returns the command setting the inflation schedule of the mint module in genesis.json.
+func setInflationCommand(defaultNodeHome string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set-inflation",
+		Short: "Set the inflation schedule of the mint module in genesis.json",
+		Long: `Set the inflation schedule of the mint module in genesis.json. Only the given flags are changed.
+The inflation starts at the initial inflation, and moves each year by at most the inflation rate change towards
+the max inflation when less than the goal bonded ratio of the supply is bonded, and towards the min inflation otherwise.
+Set the blocks per year according to the block time of the chain, as the yearly provisions are minted per block.`,
+		Example: "minid genesis set-inflation --inflation 0.1 --inflation-min 0.05 --inflation-max 0.2 --blocks-per-year 31557600",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			clientCtx := client.GetClientContextFromCmd(cmd)
+			serverCtx := server.GetServerContextFromCmd(cmd)
+			serverCtx.Config.SetRoot(clientCtx.HomeDir)
+
+			genFile := serverCtx.Config.GenesisFile()
+			appState, genDoc, err := genutiltypes.GenesisStateFromGenFile(genFile)
+			if err != nil {
+				return fmt.Errorf("failed to unmarshal genesis state: %w", err)
+			}
+
+			var mintGenState minttypes.GenesisState
+			if err := clientCtx.Codec.UnmarshalJSON(appState[minttypes.ModuleName], &mintGenState); err != nil {
+				return fmt.Errorf("failed to unmarshal mint genesis state: %w", err)
+			}
+
+			if err := setInflationSchedule(cmd, &mintGenState); err != nil {
+				return err
+			}
+
+			if err := minttypes.ValidateGenesis(mintGenState); err != nil {
+				return fmt.Errorf("invalid inflation schedule: %w", err)
+			}
+
+			if appState[minttypes.ModuleName], err = clientCtx.Codec.MarshalJSON(&mintGenState); err != nil {
+				return fmt.Errorf("failed to marshal mint genesis state: %w", err)
+			}
+
+			if genDoc.AppState, err = json.Marshal(appState); err != nil {
+				return fmt.Errorf("failed to marshal application genesis state: %w", err)
+			}
+
+			if err := genutil.ExportGenesisFile(genDoc, genFile); err != nil {
+				return err
+			}
+
+			output, _ := cmd.Flags().GetString(flags.FlagOutput)
+			return clientCtx.WithOutputFormat(output).PrintProto(&mintGenState)
+		},
+	}
+
+	cmd.Flags().String(flags.FlagHome, defaultNodeHome, "The application home directory")
+	cmd.Flags().String(flagInflation, "", "Initial inflation rate (e.g. 0.13)")
+	cmd.Flags().String(flagInflationMin, "", "Minimum inflation rate (e.g. 0.07)")
+	cmd.Flags().String(flagInflationMax, "", "Maximum inflation rate (e.g. 0.20)")
+	cmd.Flags().String(flagInflationRateChange, "", "Maximum yearly change of the inflation rate (e.g. 0.13)")
+	cmd.Flags().String(flagGoalBonded, "", "Bonded ratio of the supply targeted by the inflation (e.g. 0.67)")
+	cmd.Flags().Uint64(flagBlocksPerYear, 0, "Expected number of blocks per year")
+	cmd.Flags().String(flagMintDenom, "", "Denomination of the minted coins")
+	cmd.Flags().StringP(flags.FlagOutput, "o", "text", "Output format (text|json)")
+
+	return cmd
+}
+
+// setInflationSchedule sets the inflation schedule flags given to the command in the mint genesis state.
+func setInflationSchedule(cmd *cobra.Command, genState *minttypes.GenesisState) error {
+	decFlags := []struct {
+		name string
+		dec  *sdk.Dec
+	}{
+		{flagInflation, &genState.Minter.Inflation},
+		{flagInflationMin, &genState.Params.InflationMin},
+		{flagInflationMax, &genState.Params.InflationMax},
+		{flagInflationRateChange, &genState.Params.InflationRateChange},
+		{flagGoalBonded, &genState.Params.GoalBonded},
+	}
+
+	for _, f := range decFlags {
+		if !cmd.Flags().Changed(f.name) {
+			continue
+		}
+
+		value, _ := cmd.Flags().GetString(f.name)
+		dec, err := sdk.NewDecFromStr(value)
+		if err != nil {
+			return fmt.Errorf("invalid --%s, got: %s: %w", f.name, value, err)
+		}
+		*f.dec = dec
+	}
+
+	if cmd.Flags().Changed(flagBlocksPerYear) {
+		genState.Params.BlocksPerYear, _ = cmd.Flags().GetUint64(flagBlocksPerYear)
+	}
+
+	if cmd.Flags().Changed(flagMintDenom) {
+		genState.Params.MintDenom, _ = cmd.Flags().GetString(flagMintDenom)
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package cmd_test
+
+import (
+	"io"
+	"path/filepath"
+	"testing"
+
+	svrcmd "github.com/cosmos/cosmos-sdk/server/cmd"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	moduletestutil "github.com/cosmos/cosmos-sdk/types/module/testutil"
+	genutiltypes "github.com/cosmos/cosmos-sdk/x/genutil/types"
+	"github.com/cosmos/cosmos-sdk/x/mint"
+	minttypes "github.com/cosmos/cosmos-sdk/x/mint/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/julienrbrt/chain-minimal/cmd/minid/cmd"
+)
+
+// execute runs minid with the given arguments.
+func execute(t *testing.T, home string, args ...string) error {
+	t.Helper()
+
+	rootCmd := cmd.NewRootCmd()
+	rootCmd.SetArgs(append(args, "--home", home))
+	rootCmd.SetOut(io.Discard)
+	rootCmd.SetErr(io.Discard)
+
+	return svrcmd.Execute(rootCmd, "", home)
+}
+
+// mintGenesis returns the mint genesis state of the genesis.json of the given home.
+func mintGenesis(t *testing.T, home string) minttypes.GenesisState {
+	t.Helper()
+
+	appState, _, err := genutiltypes.GenesisStateFromGenFile(filepath.Join(home, "config", "genesis.json"))
+	require.NoError(t, err)
+
+	var genState minttypes.GenesisState
+	moduletestutil.MakeTestEncodingConfig(mint.AppModuleBasic{}).Codec.MustUnmarshalJSON(appState[minttypes.ModuleName], &genState)
+
+	return genState
+}
+
+func TestSetInflation(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, execute(t, home, "init", "test", "--chain-id", "demo", "--default-denom", "mini"))
+
+	initial := mintGenesis(t, home)
+	require.Equal(t, "mini", initial.Params.MintDenom)
+
+	// only the given flags are changed
+	require.NoError(t, execute(t, home, "genesis", "set-inflation", "--inflation", "0.1", "--inflation-max", "0.25", "--blocks-per-year", "1000"))
+
+	genState := mintGenesis(t, home)
+	require.Equal(t, sdk.NewDecWithPrec(1, 1), genState.Minter.Inflation)
+	require.Equal(t, sdk.NewDecWithPrec(25, 2), genState.Params.InflationMax)
+	require.Equal(t, uint64(1000), genState.Params.BlocksPerYear)
+	require.Equal(t, initial.Params.InflationMin, genState.Params.InflationMin)
+	require.Equal(t, initial.Params.GoalBonded, genState.Params.GoalBonded)
+	require.Equal(t, initial.Params.MintDenom, genState.Params.MintDenom)
+
+	// an invalid schedule is not written
+	require.Error(t, execute(t, home, "genesis", "set-inflation", "--inflation-min", "0.3"))
+	require.Error(t, execute(t, home, "genesis", "set-inflation", "--goal-bonded", "abc"))
+	require.Equal(t, genState, mintGenesis(t, home))
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/cosmos/cosmos-sdk/client/grpc/node"
+)
+
+// minGasPricesCommand returns the command querying the current minimum gas prices of a node.
+func minGasPricesCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "min-gas-prices",
+		Short: "Query the current minimum gas prices of the node",
+		Long:  "Query the current minimum gas prices of the node. They can rise with the mempool occupancy when dynamic minimum gas prices are enabled.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			clientCtx, err := client.GetClientQueryContext(cmd)
+			if err != nil {
+				return err
+			}
+
+			res, err := node.NewServiceClient(clientCtx).Config(cmd.Context(), &node.ConfigRequest{})
+			if err != nil {
+				return err
+			}
+
+			return clientCtx.PrintProto(res)
+		},
+	}
+
+	flags.AddQueryFlagsToCmd(cmd)
+
+	return cmd
+}
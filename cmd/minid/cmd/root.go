@@ -15,7 +15,6 @@ import (
 	"cosmossdk.io/depinject"
 	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/client/config"
-	"github.com/cosmos/cosmos-sdk/client/debug"
 	"github.com/cosmos/cosmos-sdk/client/flags"
 	"github.com/cosmos/cosmos-sdk/client/keys"
 	"github.com/cosmos/cosmos-sdk/client/rpc"
@@ -53,6 +52,9 @@ func NewRootCmd() *cobra.Command {
 		panic(err)
 	}
 
+	// the IBC modules are not provided by AppConfig
+	app.RegisterIBCInterfaces(interfaceRegistry, legacyAmino)
+
 	initClientCtx := client.Context{}.
 		WithCodec(appCodec).
 		WithInterfaceRegistry(interfaceRegistry).
@@ -85,11 +87,9 @@ func NewRootCmd() *cobra.Command {
 				return err
 			}
 
-			// overwrite the minimum gas price from the app configuration
-			srvCfg := serverconfig.DefaultConfig()
-			srvCfg.MinGasPrices = "0mini"
+			customAppTemplate, customAppConfig := initAppConfig()
 
-			return server.InterceptConfigsPreRunHandler(cmd, serverconfig.DefaultConfigTemplate, srvCfg, initCometBFTConfig())
+			return server.InterceptConfigsPreRunHandler(cmd, customAppTemplate, customAppConfig, initCometBFTConfig())
 		},
 	}
 
@@ -111,6 +111,31 @@ func initCometBFTConfig() *cmtcfg.Config {
 	return cfg
 }
 
+// CustomAppConfig defines the app configuration (app.toml), with the app custom sections.
+type CustomAppConfig struct {
+	serverconfig.Config `mapstructure:",squash"`
+
+	// MempoolWeights are the message type weights of the fee mempool
+	MempoolWeights mempool.WeightsConfig `mapstructure:"mempool-weights"`
+}
+
+// initAppConfig helps to override default app.toml values and adds the app custom sections.
+func initAppConfig() (string, interface{}) {
+	// overwrite the minimum gas price from the app configuration
+	// NOTE: this is the node-local minimum, the chain-wide minimum is the base fee of the basefee module
+	// NOTE: it can be raised dynamically with the mempool occupancy (see --mempool-min-gas-prices-curve)
+	// NOTE: transactions paying no fee are accepted within the free transaction allowance of the freetx module
+	srvCfg := serverconfig.DefaultConfig()
+	srvCfg.MinGasPrices = "0mini"
+
+	customAppConfig := CustomAppConfig{
+		Config:         *srvCfg,
+		MempoolWeights: mempool.DefaultWeightsConfig(),
+	}
+
+	return serverconfig.DefaultConfigTemplate + mempool.WeightsConfigTemplate, customAppConfig
+}
+
 func initRootCmd(
 	rootCmd *cobra.Command,
 	txConfig client.TxConfig,
@@ -122,22 +147,61 @@ func initRootCmd(
 
 	rootCmd.AddCommand(
 		genutilcli.InitCmd(app.ModuleBasics, app.DefaultNodeHome),
-		debug.Cmd(),
+		debugCommand(),
 		config.Cmd(),
 	)
 
 	server.AddCommands(rootCmd, app.DefaultNodeHome, newApp, appExport, addModuleInitFlags)
+	extendStartCommand(rootCmd)
+	extendExportCommand(rootCmd)
+
+	genesisCmd := genutilcli.GenesisCoreCommand(txConfig, app.ModuleBasics, app.DefaultNodeHome)
+	genesisCmd.AddCommand(setInflationCommand(app.DefaultNodeHome))
 
 	// add keybase, auxiliary RPC, query, genesis, and tx child commands
 	rootCmd.AddCommand(
 		rpc.StatusCommand(),
-		genutilcli.GenesisCoreCommand(txConfig, app.ModuleBasics, app.DefaultNodeHome),
+		genesisCmd,
 		queryCommand(),
 		txCommand(),
 		keys.Commands(app.DefaultNodeHome),
 	)
 
 	rootCmd.PersistentFlags().String(mempool.FlagMempoolType, "", "Select a mempool to use (none|fee|sender-nonce) - NOTE this is for demonstration purposes only")
+	rootCmd.PersistentFlags().String(mempool.FlagPriorityStrategy, string(mempool.PriorityStrategyFee), "How the fee mempool computes the priority of a transaction (fee|ante|min-coin|gas-price), strategies joined with a + are summed (e.g. gas-price+ante), ante uses the priority computed by the ante handler TxFeeChecker")
+	rootCmd.PersistentFlags().String(mempool.FlagAgingCurve, mempool.CurveNone, "Curve raising the priority of the fee mempool transactions with the number of blocks they have been pending (none|linear|exponential)")
+	rootCmd.PersistentFlags().Int64(mempool.FlagAgingRate, mempool.DefaultPriorityAgingRate, "Priority gained per pending block (linear), or after the first pending block and doubling afterwards (exponential)")
+	rootCmd.PersistentFlags().String(mempool.FlagMinGasPricesCurve, mempool.CurveNone, "Curve raising the node minimum gas prices with the app-side mempool occupancy (none|linear|exponential), requires mempool.max-txs")
+	rootCmd.PersistentFlags().Float64(mempool.FlagMinGasPricesLowOccupancy, mempool.DefaultMinGasPricesLowOccupancy, "Mempool occupancy (0-1) from which the node minimum gas prices start to rise")
+	rootCmd.PersistentFlags().Float64(mempool.FlagMinGasPricesHighOccupancy, mempool.DefaultMinGasPricesHighOccupancy, "Mempool occupancy (0-1) at which the node minimum gas prices reach the maximum gas prices")
+	rootCmd.PersistentFlags().String(mempool.FlagMaxGasPrices, "", "Minimum gas prices required once the mempool occupancy reaches the high threshold (e.g. 0.01mini)")
+	rootCmd.PersistentFlags().String(mempool.FlagJournal, "", "Record the app-side mempool operations to the given file, to be replayed with debug mempool-replay")
+	rootCmd.PersistentFlags().Int(mempool.FlagMaxBundles, mempool.DefaultMaxBundles, "Maximum number of pending transaction bundles in the app-side mempool (0 to disable bundles)")
+	rootCmd.PersistentFlags().Int64(mempool.FlagBundleTTL, mempool.DefaultBundleTTL, "Number of blocks a transaction bundle stays in the app-side mempool before expiring (0 for no expiry)")
+	rootCmd.PersistentFlags().String(mempool.FlagStakeBoostTokens, "0", "Bonded tokens of the fee payer giving a fee mempool priority boost of 1 (0 to disable the stake boost)")
+	rootCmd.PersistentFlags().Int64(mempool.FlagStakeBoostMax, mempool.DefaultStakeBoostMax, "Maximum fee mempool priority boost of the stakers")
+	rootCmd.PersistentFlags().Float64(mempool.FlagPrepareProposalBudget, 0.5, "Share of the CometBFT propose timeout (consensus.timeout_propose) the app-side mempool transaction selection can take when proposing (0 for unlimited)")
+	rootCmd.PersistentFlags().Int64(mempool.FlagMaxBytes, 0, "Maximum total size in bytes of the transactions in the fee and sender-nonce mempools (0 for unbounded)")
+	rootCmd.PersistentFlags().Int(mempool.FlagMaxTxsPerPayer, 0, "Maximum number of transactions in the fee mempool whose fees are paid by the same account, the fee granter if any (0 for unbounded)")
+	rootCmd.PersistentFlags().Int(mempool.FlagMaxFreeTxs, 0, "Maximum number of zero-fee transactions in the fee mempool (0 for unbounded)")
+	rootCmd.PersistentFlags().Int(mempool.FlagFreeTxSlots, 0, "Number of transactions per block reserved to zero-fee transactions within the free transaction allowance (0 for no limit on the number)")
+	rootCmd.PersistentFlags().Uint64(mempool.FlagFreeTxGas, 0, "Gas per block reserved to zero-fee transactions within the free transaction allowance (0 for no limit on the gas), no reservation when both the slots and the gas are 0")
+	rootCmd.PersistentFlags().Int(mempool.FlagRecheckBudget, mempool.DefaultRecheckBudget, "Maximum number of app-side mempool transactions rechecked after each commit, which delays the next block (0 for all, -1 to disable)")
+}
+
+// extendStartCommand makes the app started by the start command reload the message type weights of the fee mempool
+// on SIGHUP. The other commands creating the app (e.g. export) leave the signal handling of the process untouched.
+func extendStartCommand(rootCmd *cobra.Command) {
+	cmd, _, err := rootCmd.Find([]string{"start"})
+	if err != nil {
+		panic(err)
+	}
+
+	startRunE := cmd.RunE
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		server.GetServerContextFromCmd(cmd).Viper.Set(mempool.OptWeightsReloadOnSignal, true)
+		return startRunE(cmd, args)
+	}
 }
 
 func addModuleInitFlags(startCmd *cobra.Command) {
@@ -160,6 +224,7 @@ func queryCommand() *cobra.Command {
 		rpc.BlockCommand(),
 		authcmd.QueryTxsByEventsCmd(),
 		authcmd.QueryTxCmd(),
+		minGasPricesCommand(),
 	)
 
 	app.ModuleBasics.AddQueryCommands(cmd)
@@ -187,6 +252,7 @@ func txCommand() *cobra.Command {
 		authcmd.GetEncodeCommand(),
 		authcmd.GetDecodeCommand(),
 		authcmd.GetAuxToFeeCommand(),
+		submitBundleCommand(),
 	)
 
 	app.ModuleBasics.AddTxCommands(cmd)
@@ -212,33 +278,46 @@ func appExport(
 	appOpts servertypes.AppOptions,
 	modulesToExport []string,
 ) (servertypes.ExportedApp, error) {
-	var miniApp *app.MiniApp
+	miniApp, err := loadExportApp(logger, db, traceStore, height, appOpts)
+	if err != nil {
+		return servertypes.ExportedApp{}, err
+	}
 
+	return miniApp.ExportAppStateAndValidators(forZeroHeight, jailAllowedAddrs, modulesToExport)
+}
+
+// loadExportApp creates a new app loaded at the given height (-1 for the latest height), to export its state.
+func loadExportApp(
+	logger log.Logger,
+	db dbm.DB,
+	traceStore io.Writer,
+	height int64,
+	appOpts servertypes.AppOptions,
+) (*app.MiniApp, error) {
 	// this check is necessary as we use the flag in x/upgrade.
 	// we can exit more gracefully by checking the flag here.
 	homePath, ok := appOpts.Get(flags.FlagHome).(string)
 	if !ok || homePath == "" {
-		return servertypes.ExportedApp{}, errors.New("application home not set")
+		return nil, errors.New("application home not set")
 	}
 
 	viperAppOpts, ok := appOpts.(*viper.Viper)
 	if !ok {
-		return servertypes.ExportedApp{}, errors.New("appOpts is not viper.Viper")
+		return nil, errors.New("appOpts is not viper.Viper")
 	}
 
 	// overwrite the FlagInvCheckPeriod
 	viperAppOpts.Set(server.FlagInvCheckPeriod, 1)
 	appOpts = viperAppOpts
 
-	if height != -1 {
-		miniApp = app.NewMiniApp(logger, db, traceStore, false, appOpts)
+	if height == -1 {
+		return app.NewMiniApp(logger, db, traceStore, true, appOpts), nil
+	}
 
-		if err := miniApp.LoadHeight(height); err != nil {
-			return servertypes.ExportedApp{}, err
-		}
-	} else {
-		miniApp = app.NewMiniApp(logger, db, traceStore, true, appOpts)
+	miniApp := app.NewMiniApp(logger, db, traceStore, false, appOpts)
+	if err := miniApp.LoadHeight(height); err != nil {
+		return nil, err
 	}
 
-	return miniApp.ExportAppStateAndValidators(forZeroHeight, jailAllowedAddrs, modulesToExport)
+	return miniApp, nil
 }
@@ -0,0 +1,75 @@
+package mempool
+
+import (
+	"fmt"
+	"math"
+)
+
+// DefaultPriorityAgingRate is the default priority gained by a transaction per pending block.
+var DefaultPriorityAgingRate int64 = 10
+
+// PriorityAging raises the priority of a transaction with the number of blocks it has been pending, so that
+// transactions paying a low or no fee are eventually included under a continuous load of higher paying ones.
+// The aged priority saturates at math.MaxInt64.
+type PriorityAging struct {
+	// Curve is the aging curve (none|linear|exponential).
+	Curve string
+	// Rate is the priority gained per pending block with the linear curve. With the exponential curve, it is
+	// the priority gained after the first pending block, the gain doubling with each additional pending block.
+	Rate int64
+}
+
+// Enabled returns whether the aging is enabled.
+func (a PriorityAging) Enabled() bool {
+	return a.Curve != "" && a.Curve != CurveNone
+}
+
+// Validate validates the aging configuration.
+func (a PriorityAging) Validate() error {
+	if !a.Enabled() {
+		return nil
+	}
+
+	if a.Curve != CurveLinear && a.Curve != CurveExponential {
+		return fmt.Errorf("invalid aging curve, got: %s, want %s|%s|%s", a.Curve, CurveNone, CurveLinear, CurveExponential)
+	}
+
+	if a.Rate <= 0 {
+		return fmt.Errorf("aging rate must be positive, got: %d", a.Rate)
+	}
+
+	return nil
+}
+
+// Boost returns the priority gained by a transaction pending for the given number of blocks.
+func (a PriorityAging) Boost(blocks int64) int64 {
+	if !a.Enabled() || blocks <= 0 {
+		return 0
+	}
+
+	factor := blocks
+	if a.Curve == CurveExponential {
+		// 2^blocks - 1, i.e. Rate, 3*Rate, 7*Rate, ...
+		if blocks >= 63 {
+			return math.MaxInt64
+		}
+
+		factor = 1<<blocks - 1
+	}
+
+	if factor > math.MaxInt64/a.Rate {
+		return math.MaxInt64
+	}
+
+	return factor * a.Rate
+}
+
+// Apply returns the aged priority of a transaction inserted at the given height.
+func (a PriorityAging) Apply(priority, insertedAt, height int64) int64 {
+	boost := a.Boost(height - insertedAt)
+	if priority > 0 && boost > math.MaxInt64-priority {
+		return math.MaxInt64
+	}
+
+	return priority + boost
+}
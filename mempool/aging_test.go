@@ -0,0 +1,116 @@
+package mempool_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/cometbft/cometbft/libs/log"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	simtypes "github.com/cosmos/cosmos-sdk/types/simulation"
+	"github.com/stretchr/testify/require"
+
+	"github.com/julienrbrt/chain-minimal/mempool"
+)
+
+func TestPriorityAging(t *testing.T) {
+	linear := mempool.PriorityAging{Curve: mempool.CurveLinear, Rate: 10}
+	require.Equal(t, int64(0), linear.Boost(0))
+	require.Equal(t, int64(10), linear.Boost(1))
+	require.Equal(t, int64(50), linear.Boost(5))
+	require.Equal(t, int64(120), linear.Apply(100, 3, 5))
+
+	exponential := mempool.PriorityAging{Curve: mempool.CurveExponential, Rate: 10}
+	require.Equal(t, int64(10), exponential.Boost(1))
+	require.Equal(t, int64(70), exponential.Boost(3))
+	require.Equal(t, int64(math.MaxInt64), exponential.Boost(100))
+	require.Equal(t, int64(math.MaxInt64), exponential.Apply(100, 0, 62))
+
+	// a tx cannot age before being inserted
+	require.Equal(t, int64(100), linear.Apply(100, 5, 3))
+	require.Equal(t, int64(0), mempool.PriorityAging{Curve: mempool.CurveNone, Rate: 10}.Boost(5))
+
+	require.NoError(t, mempool.PriorityAging{}.Validate())
+	require.Error(t, mempool.PriorityAging{Curve: "quadratic", Rate: 10}.Validate())
+	require.Error(t, mempool.PriorityAging{Curve: mempool.CurveLinear}.Validate())
+}
+
+// TestFeeMempoolAgingStarvation checks that a zero-fee tx is included within a bounded number of blocks
+// under a continuous load of paid txs exceeding the block capacity, and is starved without aging.
+func TestFeeMempoolAgingStarvation(t *testing.T) {
+	const (
+		blockSize = 2
+		arrivals  = 3 // paid txs arriving per block, more than the block capacity
+		paidFee   = 100
+		maxBlocks = 200
+	)
+
+	testCases := []struct {
+		name      string
+		aging     mempool.PriorityAging
+		maxBlocks int // maximum number of blocks before the zero-fee tx is included, 0 if never
+	}{
+		{
+			name:  "no aging",
+			aging: mempool.PriorityAging{Curve: mempool.CurveNone},
+		},
+		{
+			// the zero-fee tx outranks the paid txs arriving paidFee/rate blocks after it,
+			// so at most (paidFee/rate+1)*arrivals txs are selected before it
+			name:      "linear",
+			aging:     mempool.PriorityAging{Curve: mempool.CurveLinear, Rate: 10},
+			maxBlocks: (paidFee/10 + 1) * arrivals / blockSize,
+		},
+		{
+			name:      "linear slow",
+			aging:     mempool.PriorityAging{Curve: mempool.CurveLinear, Rate: 1},
+			maxBlocks: (paidFee/1 + 1) * arrivals / blockSize,
+		},
+		{
+			name:      "exponential",
+			aging:     mempool.PriorityAging{Curve: mempool.CurveExponential, Rate: 1},
+			maxBlocks: 10,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			accounts := simtypes.RandomAccounts(rand.New(rand.NewSource(0)), arrivals+1)
+			pool := mempool.NewFeeMempool(log.NewNopLogger(), mempool.FeeMempoolAgingOpt(tc.aging))
+
+			// carol sends a zero-fee tx at the first block
+			carol := accounts[arrivals].Address
+			require.NoError(t, pool.Insert(sdk.Context{}.WithBlockHeight(1), testTx{id: -1, address: carol, gas: 1}))
+
+			includedAt := 0
+			for height := 1; height <= maxBlocks && includedAt == 0; height++ {
+				ctx := sdk.Context{}.WithBlockHeight(int64(height))
+
+				for i := 0; i < arrivals; i++ {
+					tx := testTx{id: height*arrivals + i, address: accounts[i].Address, nonce: uint64(height), priority: paidFee, gas: 1}
+					require.NoError(t, pool.Insert(ctx, tx))
+				}
+
+				var block []sdk.Tx
+				for it := pool.Select(ctx, nil); it != nil && len(block) < blockSize; it = it.Next() {
+					block = append(block, it.Tx())
+				}
+
+				for _, tx := range block {
+					if tx.(testTx).id == -1 {
+						includedAt = height
+					}
+					require.NoError(t, pool.Remove(tx))
+				}
+			}
+
+			if tc.maxBlocks == 0 {
+				require.Zero(t, includedAt, "the zero-fee tx should be starved without aging")
+				return
+			}
+
+			require.NotZero(t, includedAt, "the zero-fee tx was never included")
+			require.LessOrEqual(t, includedAt, tc.maxBlocks)
+		})
+	}
+}
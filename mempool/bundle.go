@@ -0,0 +1,373 @@
+package mempool
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/mempool"
+)
+
+// MaxBundleTxs is the maximum number of transactions in a bundle.
+const MaxBundleTxs = 16
+
+// DefaultMaxBundles is the default maximum number of pending bundles.
+var DefaultMaxBundles = 100
+
+// DefaultBundleTTL is the default number of blocks a bundle stays pending.
+var DefaultBundleTTL int64 = 20
+
+// Bundle is an ordered list of signed transactions, possibly from different senders,
+// included in a block contiguously and in order, or not at all.
+type Bundle struct {
+	// ID is the hex encoded hash of the transaction hashes of the bundle.
+	ID      string
+	Txs     []sdk.Tx
+	TxBytes [][]byte
+	// GasPrice is the aggregate gas price of the bundle: the sum of the fees over the sum of the gas limits.
+	GasPrice sdk.Dec
+	// Height is the block height at which the bundle was inserted.
+	Height int64
+
+	hashes [][sha256.Size]byte
+}
+
+// Size returns the size in bytes of the transactions of the bundle.
+func (b *Bundle) Size() int64 {
+	var size int64
+	for _, bz := range b.TxBytes {
+		size += int64(len(bz))
+	}
+
+	return size
+}
+
+// BundleIterator is the iterator returned by BundleMempool.Select.
+type BundleIterator interface {
+	mempool.Iterator
+
+	// Bundle returns the bundle of the current transaction, nil when the transaction is not part of a bundle.
+	Bundle() *Bundle
+}
+
+var _ mempool.Mempool = (*BundleMempool)(nil)
+
+// BundleMempool wraps a mempool and adds atomic bundles to it. Bundles are kept apart from the wrapped
+// mempool and ranked by their aggregate gas price in the fee denom. Select merges them into the order of
+// the wrapped mempool, before the first transaction with a lower gas price, and yields their transactions
+// contiguously. The proposal handler is responsible for including or dropping each bundle as a whole.
+//
+// Bundles expire once they have been pending for the TTL, and are rechecked after each commit (see Rechecker).
+//
+// NOTE: Removing any transaction of a bundle removes the whole bundle.
+type BundleMempool struct {
+	mempool.Mempool
+
+	txEncoder  sdk.TxEncoder
+	denom      string
+	maxBundles int
+	// ttl is the number of blocks a bundle stays pending, 0 for no expiry
+	ttl int64
+
+	mu        sync.Mutex
+	bundles   []*Bundle // sorted by gas price, then by arrival
+	byHash    map[[sha256.Size]byte]*Bundle
+	txCount   int
+	sizeBytes int64
+}
+
+// NewBundleMempool creates a new BundleMempool wrapping the given mempool.
+// The gas price of the bundles is computed in the given denom, and they expire after ttl blocks (0 for no expiry).
+func NewBundleMempool(mp mempool.Mempool, txEncoder sdk.TxEncoder, denom string, maxBundles int, ttl int64) *BundleMempool {
+	return &BundleMempool{
+		Mempool:    mp,
+		txEncoder:  txEncoder,
+		denom:      denom,
+		maxBundles: maxBundles,
+		ttl:        ttl,
+		byHash:     make(map[[sha256.Size]byte]*Bundle),
+	}
+}
+
+// InsertBundle inserts an ordered list of transactions as a bundle, at the block height of the context.
+// The transactions must have been validated, in order, by the caller.
+func (bm *BundleMempool) InsertBundle(ctx context.Context, txs []sdk.Tx) (*Bundle, error) {
+	if len(txs) == 0 || len(txs) > MaxBundleTxs {
+		return nil, fmt.Errorf("a bundle must have between 1 and %d transactions, got: %d", MaxBundleTxs, len(txs))
+	}
+
+	bundle := &Bundle{Txs: txs}
+	if sdkCtx, ok := ctx.(sdk.Context); ok {
+		bundle.Height = sdkCtx.BlockHeight()
+	}
+
+	var (
+		fees   = sdk.ZeroInt()
+		gas    uint64
+		digest = sha256.New()
+	)
+	for i, tx := range txs {
+		feeTx, ok := tx.(sdk.FeeTx)
+		if !ok {
+			return nil, fmt.Errorf("transaction %d must be a FeeTx", i)
+		}
+
+		bz, err := bm.txEncoder(tx)
+		if err != nil {
+			return nil, fmt.Errorf("transaction %d: %w", i, err)
+		}
+
+		hash := sha256.Sum256(bz)
+		digest.Write(hash[:])
+
+		bundle.TxBytes = append(bundle.TxBytes, bz)
+		bundle.hashes = append(bundle.hashes, hash)
+		fees = fees.Add(feeTx.GetFee().AmountOf(bm.denom))
+		gas += feeTx.GetGas()
+	}
+
+	if gas == 0 {
+		return nil, fmt.Errorf("a bundle must have a positive gas limit")
+	}
+
+	bundle.ID = hex.EncodeToString(digest.Sum(nil))
+	bundle.GasPrice = sdk.NewDecFromInt(fees).QuoInt64(int64(gas))
+
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+
+	if len(bm.bundles) >= bm.maxBundles {
+		return nil, mempool.ErrMempoolTxMaxCapacity
+	}
+
+	for i, hash := range bundle.hashes {
+		if _, ok := bm.byHash[hash]; ok {
+			return nil, fmt.Errorf("transaction %d is already part of a bundle", i)
+		}
+	}
+
+	// insert after the bundles of the same gas price, so that they keep their arrival order
+	i := sort.Search(len(bm.bundles), func(i int) bool { return bm.bundles[i].GasPrice.LT(bundle.GasPrice) })
+	bm.bundles = append(bm.bundles, nil)
+	copy(bm.bundles[i+1:], bm.bundles[i:])
+	bm.bundles[i] = bundle
+
+	for _, hash := range bundle.hashes {
+		bm.byHash[hash] = bundle
+	}
+	bm.txCount += len(bundle.Txs)
+	bm.sizeBytes += bundle.Size()
+
+	return bundle, nil
+}
+
+// Select returns an iterator over the transactions of the wrapped mempool and of the bundles.
+func (bm *BundleMempool) Select(ctx context.Context, txs [][]byte) mempool.Iterator {
+	bm.mu.Lock()
+	bundles := make([]*Bundle, len(bm.bundles))
+	copy(bundles, bm.bundles)
+	bm.mu.Unlock()
+
+	it := &bundleIterator{
+		ctx:     ctx,
+		inner:   bm.Mempool.Select(ctx, txs),
+		bundles: bundles,
+		denom:   bm.denom,
+	}
+
+	return it.advance()
+}
+
+// CountTx returns the number of transactions of the wrapped mempool and of the bundles.
+func (bm *BundleMempool) CountTx() int {
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+
+	return bm.Mempool.CountTx() + bm.txCount
+}
+
+// Remove removes the bundle of the transaction, or the transaction from the wrapped mempool
+// when it is not part of a bundle.
+func (bm *BundleMempool) Remove(tx sdk.Tx) error {
+	bm.mu.Lock()
+	removed := false
+	if len(bm.byHash) > 0 {
+		if bz, err := bm.txEncoder(tx); err == nil {
+			removed = bm.remove(sha256.Sum256(bz))
+		}
+	}
+	bm.mu.Unlock()
+
+	if removed {
+		return nil
+	}
+
+	return bm.Mempool.Remove(tx)
+}
+
+// SizeBytes returns the encoded size of the transactions of the wrapped mempool and of the bundles.
+// The size of the wrapped mempool is only counted when it keeps track of it.
+func (bm *BundleMempool) SizeBytes() int64 {
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+
+	return sizeBytes(bm.Mempool) + bm.sizeBytes
+}
+
+// CountBundles returns the number of pending bundles.
+func (bm *BundleMempool) CountBundles() int {
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+
+	return len(bm.bundles)
+}
+
+// Bundles returns the pending bundles, by gas price.
+func (bm *BundleMempool) Bundles() []*Bundle {
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+
+	bundles := make([]*Bundle, len(bm.bundles))
+	copy(bundles, bm.bundles)
+
+	return bundles
+}
+
+// Expire removes the bundles pending for the TTL at the given height, and returns their number.
+func (bm *BundleMempool) Expire(height int64) int {
+	if bm.ttl <= 0 {
+		return 0
+	}
+
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+
+	var expired []*Bundle
+	for _, bundle := range bm.bundles {
+		if height-bundle.Height >= bm.ttl {
+			expired = append(expired, bundle)
+		}
+	}
+
+	for _, bundle := range expired {
+		bm.remove(bundle.hashes[0])
+	}
+
+	return len(expired)
+}
+
+// remove removes the bundle of the transaction with the given hash, it returns whether one was found.
+func (bm *BundleMempool) remove(hash [sha256.Size]byte) bool {
+	bundle, ok := bm.byHash[hash]
+	if !ok {
+		return false
+	}
+
+	for _, hash := range bundle.hashes {
+		delete(bm.byHash, hash)
+	}
+	bm.txCount -= len(bundle.Txs)
+	bm.sizeBytes -= bundle.Size()
+
+	for i, b := range bm.bundles {
+		if b == bundle {
+			bm.bundles = append(bm.bundles[:i], bm.bundles[i+1:]...)
+			break
+		}
+	}
+
+	return true
+}
+
+var (
+	_ BundleIterator = (*bundleIterator)(nil)
+	_ SizedIterator  = (*bundleIterator)(nil)
+)
+
+// bundleIterator merges a snapshot of the bundles into the order of the wrapped mempool.
+type bundleIterator struct {
+	ctx     context.Context
+	inner   mempool.Iterator
+	bundles []*Bundle
+	denom   string
+
+	// bundle is the bundle of the current transaction, nil when it comes from the wrapped mempool
+	bundle *Bundle
+	member int
+}
+
+func (it *bundleIterator) Next() mempool.Iterator {
+	if it.bundle == nil {
+		it.inner = it.inner.Next()
+		return it.advance()
+	}
+
+	if it.member+1 < len(it.bundle.Txs) {
+		it.member++
+		return it
+	}
+
+	it.bundles = it.bundles[1:]
+	return it.advance()
+}
+
+func (it *bundleIterator) Tx() sdk.Tx {
+	if it.bundle != nil {
+		return it.bundle.Txs[it.member]
+	}
+
+	return it.inner.Tx()
+}
+
+// Size returns the encoded size of the current transaction, 0 when unknown.
+func (it *bundleIterator) Size() int64 {
+	if it.bundle != nil {
+		return int64(len(it.bundle.TxBytes[it.member]))
+	}
+
+	return IteratorSize(it.inner)
+}
+
+// TxBytes returns the encoded current transaction, nil when unknown.
+func (it *bundleIterator) TxBytes() []byte {
+	if it.bundle != nil {
+		return it.bundle.TxBytes[it.member]
+	}
+
+	return IteratorTxBytes(it.inner)
+}
+
+func (it *bundleIterator) Bundle() *Bundle {
+	return it.bundle
+}
+
+// advance positions the iterator on the next bundle or on the current transaction of the wrapped mempool,
+// whichever has the highest gas price. Bundles win ties.
+func (it *bundleIterator) advance() mempool.Iterator {
+	it.bundle, it.member = nil, 0
+
+	switch {
+	case Done(it.ctx), len(it.bundles) == 0 && it.inner == nil:
+		return nil
+	case len(it.bundles) == 0:
+		return it
+	case it.inner == nil || it.bundles[0].GasPrice.GTE(txGasPrice(it.inner.Tx(), it.denom)):
+		it.bundle = it.bundles[0]
+	}
+
+	return it
+}
+
+// txGasPrice returns the gas price of the transaction in the given denom.
+func txGasPrice(tx sdk.Tx, denom string) sdk.Dec {
+	feeTx, ok := tx.(sdk.FeeTx)
+	if !ok || feeTx.GetGas() == 0 {
+		return sdk.ZeroDec()
+	}
+
+	return sdk.NewDecFromInt(feeTx.GetFee().AmountOf(denom)).QuoInt64(int64(feeTx.GetGas()))
+}
@@ -0,0 +1,82 @@
+package mempool
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/mempool"
+
+	"github.com/julienrbrt/chain-minimal/mempool/types"
+)
+
+var _ types.ServiceServer = bundleServer{}
+
+type bundleServer struct {
+	mempool      *BundleMempool
+	txDecoder    sdk.TxDecoder
+	anteHandler  sdk.AnteHandler
+	checkContext func() (sdk.Context, error)
+}
+
+// NewBundleServer creates the mempool gRPC service, submitting bundles to the given mempool.
+// Bundles are validated by running the ante handler over their transactions, in order,
+// on a branch of the context returned by checkContext (i.e. the latest committed state in check mode).
+//
+// The service changes the mempool, so it must be registered on the gRPC server of the node,
+// not on the query router which also serves the ABCI queries.
+func NewBundleServer(bm *BundleMempool, txDecoder sdk.TxDecoder, anteHandler sdk.AnteHandler, checkContext func() (sdk.Context, error)) types.ServiceServer {
+	return bundleServer{
+		mempool:      bm,
+		txDecoder:    txDecoder,
+		anteHandler:  anteHandler,
+		checkContext: checkContext,
+	}
+}
+
+// SubmitBundle validates the bundle and inserts it in the mempool. The bundle is only included, atomically, in the
+// blocks proposed by this node, as it is not gossiped and the other validators do not enforce its atomicity.
+func (s bundleServer) SubmitBundle(_ context.Context, req *types.SubmitBundleRequest) (*types.SubmitBundleResponse, error) {
+	if req == nil || len(req.Txs) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "empty bundle")
+	}
+
+	if len(req.Txs) > MaxBundleTxs {
+		return nil, status.Errorf(codes.InvalidArgument, "a bundle must have at most %d transactions, got: %d", MaxBundleTxs, len(req.Txs))
+	}
+
+	ctx, err := s.checkContext()
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	checkCtx, _ := ctx.CacheContext()
+
+	txs := make([]sdk.Tx, len(req.Txs))
+	for i, bz := range req.Txs {
+		tx, err := s.txDecoder(bz)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "transaction %d: %v", i, err)
+		}
+
+		// the ante handler state changes (e.g. sequences) are kept for the next transactions of the bundle
+		if _, err := s.anteHandler(checkCtx.WithTxBytes(bz), tx, false); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "transaction %d: %v", i, err)
+		}
+
+		txs[i] = tx
+	}
+
+	bundle, err := s.mempool.InsertBundle(ctx, txs)
+	if err != nil {
+		if errors.Is(err, mempool.ErrMempoolTxMaxCapacity) {
+			return nil, status.Error(codes.ResourceExhausted, err.Error())
+		}
+
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return &types.SubmitBundleResponse{BundleId: bundle.ID}, nil
+}
@@ -0,0 +1,164 @@
+package mempool_test
+
+import (
+	"context"
+	"encoding/binary"
+	"math/rand"
+	"testing"
+
+	"github.com/cometbft/cometbft/libs/log"
+	storetypes "github.com/cosmos/cosmos-sdk/store/types"
+	"github.com/cosmos/cosmos-sdk/testutil"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkmempool "github.com/cosmos/cosmos-sdk/types/mempool"
+	simtypes "github.com/cosmos/cosmos-sdk/types/simulation"
+	"github.com/stretchr/testify/require"
+
+	"github.com/julienrbrt/chain-minimal/mempool"
+	"github.com/julienrbrt/chain-minimal/mempool/types"
+)
+
+func TestBundleMempool(t *testing.T) {
+	accounts := simtypes.RandomAccounts(rand.New(rand.NewSource(0)), 4)
+	sa, sb, sc, sd := accounts[0].Address, accounts[1].Address, accounts[2].Address, accounts[3].Address
+
+	pool := mempool.NewBundleMempool(mempool.NewFeeMempool(log.TestingLogger()), testTxEncoder, "mini", 2, 0)
+	for _, tx := range []testTx{
+		{id: 0, address: sa, priority: 30, gas: 1},
+		{id: 1, address: sb, priority: 10, gas: 1},
+	} {
+		require.NoError(t, pool.Insert(sdk.Context{}, tx))
+	}
+
+	// the aggregate gas price of the bundle is (5 + 35) / 2 = 20
+	bundle, err := pool.InsertBundle(sdk.Context{}, []sdk.Tx{
+		testTx{id: 2, address: sc, priority: 5, gas: 1},
+		testTx{id: 3, address: sd, priority: 35, gas: 1},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "20.000000000000000000", bundle.GasPrice.String())
+	require.Equal(t, 4, pool.CountTx())
+
+	// a tx cannot be part of two bundles
+	_, err = pool.InsertBundle(sdk.Context{}, []sdk.Tx{testTx{id: 2, address: sc, priority: 5, gas: 1}})
+	require.Error(t, err)
+
+	// the bundle is selected contiguously and in order, between the txs of higher and lower gas price
+	var (
+		txOrder   []int
+		bundleIDs []string
+	)
+	for it := pool.Select(sdk.Context{}, nil); it != nil; it = it.Next() {
+		txOrder = append(txOrder, it.Tx().(testTx).id)
+
+		var id string
+		if b := it.(mempool.BundleIterator).Bundle(); b != nil {
+			id = b.ID
+		}
+		bundleIDs = append(bundleIDs, id)
+	}
+	require.Equal(t, []int{0, 2, 3, 1}, txOrder)
+	require.Equal(t, []string{"", bundle.ID, bundle.ID, ""}, bundleIDs)
+
+	// removing a tx of the bundle removes the whole bundle
+	require.NoError(t, pool.Remove(testTx{id: 3, address: sd, priority: 35, gas: 1}))
+	require.Equal(t, 2, pool.CountTx())
+	require.Equal(t, 0, pool.CountBundles())
+	require.ErrorIs(t, pool.Remove(testTx{id: 2, address: sc, priority: 5, gas: 1}), sdkmempool.ErrTxNotFound)
+
+	// the number of pending bundles is bounded
+	for i := 0; i < 2; i++ {
+		_, err = pool.InsertBundle(sdk.Context{}, []sdk.Tx{testTx{id: 4 + i, address: sc, nonce: uint64(i), gas: 1}})
+		require.NoError(t, err)
+	}
+	_, err = pool.InsertBundle(sdk.Context{}, []sdk.Tx{testTx{id: 6, address: sd, gas: 1}})
+	require.ErrorIs(t, err, sdkmempool.ErrMempoolTxMaxCapacity)
+}
+
+func TestBundleServer(t *testing.T) {
+	accounts := simtypes.RandomAccounts(rand.New(rand.NewSource(0)), 2)
+	sa, sb := accounts[0].Address, accounts[1].Address
+
+	key := storetypes.NewKVStoreKey("test")
+	ctx := testutil.DefaultContext(key, storetypes.NewTransientStoreKey("transient_test"))
+
+	txs := []testTx{
+		{id: 0, address: sa, nonce: 0, gas: 1},
+		{id: 1, address: sa, nonce: 1, gas: 1},
+		{id: 2, address: sb, nonce: 0, gas: 1},
+		{id: 3, address: sb, nonce: 2, gas: 1},
+	}
+	txBytes := make([][]byte, len(txs))
+	for i, tx := range txs {
+		txBytes[i], _ = testTxEncoder(tx)
+	}
+
+	pool := mempool.NewBundleMempool(mempool.NewFeeMempool(log.TestingLogger()), testTxEncoder, "mini", 10, 0)
+	server := mempool.NewBundleServer(pool, testTxDecoder(txs), sequenceAnteHandler(key), func() (sdk.Context, error) { return ctx, nil })
+
+	// the txs of a bundle are validated in order, each on top of the previous ones
+	res, err := server.SubmitBundle(context.Background(), &types.SubmitBundleRequest{Txs: [][]byte{txBytes[0], txBytes[1], txBytes[2]}})
+	require.NoError(t, err)
+	require.NotEmpty(t, res.BundleId)
+	require.Equal(t, 3, pool.CountTx())
+
+	// a bundle with an invalid tx is rejected as a whole
+	_, err = server.SubmitBundle(context.Background(), &types.SubmitBundleRequest{Txs: [][]byte{txBytes[2], txBytes[3]}})
+	require.ErrorContains(t, err, "transaction 1")
+	require.Equal(t, 1, pool.CountBundles())
+
+	_, err = server.SubmitBundle(context.Background(), &types.SubmitBundleRequest{})
+	require.Error(t, err)
+
+	// the validation must not have modified the committed state
+	require.Nil(t, ctx.KVStore(key).Get(sa))
+}
+
+func TestBundleExpiry(t *testing.T) {
+	accounts := simtypes.RandomAccounts(rand.New(rand.NewSource(0)), 2)
+	ctx := testutil.DefaultContext(storetypes.NewKVStoreKey("test"), storetypes.NewTransientStoreKey("transient_test"))
+
+	pool := mempool.NewBundleMempool(mempool.NewFeeMempool(log.TestingLogger()), testTxEncoder, "mini", 10, 3)
+	_, err := pool.InsertBundle(ctx.WithBlockHeight(1), []sdk.Tx{testTx{id: 0, address: accounts[0].Address, gas: 1}})
+	require.NoError(t, err)
+	_, err = pool.InsertBundle(ctx.WithBlockHeight(2), []sdk.Tx{testTx{id: 1, address: accounts[1].Address, gas: 1}})
+	require.NoError(t, err)
+
+	// a bundle expires once it has been pending for the TTL
+	require.Equal(t, 0, pool.Expire(3))
+	require.Equal(t, 1, pool.Expire(4))
+	require.Equal(t, 1, pool.CountBundles())
+	require.Equal(t, int64(2), pool.Bundles()[0].Height)
+	require.Equal(t, 1, pool.Expire(5))
+	require.Equal(t, 0, pool.CountTx())
+}
+
+func TestRecheckBundles(t *testing.T) {
+	accounts := simtypes.RandomAccounts(rand.New(rand.NewSource(0)), 2)
+	sa, sb := accounts[0].Address, accounts[1].Address
+
+	key := storetypes.NewKVStoreKey("test")
+	ctx := testutil.DefaultContext(key, storetypes.NewTransientStoreKey("transient_test")).WithBlockHeight(15)
+
+	pool := mempool.NewBundleMempool(mempool.NewFeeMempool(log.TestingLogger()), testTxEncoder, "mini", 10, 10)
+	_, err := pool.InsertBundle(ctx, []sdk.Tx{testTx{id: 0, address: sa, nonce: 0, gas: 1}, testTx{id: 1, address: sa, nonce: 1, gas: 1}})
+	require.NoError(t, err)
+	_, err = pool.InsertBundle(ctx, []sdk.Tx{testTx{id: 2, address: sb, nonce: 0, gas: 1}})
+	require.NoError(t, err)
+	// this bundle expires at height 15
+	_, err = pool.InsertBundle(ctx.WithBlockHeight(5), []sdk.Tx{testTx{id: 3, address: sb, nonce: 1, gas: 1}})
+	require.NoError(t, err)
+
+	// the last block consumed the first nonce of sa, which invalidates its bundle as a whole
+	ctx.KVStore(key).Set(sa, binary.BigEndian.AppendUint64(nil, 1))
+
+	rechecker := mempool.NewRechecker(log.TestingLogger(), mempool.NewSenderIndexMempool(pool), sequenceAnteHandler(key), testTxEncoder, 0)
+	checked, removed := rechecker.RecheckBundles(ctx, pool)
+	require.Equal(t, 2, checked)
+	require.Equal(t, 2, removed)
+	require.Equal(t, 1, pool.CountBundles())
+	require.Equal(t, testTx{id: 2, address: sb, nonce: 0, gas: 1}, pool.Bundles()[0].Txs[0])
+
+	// the recheck must not have modified the committed state
+	require.Nil(t, ctx.KVStore(key).Get(sb))
+}
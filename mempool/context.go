@@ -0,0 +1,42 @@
+package mempool
+
+import (
+	"context"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Done returns whether the context is cancelled or past its deadline, so that iterators and proposal handlers
+// stop early. A context without underlying context (e.g. a zero sdk.Context) is never done.
+// The deadline is compared to the current time, as the context error is only set once its timer has fired.
+func Done(ctx context.Context) bool {
+	if sdkCtx, ok := ctx.(sdk.Context); ok {
+		ctx = sdkCtx.Context()
+	}
+
+	if ctx == nil {
+		return false
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && !time.Now().Before(deadline) {
+		return true
+	}
+
+	return ctx.Err() != nil
+}
+
+// sdkContext returns the sdk.Context of the given context, either the context itself or the one it wraps
+// (see sdk.WrapSDKContext). Contrary to sdk.UnwrapSDKContext, it does not panic on a plain context.
+func sdkContext(ctx context.Context) (sdk.Context, bool) {
+	if sdkCtx, ok := ctx.(sdk.Context); ok {
+		return sdkCtx, true
+	}
+
+	if ctx == nil {
+		return sdk.Context{}, false
+	}
+
+	sdkCtx, ok := ctx.Value(sdk.SdkContextKey).(sdk.Context)
+	return sdkCtx, ok
+}
@@ -0,0 +1,62 @@
+package mempool_test
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/cometbft/cometbft/libs/log"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	simtypes "github.com/cosmos/cosmos-sdk/types/simulation"
+	"github.com/stretchr/testify/require"
+
+	"github.com/julienrbrt/chain-minimal/mempool"
+)
+
+func TestSelectContext(t *testing.T) {
+	const txCount = 50
+
+	accounts := simtypes.RandomAccounts(rand.New(rand.NewSource(0)), txCount)
+
+	for _, mempoolType := range []string{mempool.TypeFee, mempool.TypeSenderNonce} {
+		t.Run(mempoolType, func(t *testing.T) {
+			pool, err := mempool.NewMempool(log.NewNopLogger(), mempool.Config{Type: mempoolType})
+			require.NoError(t, err)
+
+			for i, acc := range accounts {
+				require.NoError(t, pool.Insert(sdk.Context{}, testTx{id: i, address: acc.Address, priority: int64(i)}))
+			}
+
+			// a cancelled context selects nothing
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			require.Nil(t, pool.Select(ctx, nil))
+			require.Nil(t, pool.Select(sdk.Context{}.WithContext(ctx), nil))
+
+			// the iteration stops once the context is cancelled
+			ctx, cancel = context.WithCancel(context.Background())
+			defer cancel()
+
+			var selected int
+			for it := pool.Select(sdk.Context{}.WithContext(ctx), nil); it != nil; it = it.Next() {
+				if selected++; selected == 10 {
+					cancel()
+				}
+			}
+			require.Equal(t, 10, selected)
+
+			// as well as past its deadline
+			ctx, cancel = context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+			defer cancel()
+			require.Nil(t, pool.Select(ctx, nil))
+
+			// a zero context is never done
+			selected = 0
+			for it := pool.Select(sdk.Context{}, nil); it != nil; it = it.Next() {
+				selected++
+			}
+			require.Equal(t, txCount, selected)
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package mempool
+
+import (
+	"strings"
+
+	ibctransfertypes "github.com/cosmos/ibc-go/v7/modules/apps/transfer/types"
+)
+
+// ResolveDenom returns the denomination of the coins of the given denom on this chain, so that fees paid in
+// IBC vouchers can be ranked. The denom is either a native denom, an IBC voucher denom (i.e. ibc/{hash}, whose
+// hash is normalized to upper case as on chain) or the full path of an IBC voucher (e.g. transfer/channel-0/uatom),
+// whose IBC voucher denom is computed.
+func ResolveDenom(denom string) (string, error) {
+	prefix := ibctransfertypes.DenomPrefix + "/"
+	if strings.HasPrefix(denom, prefix) {
+		if err := ibctransfertypes.ValidateIBCDenom(denom); err != nil {
+			return "", err
+		}
+
+		return prefix + strings.ToUpper(strings.TrimPrefix(denom, prefix)), nil
+	}
+
+	trace := ibctransfertypes.ParseDenomTrace(denom)
+	if err := trace.Validate(); err != nil {
+		return "", err
+	}
+
+	return trace.IBCDenom(), nil
+}
+
+// isIBCDenom returns whether the denom is the denom of an IBC voucher, i.e. ibc/{hash}.
+func isIBCDenom(denom string) bool {
+	return strings.HasPrefix(denom, ibctransfertypes.DenomPrefix+"/")
+}
+
+// containsDenom returns whether the denom is one of the given denoms.
+func containsDenom(denoms []string, denom string) bool {
+	for _, d := range denoms {
+		if d == denom {
+			return true
+		}
+	}
+
+	return false
+}
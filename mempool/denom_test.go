@@ -0,0 +1,109 @@
+package mempool_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/cometbft/cometbft/libs/log"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	simtypes "github.com/cosmos/cosmos-sdk/types/simulation"
+	"github.com/stretchr/testify/require"
+
+	"github.com/julienrbrt/chain-minimal/mempool"
+)
+
+// atomDenom is the denom of the uatom vouchers received on channel-0.
+const atomDenom = "ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2"
+
+func TestResolveDenom(t *testing.T) {
+	testCases := []struct {
+		denom    string
+		expected string
+		expErr   bool
+	}{
+		{"mini", "mini", false},
+		{"transfer/channel-0/uatom", atomDenom, false},
+		{atomDenom, atomDenom, false},
+		{"ibc/27394fb092d2eccd56123c74f36e4c1f926001ceada9ca97ea622b25f41e5eb2", atomDenom, false},
+		{"ibc/xyz", "", true},
+		{"ibc/", "", true},
+		{"", "", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.denom, func(t *testing.T) {
+			denom, err := mempool.ResolveDenom(tc.denom)
+			if tc.expErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, denom)
+		})
+	}
+}
+
+// TestFeeMempoolIBCDenom checks that the fee mempool ranks the fees paid in IBC vouchers by their gas price.
+func TestFeeMempoolIBCDenom(t *testing.T) {
+	accounts := simtypes.RandomAccounts(rand.New(rand.NewSource(0)), 2)
+	sa, sb := accounts[0].Address, accounts[1].Address
+
+	pool, err := mempool.NewMempool(log.NewNopLogger(), mempool.Config{
+		Type:             mempool.TypeFee,
+		PriorityStrategy: mempool.PriorityStrategyGasPrice,
+		Denom:            "transfer/channel-0/uatom",
+	})
+	require.NoError(t, err)
+
+	// the fee paid in another denomination than the vouchers is not taken into account
+	require.NoError(t, pool.Insert(sdk.Context{}, testTx{id: 0, address: sa, priority: 1000, gas: 10}))
+	require.NoError(t, pool.Insert(sdk.Context{}, testTx{id: 1, address: sb, priority: 10, gas: 10, feeDenom: atomDenom}))
+
+	var order []int
+	for it := pool.Select(sdk.Context{}, nil); it != nil; it = it.Next() {
+		order = append(order, it.Tx().(testTx).id)
+	}
+	require.Equal(t, []int{1, 0}, order)
+
+	_, err = mempool.NewMempool(log.NewNopLogger(), mempool.Config{Type: mempool.TypeFee, Denom: "ibc/xyz"})
+	require.Error(t, err)
+}
+
+// TestMinCoinPriorityIBCDenom checks that the IBC vouchers are not ranked by their raw amount, unless configured.
+func TestMinCoinPriorityIBCDenom(t *testing.T) {
+	accounts := simtypes.RandomAccounts(rand.New(rand.NewSource(0)), 2)
+	sa, sb := accounts[0].Address, accounts[1].Address
+
+	pool, err := mempool.NewMempool(log.NewNopLogger(), mempool.Config{Type: mempool.TypeFee, Denom: "mini"})
+	require.NoError(t, err)
+
+	// the large amount of vouchers does not outrank the native fee
+	require.NoError(t, pool.Insert(sdk.Context{}, testTx{id: 0, address: sa, priority: 1000, feeDenom: atomDenom}))
+	require.NoError(t, pool.Insert(sdk.Context{}, testTx{id: 1, address: sb, priority: 10}))
+
+	var order []int
+	for it := pool.Select(sdk.Context{}, nil); it != nil; it = it.Next() {
+		order = append(order, it.Tx().(testTx).id)
+	}
+	require.Equal(t, []int{1, 0}, order)
+
+	tx := testTx{address: sa, priority: 1000, feeDenom: atomDenom}
+	for _, fn := range []mempool.PriorityFunc{mempool.MinCoinPriority(), mempool.FeePriority(nil)} {
+		priority, err := fn(sdk.Context{}, tx)
+		require.NoError(t, err)
+		require.Zero(t, priority)
+	}
+
+	// the vouchers of the configured denom are ranked
+	fn, err := mempool.NewPriorityFunc(mempool.PriorityStrategyMinCoin, nil, atomDenom)
+	require.NoError(t, err)
+	priority, err := fn(sdk.Context{}, tx)
+	require.NoError(t, err)
+	require.Equal(t, int64(1000), priority)
+
+	// and the native coins are still ranked next to them
+	priority, err = mempool.MinCoinPriority(atomDenom)(sdk.Context{}, testTx{address: sb, priority: 10})
+	require.NoError(t, err)
+	require.Equal(t, int64(10), priority)
+}
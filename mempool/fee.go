@@ -1,22 +1,128 @@
 package mempool
 
 import (
+	"container/heap"
 	"context"
 	"fmt"
 	"math"
-	"sort"
 
 	"github.com/cometbft/cometbft/libs/log"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/types/mempool"
-	"github.com/cosmos/cosmos-sdk/x/auth/signing"
 )
 
 var _ mempool.Mempool = (*FeeMempool)(nil)
 
-func NewFeeMempool(logger log.Logger) *FeeMempool {
-	return &FeeMempool{logger: logger.With("module", "fee-mempool")}
+func NewFeeMempool(logger log.Logger, opts ...FeeMempoolOption) *FeeMempool {
+	fm := &FeeMempool{
+		logger:   logger.With("module", "fee-mempool"),
+		payerTxs: map[string]int{},
+	}
+
+	for _, opt := range opts {
+		opt(fm)
+	}
+
+	if fm.priorityFunc == nil {
+		fm.priorityFunc = FeePriority(fm.baseFeeKeeper)
+	}
+
+	return fm
+}
+
+type FeeMempoolOption func(*FeeMempool)
+
+// BaseFeeKeeper defines the expected keeper returning the current base fee, per unit of gas.
+type BaseFeeKeeper interface {
+	GetBaseFee(ctx sdk.Context) sdk.DecCoin
+}
+
+// FeeMempoolBaseFeeOpt Option to rank transactions by the tip they pay above the base fee
+// (i.e. the fee minus the base fee times the gas limit), instead of by their whole fee.
+//
+// Example:
+//
+//	NewFeeMempool(logger, FeeMempoolBaseFeeOpt(app.BaseFeeKeeper))
+func FeeMempoolBaseFeeOpt(keeper BaseFeeKeeper) FeeMempoolOption {
+	return func(fm *FeeMempool) {
+		fm.baseFeeKeeper = keeper
+	}
+}
+
+// FeeMempoolPriorityFuncOpt Option to set how the priority of a transaction is computed.
+// It defaults to FeePriority, with the base fee keeper of FeeMempoolBaseFeeOpt if any.
+//
+// Example:
+//
+//	NewFeeMempool(logger, FeeMempoolPriorityFuncOpt(SumPriority(GasPricePriority(keeper, "mini"), AntePriority())))
+func FeeMempoolPriorityFuncOpt(fn PriorityFunc) FeeMempoolOption {
+	return func(fm *FeeMempool) {
+		fm.priorityFunc = fn
+	}
+}
+
+// FeeMempoolAgingOpt Option to raise the priority of the transactions with the number of blocks they have been
+// pending, so that every valid transaction is eventually selected. The height of the contexts given to Insert and
+// Select is used to count the pending blocks.
+//
+// Example:
+//
+//	NewFeeMempool(logger, FeeMempoolAgingOpt(PriorityAging{Curve: CurveLinear, Rate: 10}))
+func FeeMempoolAgingOpt(aging PriorityAging) FeeMempoolOption {
+	return func(fm *FeeMempool) {
+		fm.aging = aging
+	}
+}
+
+// FeeMempoolMaxFreeTxsOpt Option to bound the number of zero-fee transactions in the mempool,
+// so that they cannot fill it. A value of 0 does not bound them.
+//
+// Example:
+//
+//	NewFeeMempool(logger, FeeMempoolMaxFreeTxsOpt(100))
+func FeeMempoolMaxFreeTxsOpt(maxFreeTxs int) FeeMempoolOption {
+	return func(fm *FeeMempool) {
+		fm.maxFreeTxs = maxFreeTxs
+	}
+}
+
+// FeeMempoolMaxTxsPerPayerOpt Option to bound the number of transactions in the mempool whose fees are paid by the
+// same account, so that a single payer cannot fill the mempool nor the blocks. The fees are attributed to the fee
+// granter of the transaction when it has one (see TxPayer), so that a sponsor is bounded over all the transactions
+// it sponsors. A value of 0 does not bound them.
+//
+// Example:
+//
+//	NewFeeMempool(logger, FeeMempoolMaxTxsPerPayerOpt(50))
+func FeeMempoolMaxTxsPerPayerOpt(maxTxsPerPayer int) FeeMempoolOption {
+	return func(fm *FeeMempool) {
+		fm.maxTxsPerPayer = maxTxsPerPayer
+	}
+}
+
+// FeeMempoolMaxBytesOpt Option to bound the total encoded size of the transactions in the mempool.
+// A value of 0 does not bound it.
+//
+// Example:
+//
+//	NewFeeMempool(logger, FeeMempoolMaxBytesOpt(64 << 20))
+func FeeMempoolMaxBytesOpt(maxBytes int64) FeeMempoolOption {
+	return func(fm *FeeMempool) {
+		fm.maxBytes = maxBytes
+	}
+}
+
+// FeeMempoolTxEncoderOpt Option to encode the transactions inserted without transaction bytes in their context
+// (i.e. outside of CheckTx), so that their size is known.
+//
+// Example:
+//
+//	NewFeeMempool(logger, FeeMempoolTxEncoderOpt(txConfig.TxEncoder()))
+func FeeMempoolTxEncoderOpt(txEncoder sdk.TxEncoder) FeeMempoolOption {
+	return func(fm *FeeMempool) {
+		fm.txEncoder = txEncoder
+	}
 }
 
 // FeeMempool defines a mempool that prioritizes transactions according to their fees.
@@ -24,21 +130,46 @@ func NewFeeMempool(logger log.Logger) *FeeMempool {
 // Once no more transactions has fees, the remainaing transactions are inserted until the mempool is full.
 // This mempool is not optimized, do not use in production.
 type FeeMempool struct {
-	logger log.Logger
-	pool   fmTxs
+	logger         log.Logger
+	txs            []fmTx
+	baseFeeKeeper  BaseFeeKeeper
+	priorityFunc   PriorityFunc
+	aging          PriorityAging
+	maxFreeTxs     int
+	freeTxs        int
+	maxTxsPerPayer int
+	// payerTxs is the number of txs per payer (see TxPayer)
+	payerTxs  map[string]int
+	txEncoder sdk.TxEncoder
+	maxBytes  int64
+	sizeBytes int64
 }
 
 type fmTx struct {
-	address  string
+	signers  []signerNonce
 	priority int64
 	tx       sdk.Tx
+	// bytes is the encoded tx, nil when unknown
+	bytes []byte
+	// height is the block height at which the tx was inserted
+	height int64
+	// agedPriority is the priority of the tx, aged at the height of the last selection
+	agedPriority int64
+	// antePriority is the priority set by the ante handler in the context given to Insert, kept to reprioritize the tx
+	antePriority int64
 }
 
 func (fm fmTx) Equal(other fmTx) bool {
-	if fm.address != other.address || fm.priority != other.priority {
+	if len(fm.signers) != len(other.signers) {
 		return false
 	}
 
+	for i, signer := range fm.signers {
+		if signer != other.signers[i] {
+			return false
+		}
+	}
+
 	if len(fm.tx.GetMsgs()) != len(other.tx.GetMsgs()) {
 		return false
 	}
@@ -52,109 +183,224 @@ func (fm fmTx) Equal(other fmTx) bool {
 	return true
 }
 
-var _ mempool.Iterator = &fmTxs{}
+// fmTxHeap is a heap of the indexes of txs, the one with the highest aged priority first, then the oldest
+// one and the first inserted one.
+type fmTxHeap struct {
+	txs   []fmTx
+	order []int
+}
+
+var _ heap.Interface = &fmTxHeap{}
+
+func (h *fmTxHeap) Len() int { return len(h.order) }
+
+func (h *fmTxHeap) Less(i, j int) bool {
+	a, b := h.txs[h.order[i]], h.txs[h.order[j]]
+	if a.agedPriority != b.agedPriority {
+		return b.agedPriority < a.agedPriority
+	}
+
+	if a.height != b.height {
+		return a.height < b.height
+	}
 
+	return h.order[i] < h.order[j]
+}
+
+func (h *fmTxHeap) Swap(i, j int) { h.order[i], h.order[j] = h.order[j], h.order[i] }
+
+func (h *fmTxHeap) Push(x any) { h.order = append(h.order, x.(int)) }
+
+func (h *fmTxHeap) Pop() any {
+	last := h.order[len(h.order)-1]
+	h.order = h.order[:len(h.order)-1]
+	return last
+}
+
+var _ SizedIterator = &fmTxs{}
+
+// fmTxs iterates over the txs of the mempool, ranking them as they are iterated.
 type fmTxs struct {
+	ctx  context.Context
+	heap fmTxHeap
+	// idx is the index of the current tx
 	idx int
-	txs []fmTx
 }
 
-// Next returns an interator with one less tx in the pool
+// Next returns an interator with one less tx in the pool, or nil once the context of the selection is done
 func (fm *fmTxs) Next() mempool.Iterator {
-	if len(fm.txs) == 0 {
+	if fm.heap.Len() == 0 || Done(fm.ctx) {
 		return nil
 	}
 
-	if len(fm.txs) == fm.idx+1 {
-		return nil
-	}
-
-	fm.idx++
+	fm.idx = heap.Pop(&fm.heap).(int)
 	return fm
 }
 
+// Size returns the encoded size of the current tx, 0 when unknown.
+func (fm *fmTxs) Size() int64 {
+	return int64(len(fm.heap.txs[fm.idx].bytes))
+}
+
+// TxBytes returns the encoded current tx, nil when unknown.
+func (fm *fmTxs) TxBytes() []byte {
+	return fm.heap.txs[fm.idx].bytes
+}
+
 func (fm *fmTxs) Tx() sdk.Tx {
-	if fm.idx >= len(fm.txs) {
+	if fm.idx >= len(fm.heap.txs) {
 		panic(fmt.Sprintf("index out of bound: %d, fmTxs: %v", fm.idx, fm))
 	}
 
-	return fm.txs[fm.idx].tx
+	return fm.heap.txs[fm.idx].tx
 }
 
-// Insert a transaction in the mempool per sender
-func (fm *FeeMempool) Insert(_ context.Context, tx sdk.Tx) error {
-	sigs, err := tx.(signing.SigVerifiableTx).GetSignaturesV2()
+// Insert a transaction in the mempool, indexed by all its signers and their sequence
+func (fm *FeeMempool) Insert(ctx context.Context, tx sdk.Tx) error {
+	signers, err := txSigners(tx)
 	if err != nil {
 		return err
 	}
-	if len(sigs) == 0 {
-		return fmt.Errorf("tx must have at least one signer")
+
+	priority, err := fm.priorityFunc(ctx, tx)
+	if err != nil {
+		return err
 	}
 
-	sig := sigs[0]
-	sender := sdk.AccAddress(sig.PubKey.Address()).String()
+	isFree := IsZeroFee(tx)
+	if isFree && fm.maxFreeTxs > 0 && fm.freeTxs >= fm.maxFreeTxs {
+		return mempool.ErrMempoolTxMaxCapacity
+	}
 
-	// by default a transaction has no priority
-	// note, we could have got the prority from the context as well
-	// however, we wanted to demonstrate that any custom logic can be used to determine the priority of a transaction
-	// sdkContext := sdk.UnwrapSDKContext(ctx)
-	// priority := sdkContext.Priority()
-	var priority int64
-	if feeTx, ok := tx.(sdk.FeeTx); ok {
-		priority = naiveGetTxPriority(feeTx.GetFee())
+	// the txs which are not a sdk.FeeTx have no payer, and are not counted
+	payer := TxPayer(tx)
+	if fm.maxTxsPerPayer > 0 && payer != nil && fm.payerTxs[payer.String()] >= fm.maxTxsPerPayer {
+		return mempool.ErrMempoolTxMaxCapacity
+	}
+
+	bz, err := txBytes(ctx, tx, fm.txEncoder)
+	if err != nil {
+		return err
+	}
+
+	size := int64(len(bz))
+	if fm.maxBytes > 0 && fm.sizeBytes+size > fm.maxBytes {
+		return mempool.ErrMempoolTxMaxCapacity
+	}
+
+	var height, antePriority int64
+	if sdkCtx, ok := ctx.(sdk.Context); ok {
+		height, antePriority = sdkCtx.BlockHeight(), sdkCtx.Priority()
 	}
 
-	fm.logger.Info(fmt.Sprintf("transaction from %s inserted in mempool with priority %d", sender, priority))
-	fm.pool.txs = append(fm.pool.txs, fmTx{
-		address:  sender,
-		priority: priority,
-		tx:       tx,
+	fm.logger.Info(fmt.Sprintf("transaction from %s inserted in mempool with priority %d", signers[0].address, priority))
+	fm.txs = append(fm.txs, fmTx{
+		signers:      signers,
+		priority:     priority,
+		tx:           tx,
+		bytes:        bz,
+		height:       height,
+		antePriority: antePriority,
 	})
+	fm.sizeBytes += size
+	if isFree {
+		fm.freeTxs++
+	}
+	if payer != nil {
+		fm.payerTxs[payer.String()]++
+	}
 
 	return nil
 }
 
 // Select returns an iterator ordering transactions the mempool with the highest fee.
+// When aging is enabled, the priorities are aged up to the height of the context, and the oldest
+// transaction comes first among transactions of the same aged priority.
+// The iteration stops once the context is cancelled or past its deadline, and so does the ranking of the transactions.
 // NOTE: It is not safe to use this iterator while removing transactions from the underlying mempool.
-func (fm *FeeMempool) Select(_ context.Context, _ [][]byte) mempool.Iterator {
-	if len(fm.pool.txs) == 0 {
+func (fm *FeeMempool) Select(ctx context.Context, _ [][]byte) mempool.Iterator {
+	if len(fm.txs) == 0 || Done(ctx) {
 		return nil
 	}
 
-	// sort all txs after each insertion (truly not efficient, but you get it)
-	sort.Slice(fm.pool.txs, func(i, j int) bool {
-		return fm.pool.txs[j].priority < fm.pool.txs[i].priority
-	})
+	var height int64
+	if sdkCtx, ok := ctx.(sdk.Context); ok {
+		height = sdkCtx.BlockHeight()
+	}
+
+	order := make([]int, len(fm.txs))
+	for i, tx := range fm.txs {
+		fm.txs[i].agedPriority = fm.aging.Apply(tx.priority, tx.height, height)
+		order[i] = i
+	}
+
+	// each selection gets its own iterator, starting from the first tx. The txs are ranked as they are iterated,
+	// so that the ranking work stops with the iteration: building the heap is linear, and each iteration logarithmic.
+	it := &fmTxs{ctx: ctx, heap: fmTxHeap{txs: fm.txs, order: order}}
+	heap.Init(&it.heap)
+	it.idx = heap.Pop(&it.heap).(int)
+
+	return it
+}
+
+// Reprioritize recomputes the priority of the transactions of the mempool with the given context, e.g. after the
+// priority function weights are reloaded. The priority set by the ante handler at insertion is kept in the context.
+// A transaction whose priority fails to be computed keeps its previous priority. It returns the number of changed priorities.
+func (fm *FeeMempool) Reprioritize(ctx context.Context) (changed int) {
+	for i, tx := range fm.txs {
+		txCtx := ctx
+		if sdkCtx, ok := ctx.(sdk.Context); ok {
+			txCtx = sdkCtx.WithPriority(tx.antePriority)
+		}
+
+		priority, err := fm.priorityFunc(txCtx, tx.tx)
+		if err != nil {
+			fm.logger.Error("failed to reprioritize transaction", "signer", tx.signers[0].address, "err", err)
+			continue
+		}
 
-	return &fm.pool
+		if priority != tx.priority {
+			fm.txs[i].priority = priority
+			changed++
+		}
+	}
+
+	return changed
 }
 
 // CountTx returns the total amount of transactions in the mempool
 func (fm *FeeMempool) CountTx() int {
-	return len(fm.pool.txs)
+	return len(fm.txs)
+}
+
+// SizeBytes returns the total encoded size of the transactions in the mempool.
+func (fm *FeeMempool) SizeBytes() int64 {
+	return fm.sizeBytes
 }
 
 // Remove removes a tx from the mempool. It returns an error if the tx does not have at least one signer or the tx was not found in the pool.
+// A tx is identified by all its signers and their sequence.
 func (fm *FeeMempool) Remove(tx sdk.Tx) error {
-	sigs, err := tx.(signing.SigVerifiableTx).GetSignaturesV2()
+	signers, err := txSigners(tx)
 	if err != nil {
 		return err
 	}
-	if len(sigs) == 0 {
-		return fmt.Errorf("tx must have at least one signer")
-	}
-
-	sig := sigs[0]
-	sender := sdk.AccAddress(sig.PubKey.Address()).String()
-	var priority int64
-	if feeTx, ok := tx.(sdk.FeeTx); ok {
-		priority = naiveGetTxPriority(feeTx.GetFee())
-	}
 
-	txToDelete := fmTx{priority: priority, address: sender, tx: tx}
-	for idx, fmTx := range fm.pool.txs {
+	txToDelete := fmTx{signers: signers, tx: tx}
+	for idx, fmTx := range fm.txs {
 		if fmTx.Equal(txToDelete) {
-			fm.pool.txs = removeAtIndex(fm.pool.txs, idx)
+			if IsZeroFee(fmTx.tx) {
+				fm.freeTxs--
+			}
+			fm.sizeBytes -= int64(len(fmTx.bytes))
+
+			if payer := TxPayer(fmTx.tx); payer != nil {
+				if fm.payerTxs[payer.String()]--; fm.payerTxs[payer.String()] <= 0 {
+					delete(fm.payerTxs, payer.String())
+				}
+			}
+
+			fm.txs = removeAtIndex(fm.txs, idx)
 			return nil
 		}
 	}
@@ -162,11 +408,36 @@ func (fm *FeeMempool) Remove(tx sdk.Tx) error {
 	return mempool.ErrTxNotFound
 }
 
+// IsZeroFee returns whether the transaction pays no fee.
+func IsZeroFee(tx sdk.Tx) bool {
+	feeTx, ok := tx.(sdk.FeeTx)
+	return ok && feeTx.GetFee().IsZero()
+}
+
+// TxPayer returns the account paying the fees of the transaction: its fee granter when it has one,
+// its fee payer otherwise. It returns nil when the transaction is not a sdk.FeeTx.
+func TxPayer(tx sdk.Tx) sdk.AccAddress {
+	feeTx, ok := tx.(sdk.FeeTx)
+	if !ok {
+		return nil
+	}
+
+	if granter := feeTx.FeeGranter(); !granter.Empty() {
+		return granter
+	}
+
+	return feeTx.FeePayer()
+}
+
 // naiveGetTxPriority returns a naive tx priority based on the amount of the smallest denomination of the fee
-// provided in a transaction.
-func naiveGetTxPriority(fee sdk.Coins) int64 {
+// provided in a transaction. The IBC vouchers are ignored, except the given ones.
+func naiveGetTxPriority(fee sdk.Coins, vouchers []string) int64 {
 	var priority int64
 	for _, c := range fee {
+		if isIBCDenom(c.Denom) && !containsDenom(vouchers, c.Denom) {
+			continue
+		}
+
 		p := int64(math.MaxInt64)
 		if c.Amount.IsInt64() {
 			p = c.Amount.Int64()
@@ -178,3 +449,20 @@ func naiveGetTxPriority(fee sdk.Coins) int64 {
 
 	return priority
 }
+
+// GetTxTip returns the amount of fee paid above the base fee for the given gas limit.
+// Fees paid in another denomination than the base fee are not taken into account.
+func GetTxTip(fee sdk.Coins, gas uint64, baseFee sdk.DecCoin) int64 {
+	required := baseFee.Amount.MulInt(sdk.NewIntFromUint64(gas)).Ceil().RoundInt()
+
+	tip := fee.AmountOf(baseFee.Denom).Sub(required)
+	if !tip.IsPositive() {
+		return 0
+	}
+
+	if !tip.IsInt64() {
+		return math.MaxInt64
+	}
+
+	return tip.Int64()
+}
@@ -7,8 +7,11 @@ import (
 	"testing"
 
 	"github.com/cometbft/cometbft/libs/log"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkmempool "github.com/cosmos/cosmos-sdk/types/mempool"
 	simtypes "github.com/cosmos/cosmos-sdk/types/simulation"
+	txsigning "github.com/cosmos/cosmos-sdk/types/tx/signing"
 	"github.com/stretchr/testify/require"
 
 	"github.com/julienrbrt/chain-minimal/mempool"
@@ -74,3 +77,136 @@ func TestTxOrder(t *testing.T) {
 		})
 	}
 }
+
+type testBaseFeeKeeper struct {
+	baseFee sdk.DecCoin
+}
+
+func (k testBaseFeeKeeper) GetBaseFee(sdk.Context) sdk.DecCoin {
+	return k.baseFee
+}
+
+func TestTxOrderBaseFee(t *testing.T) {
+	accounts := simtypes.RandomAccounts(rand.New(rand.NewSource(0)), 3)
+	sa := accounts[0].Address
+	sb := accounts[1].Address
+	sc := accounts[2].Address
+
+	keeper := testBaseFeeKeeper{baseFee: sdk.NewDecCoin("mini", sdk.NewInt(1))}
+	pool := mempool.NewFeeMempool(log.TestingLogger(), mempool.FeeMempoolBaseFeeOpt(keeper))
+
+	// txs are ranked by the fee they pay above the base fee for their gas, not by their fee
+	txs := []testTx{
+		{id: 0, address: sa, priority: 100, gas: 90}, // tip: 10
+		{id: 1, address: sb, priority: 50, gas: 10},  // tip: 40
+		{id: 2, address: sc, priority: 20, gas: 30},  // no tip
+	}
+	for _, tx := range txs {
+		require.NoError(t, pool.Insert(sdk.Context{}, tx))
+	}
+
+	var txOrder []int
+	for itr := pool.Select(context.Background(), nil); itr != nil; itr = itr.Next() {
+		txOrder = append(txOrder, itr.Tx().(testTx).id)
+	}
+	require.Equal(t, []int{1, 0, 2}, txOrder)
+}
+
+func TestTxOrderAntePriority(t *testing.T) {
+	accounts := simtypes.RandomAccounts(rand.New(rand.NewSource(0)), 3)
+
+	pool := mempool.NewFeeMempool(log.TestingLogger(), mempool.FeeMempoolPriorityFuncOpt(mempool.AntePriority()))
+
+	// txs are ranked by the priority set by the ante handler, not by their fee
+	antePriorities := []int64{20, 30, 10}
+	for i, acc := range accounts {
+		ctx := sdk.Context{}.WithPriority(antePriorities[i])
+		require.NoError(t, pool.Insert(ctx, testTx{id: i, address: acc.Address, priority: int64(100 - i)}))
+	}
+
+	var txOrder []int
+	for itr := pool.Select(context.Background(), nil); itr != nil; itr = itr.Next() {
+		txOrder = append(txOrder, itr.Tx().(testTx).id)
+	}
+	require.Equal(t, []int{1, 0, 2}, txOrder)
+}
+
+func TestFeeMempoolMultiSigner(t *testing.T) {
+	accounts := simtypes.RandomAccounts(rand.New(rand.NewSource(0)), 2)
+	sa := accounts[0].Address
+	sb := accounts[1].Address
+
+	pool := mempool.NewFeeMempool(log.TestingLogger())
+	txs := []testTx{
+		{id: 0, address: sa, nonce: 0, priority: 10, cosigners: []cosigner{{address: sb, nonce: 0}}},
+		{id: 1, address: sa, nonce: 0, priority: 20, cosigners: []cosigner{{address: sb, nonce: 1}}},
+	}
+	for _, tx := range txs {
+		require.NoError(t, pool.Insert(sdk.Context{}, tx))
+	}
+
+	// txs are identified by all their signers, not only by the first one
+	require.NoError(t, pool.Remove(testTx{address: sa, nonce: 0, cosigners: []cosigner{{address: sb, nonce: 1}}}))
+	require.Equal(t, 1, pool.CountTx())
+	require.Equal(t, 0, pool.Select(sdk.Context{}, nil).Tx().(testTx).id)
+
+	require.ErrorIs(t, pool.Remove(testTx{address: sa, nonce: 0}), sdkmempool.ErrTxNotFound)
+}
+
+func TestFeeMempoolMaxFreeTxs(t *testing.T) {
+	accounts := simtypes.RandomAccounts(rand.New(rand.NewSource(0)), 3)
+	sa, sb, sc := accounts[0].Address, accounts[1].Address, accounts[2].Address
+
+	pool := mempool.NewFeeMempool(log.TestingLogger(), mempool.FeeMempoolMaxFreeTxsOpt(1))
+	free := testTx{id: 0, address: sa}
+	require.NoError(t, pool.Insert(context.Background(), free))
+
+	// the free txs are bounded, not the paid ones
+	require.ErrorIs(t, pool.Insert(context.Background(), testTx{id: 1, address: sb}), sdkmempool.ErrMempoolTxMaxCapacity)
+	require.NoError(t, pool.Insert(context.Background(), testTx{id: 2, address: sc, priority: 10}))
+
+	require.NoError(t, pool.Remove(free))
+	require.NoError(t, pool.Insert(context.Background(), testTx{id: 1, address: sb}))
+	require.Equal(t, 2, pool.CountTx())
+}
+
+func TestFeeMempoolMaxTxsPerPayer(t *testing.T) {
+	accounts := simtypes.RandomAccounts(rand.New(rand.NewSource(0)), 4)
+	sponsor, sa, sb, sc := accounts[0].Address, accounts[1].Address, accounts[2].Address, accounts[3].Address
+
+	pool := mempool.NewFeeMempool(log.TestingLogger(), mempool.FeeMempoolMaxTxsPerPayerOpt(2))
+	sponsored := testTx{id: 0, address: sa, granter: sponsor, priority: 10}
+	require.NoError(t, pool.Insert(context.Background(), sponsored))
+	require.NoError(t, pool.Insert(context.Background(), testTx{id: 1, address: sb, granter: sponsor, priority: 10}))
+
+	// the fees of the sponsored txs are attributed to the sponsor, not to their signers
+	require.ErrorIs(t, pool.Insert(context.Background(), testTx{id: 2, address: sc, granter: sponsor, priority: 100}), sdkmempool.ErrMempoolTxMaxCapacity)
+	require.NoError(t, pool.Insert(context.Background(), testTx{id: 3, address: sa, nonce: 1, priority: 10}))
+	require.NoError(t, pool.Insert(context.Background(), testTx{id: 4, address: sa, nonce: 2, priority: 10}))
+	require.ErrorIs(t, pool.Insert(context.Background(), testTx{id: 5, address: sa, nonce: 3, priority: 10}), sdkmempool.ErrMempoolTxMaxCapacity)
+
+	require.NoError(t, pool.Remove(sponsored))
+	require.NoError(t, pool.Insert(context.Background(), testTx{id: 2, address: sc, granter: sponsor, priority: 100}))
+	require.Equal(t, 4, pool.CountTx())
+
+	// the txs without fee have no payer, so they are not bounded together
+	for nonce := uint64(0); nonce < 3; nonce++ {
+		require.NoError(t, pool.Insert(context.Background(), noFeeTx{testTx{id: 6, address: sponsor, nonce: nonce}}))
+	}
+	require.Equal(t, 7, pool.CountTx())
+}
+
+// noFeeTx is a signed tx which is not a sdk.FeeTx.
+type noFeeTx struct {
+	tx testTx
+}
+
+func (tx noFeeTx) GetSigners() []sdk.AccAddress { return tx.tx.GetSigners() }
+
+func (tx noFeeTx) GetPubKeys() ([]cryptotypes.PubKey, error) { return tx.tx.GetPubKeys() }
+
+func (tx noFeeTx) GetSignaturesV2() ([]txsigning.SignatureV2, error) { return tx.tx.GetSignaturesV2() }
+
+func (tx noFeeTx) GetMsgs() []sdk.Msg { return tx.tx.GetMsgs() }
+
+func (tx noFeeTx) ValidateBasic() error { return tx.tx.ValidateBasic() }
@@ -0,0 +1,495 @@
+package mempool
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/cometbft/cometbft/libs/log"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/mempool"
+)
+
+// JournalOp is a mempool operation recorded in a journal.
+type JournalOp byte
+
+// Journal operations.
+const (
+	JournalOpInsert JournalOp = iota + 1
+	JournalOpRemove
+	JournalOpSelect
+	// JournalOpSession starts a new session of the journal, written when it is opened: the ids of the
+	// previous sessions are not valid anymore and their transactions are not in the mempool.
+	JournalOpSession
+)
+
+// String implements fmt.Stringer.
+func (op JournalOp) String() string {
+	switch op {
+	case JournalOpInsert:
+		return "insert"
+	case JournalOpRemove:
+		return "remove"
+	case JournalOpSelect:
+		return "select"
+	case JournalOpSession:
+		return "session"
+	default:
+		return fmt.Sprintf("unknown(%d)", byte(op))
+	}
+}
+
+// JournalEntry is a mempool operation recorded in a journal.
+// Transactions are identified by an id, their bytes are only written the first time they are seen.
+type JournalEntry struct {
+	Op       JournalOp
+	Height   int64
+	Priority int64
+	ID       uint64
+	Tx       []byte
+	Order    []uint64
+	Err      string
+}
+
+// appendJournalEntry appends the binary encoding of the entry to buf: the operation byte followed by
+// the varint encoded fields, the bytes, ids and error being prefixed by their length.
+func appendJournalEntry(buf []byte, entry JournalEntry) []byte {
+	buf = append(buf, byte(entry.Op))
+	buf = binary.AppendVarint(buf, entry.Height)
+	buf = binary.AppendVarint(buf, entry.Priority)
+	buf = binary.AppendUvarint(buf, entry.ID)
+	buf = binary.AppendUvarint(buf, uint64(len(entry.Tx)))
+	buf = append(buf, entry.Tx...)
+	buf = binary.AppendUvarint(buf, uint64(len(entry.Order)))
+	for _, id := range entry.Order {
+		buf = binary.AppendUvarint(buf, id)
+	}
+	buf = binary.AppendUvarint(buf, uint64(len(entry.Err)))
+
+	return append(buf, entry.Err...)
+}
+
+// readJournalEntry reads an entry encoded by appendJournalEntry. It returns io.EOF when there is no entry left.
+func readJournalEntry(r *bufio.Reader) (JournalEntry, error) {
+	var entry JournalEntry
+
+	op, err := r.ReadByte()
+	if err != nil {
+		return entry, err
+	}
+	entry.Op = JournalOp(op)
+
+	if entry.Height, err = binary.ReadVarint(r); err != nil {
+		return entry, unexpectedEOF(err)
+	}
+	if entry.Priority, err = binary.ReadVarint(r); err != nil {
+		return entry, unexpectedEOF(err)
+	}
+	if entry.ID, err = binary.ReadUvarint(r); err != nil {
+		return entry, unexpectedEOF(err)
+	}
+	if entry.Tx, err = readJournalBytes(r); err != nil {
+		return entry, err
+	}
+
+	count, err := binary.ReadUvarint(r)
+	if err != nil {
+		return entry, unexpectedEOF(err)
+	}
+	for i := uint64(0); i < count; i++ {
+		id, err := binary.ReadUvarint(r)
+		if err != nil {
+			return entry, unexpectedEOF(err)
+		}
+
+		entry.Order = append(entry.Order, id)
+	}
+
+	errBz, err := readJournalBytes(r)
+	if err != nil {
+		return entry, err
+	}
+	entry.Err = string(errBz)
+
+	return entry, nil
+}
+
+// readJournalBytes reads length-prefixed bytes, nil when empty.
+func readJournalBytes(r *bufio.Reader) ([]byte, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	if n == 0 {
+		return nil, nil
+	}
+
+	bz := make([]byte, n)
+	if _, err := io.ReadFull(r, bz); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+
+	return bz, nil
+}
+
+// unexpectedEOF reports a journal ending in the middle of an entry.
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+
+	return err
+}
+
+var _ mempool.Mempool = (*JournalMempool)(nil)
+
+// JournalMempool wraps a mempool and records every Insert, Remove and Select in a journal, in a compact
+// binary encoding. The journal can be replayed against any mempool with ReplayJournal.
+//
+// A Select records the transactions its iterator moves past, so that the selection stays as lazy as the wrapped
+// mempool (e.g. a proposal stopping at the time budget). It is written when its iteration ends, or at the next
+// journal operation otherwise.
+type JournalMempool struct {
+	mempool.Mempool
+
+	logger    log.Logger
+	txEncoder sdk.TxEncoder
+
+	mu     sync.Mutex
+	out    io.Writer
+	w      *bufio.Writer
+	ids    map[[sha256.Size]byte]uint64
+	nextID uint64
+
+	// selecting is the selection being recorded, nil when it is written
+	selecting *JournalEntry
+}
+
+// NewJournalMempool creates a new JournalMempool writing the operations of the given mempool to w.
+// It starts by writing a session entry, as the ids of a journal appended to are only valid within a session.
+func NewJournalMempool(logger log.Logger, mp mempool.Mempool, w io.Writer, txEncoder sdk.TxEncoder) *JournalMempool {
+	jm := &JournalMempool{
+		Mempool:   mp,
+		logger:    logger.With("module", "mempool-journal"),
+		txEncoder: txEncoder,
+		out:       w,
+		w:         bufio.NewWriter(w),
+		ids:       make(map[[sha256.Size]byte]uint64),
+		nextID:    1,
+	}
+
+	jm.write(JournalEntry{Op: JournalOpSession})
+
+	return jm
+}
+
+// Insert inserts the tx in the wrapped mempool and records it, with the priority and height of the context.
+func (jm *JournalMempool) Insert(ctx context.Context, tx sdk.Tx) error {
+	err := jm.Mempool.Insert(ctx, tx)
+
+	entry := JournalEntry{Op: JournalOpInsert}
+	if sdkCtx, ok := ctx.(sdk.Context); ok {
+		entry.Height = sdkCtx.BlockHeight()
+		entry.Priority = sdkCtx.Priority()
+	}
+	if err != nil {
+		entry.Err = err.Error()
+	}
+
+	jm.record(entry, tx)
+
+	// a rejected tx is not in the mempool, so it is not kept in the ids
+	if err != nil {
+		jm.mu.Lock()
+		jm.forget(tx)
+		jm.mu.Unlock()
+	}
+
+	return err
+}
+
+// Remove removes the tx from the wrapped mempool and records it.
+func (jm *JournalMempool) Remove(tx sdk.Tx) error {
+	err := jm.Mempool.Remove(tx)
+
+	entry := JournalEntry{Op: JournalOpRemove}
+	if err != nil {
+		entry.Err = err.Error()
+	}
+
+	jm.record(entry, tx)
+
+	// the tx is not in the mempool anymore, so it is written again if it is inserted back
+	jm.mu.Lock()
+	jm.forget(tx)
+	jm.mu.Unlock()
+
+	return err
+}
+
+// Select records the order of the transactions returned by the wrapped mempool, as its iterator moves past them.
+func (jm *JournalMempool) Select(ctx context.Context, txs [][]byte) mempool.Iterator {
+	entry := &JournalEntry{Op: JournalOpSelect}
+	if sdkCtx, ok := ctx.(sdk.Context); ok {
+		entry.Height = sdkCtx.BlockHeight()
+	}
+
+	jm.mu.Lock()
+	jm.writeSelect()
+	jm.selecting = entry
+	jm.mu.Unlock()
+
+	return jm.newJournalIterator(ctx, entry, jm.Mempool.Select(ctx, txs))
+}
+
+// SizeBytes returns the encoded size of the transactions of the wrapped mempool, 0 when it does not keep track of it.
+func (jm *JournalMempool) SizeBytes() int64 {
+	return sizeBytes(jm.Mempool)
+}
+
+// Flush writes the buffered journal entries, including the selection being recorded, to the underlying writer.
+func (jm *JournalMempool) Flush() error {
+	jm.mu.Lock()
+	defer jm.mu.Unlock()
+
+	jm.writeSelect()
+	return jm.w.Flush()
+}
+
+// Close flushes the journal and closes the underlying writer when it is an io.Closer.
+func (jm *JournalMempool) Close() error {
+	if err := jm.Flush(); err != nil {
+		return err
+	}
+
+	if closer, ok := jm.out.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
+
+// record writes an entry about the given tx, with the tx bytes when the tx was not seen before.
+func (jm *JournalMempool) record(entry JournalEntry, tx sdk.Tx) {
+	jm.mu.Lock()
+	defer jm.mu.Unlock()
+
+	jm.writeSelect()
+
+	id, bz, err := jm.txID(tx)
+	if err != nil {
+		jm.logger.Error("failed to record transaction", "op", entry.Op, "err", err)
+		return
+	}
+
+	entry.ID = id
+	entry.Tx = bz
+	jm.write(entry)
+}
+
+// txID returns the journal id of the tx, and its bytes when the tx was not seen before.
+func (jm *JournalMempool) txID(tx sdk.Tx) (uint64, []byte, error) {
+	bz, err := jm.txEncoder(tx)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	hash := sha256.Sum256(bz)
+	if id, ok := jm.ids[hash]; ok {
+		return id, nil, nil
+	}
+
+	id := jm.nextID
+	jm.nextID++
+	jm.ids[hash] = id
+
+	return id, bz, nil
+}
+
+// forget drops the journal id of the tx.
+func (jm *JournalMempool) forget(tx sdk.Tx) {
+	bz, err := jm.txEncoder(tx)
+	if err != nil {
+		return
+	}
+
+	delete(jm.ids, sha256.Sum256(bz))
+}
+
+// writeSelect writes the selection being recorded, if any.
+func (jm *JournalMempool) writeSelect() {
+	if jm.selecting == nil {
+		return
+	}
+
+	jm.write(*jm.selecting)
+	jm.selecting = nil
+}
+
+func (jm *JournalMempool) write(entry JournalEntry) {
+	if _, err := jm.w.Write(appendJournalEntry(nil, entry)); err != nil {
+		jm.logger.Error("failed to write journal entry", "err", err)
+		return
+	}
+
+	// flush on selections and sessions, so that the journal is complete up to the last proposal
+	if entry.Op == JournalOpSelect || entry.Op == JournalOpSession {
+		if err := jm.w.Flush(); err != nil {
+			jm.logger.Error("failed to flush journal", "err", err)
+		}
+	}
+}
+
+// ReplayedSelect is a selection replayed from a journal.
+type ReplayedSelect struct {
+	Height   int64
+	Recorded []uint64
+	Replayed []uint64
+}
+
+// Equal returns whether the replayed order is the recorded one.
+func (s ReplayedSelect) Equal() bool {
+	if len(s.Recorded) != len(s.Replayed) {
+		return false
+	}
+
+	for i, id := range s.Recorded {
+		if s.Replayed[i] != id {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ReplayJournal replays the operations of a journal against the given mempool and returns the selections
+// it produces, next to the recorded ones. Transactions are inserted with the recorded priority and height.
+// Insert and Remove errors of the replayed mempool are ignored, as they can legitimately differ from the recorded ones.
+// A recorded selection only holds the transactions its caller iterated past, so the replayed one is cut to its length.
+// At the start of a session (i.e. a node restart), the transactions of the previous one are removed from the mempool.
+func ReplayJournal(r io.Reader, mp mempool.Mempool, txDecoder sdk.TxDecoder, txEncoder sdk.TxEncoder) ([]ReplayedSelect, error) {
+	var (
+		txs     = make(map[uint64]sdk.Tx)
+		ids     = make(map[[sha256.Size]byte]uint64)
+		selects []ReplayedSelect
+	)
+
+	br := bufio.NewReader(r)
+	for n := 1; ; n++ {
+		entry, err := readJournalEntry(br)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("entry %d: %w", n, err)
+		}
+
+		if entry.Tx != nil {
+			tx, err := txDecoder(entry.Tx)
+			if err != nil {
+				return nil, fmt.Errorf("entry %d: %w", n, err)
+			}
+
+			txs[entry.ID] = tx
+			ids[sha256.Sum256(entry.Tx)] = entry.ID
+		}
+
+		ctx := sdk.Context{}.WithContext(context.Background()).WithBlockHeight(entry.Height).WithPriority(entry.Priority)
+
+		switch entry.Op {
+		case JournalOpSession:
+			for id, tx := range txs {
+				_ = mp.Remove(tx)
+				delete(txs, id)
+			}
+			ids = make(map[[sha256.Size]byte]uint64)
+		case JournalOpInsert, JournalOpRemove:
+			tx, ok := txs[entry.ID]
+			if !ok {
+				return nil, fmt.Errorf("entry %d: unknown transaction %d", n, entry.ID)
+			}
+
+			if entry.Op == JournalOpInsert {
+				_ = mp.Insert(ctx, tx)
+			} else {
+				_ = mp.Remove(tx)
+				delete(txs, entry.ID)
+			}
+		case JournalOpSelect:
+			selected := ReplayedSelect{Height: entry.Height, Recorded: entry.Order}
+			for it := mp.Select(ctx, nil); it != nil && len(selected.Replayed) < len(entry.Order); it = it.Next() {
+				bz, err := txEncoder(it.Tx())
+				if err != nil {
+					return nil, fmt.Errorf("entry %d: %w", n, err)
+				}
+
+				selected.Replayed = append(selected.Replayed, ids[sha256.Sum256(bz)])
+			}
+
+			selects = append(selects, selected)
+		default:
+			return nil, fmt.Errorf("entry %d: unknown operation %s", n, entry.Op)
+		}
+	}
+
+	return selects, nil
+}
+
+var _ SizedIterator = (*journalIterator)(nil)
+
+// journalIterator wraps the iterator of the journaled mempool, recording the transactions it moves past
+// in the selection entry until it is written.
+type journalIterator struct {
+	jm    *JournalMempool
+	ctx   context.Context
+	entry *JournalEntry
+	inner mempool.Iterator
+}
+
+// newJournalIterator returns the iterator recording the given selection, or writes the selection
+// and returns nil when the iteration ends.
+func (jm *JournalMempool) newJournalIterator(ctx context.Context, entry *JournalEntry, inner mempool.Iterator) mempool.Iterator {
+	if inner == nil || Done(ctx) {
+		jm.mu.Lock()
+		if jm.selecting == entry {
+			jm.writeSelect()
+		}
+		jm.mu.Unlock()
+
+		return nil
+	}
+
+	return &journalIterator{jm: jm, ctx: ctx, entry: entry, inner: inner}
+}
+
+func (it *journalIterator) Next() mempool.Iterator {
+	it.jm.mu.Lock()
+	if it.jm.selecting == it.entry {
+		if id, _, err := it.jm.txID(it.inner.Tx()); err != nil {
+			it.jm.logger.Error("failed to record selected transaction", "err", err)
+		} else {
+			it.entry.Order = append(it.entry.Order, id)
+		}
+	}
+	it.jm.mu.Unlock()
+
+	return it.jm.newJournalIterator(it.ctx, it.entry, it.inner.Next())
+}
+
+func (it *journalIterator) Size() int64 {
+	return IteratorSize(it.inner)
+}
+
+func (it *journalIterator) TxBytes() []byte {
+	return IteratorTxBytes(it.inner)
+}
+
+func (it *journalIterator) Tx() sdk.Tx {
+	return it.inner.Tx()
+}
@@ -0,0 +1,259 @@
+package mempool_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/cometbft/cometbft/libs/log"
+	storetypes "github.com/cosmos/cosmos-sdk/store/types"
+	"github.com/cosmos/cosmos-sdk/testutil"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkmempool "github.com/cosmos/cosmos-sdk/types/mempool"
+	simtypes "github.com/cosmos/cosmos-sdk/types/simulation"
+	"github.com/stretchr/testify/require"
+
+	"github.com/julienrbrt/chain-minimal/mempool"
+)
+
+// testTxDecoder decodes the txs encoded by testTxEncoder.
+func testTxDecoder(txs []testTx) sdk.TxDecoder {
+	return func(bz []byte) (sdk.Tx, error) {
+		for _, tx := range txs {
+			if tx.String() == string(bz) {
+				return tx, nil
+			}
+		}
+
+		return nil, fmt.Errorf("unknown tx %s", bz)
+	}
+}
+
+// selectIDs iterates a selection of the mempool, as a proposal does, and returns the ids of the selected txs.
+func selectIDs(ctx context.Context, pool sdkmempool.Mempool) []int {
+	var order []int
+	for it := pool.Select(ctx, nil); it != nil; it = it.Next() {
+		order = append(order, it.Tx().(testTx).id)
+	}
+
+	return order
+}
+
+func TestJournalReplay(t *testing.T) {
+	accounts := simtypes.RandomAccounts(rand.New(rand.NewSource(0)), 3)
+	ctx := testutil.DefaultContext(storetypes.NewKVStoreKey("test"), storetypes.NewTransientStoreKey("transient_test"))
+
+	txs := []testTx{
+		{id: 0, address: accounts[0].Address, priority: 10},
+		{id: 1, address: accounts[1].Address, priority: 30},
+		{id: 2, address: accounts[2].Address, priority: 20},
+	}
+
+	var journal bytes.Buffer
+	pool := mempool.NewJournalMempool(log.TestingLogger(), mempool.NewFeeMempool(log.TestingLogger()), &journal, testTxEncoder)
+
+	for _, tx := range txs {
+		require.NoError(t, pool.Insert(ctx.WithBlockHeight(1), tx))
+	}
+
+	require.Equal(t, []int{1, 2, 0}, selectIDs(ctx.WithBlockHeight(1), pool))
+
+	// the block only included the highest fee tx
+	require.NoError(t, pool.Remove(txs[1]))
+	require.Equal(t, []int{2, 0}, selectIDs(ctx.WithBlockHeight(2), pool))
+	require.NoError(t, pool.Flush())
+
+	// replaying against the same mempool gives the same orderings
+	selects, err := mempool.ReplayJournal(bytes.NewReader(journal.Bytes()), mempool.NewFeeMempool(log.TestingLogger()), testTxDecoder(txs), testTxEncoder)
+	require.NoError(t, err)
+	require.Len(t, selects, 2)
+	require.Equal(t, int64(1), selects[0].Height)
+	require.Equal(t, []uint64{2, 3, 1}, selects[0].Recorded)
+	require.True(t, selects[0].Equal())
+	require.Equal(t, []uint64{3, 1}, selects[1].Recorded)
+	require.True(t, selects[1].Equal())
+
+	// replaying against a mempool ignoring fees gives another ordering
+	senderNonce := mempool.NewSenderNonceMempool(mempool.SenderNonceSeedOpt(1))
+	selects, err = mempool.ReplayJournal(bytes.NewReader(journal.Bytes()), senderNonce, testTxDecoder(txs), testTxEncoder)
+	require.NoError(t, err)
+	require.Len(t, selects, 2)
+	require.ElementsMatch(t, selects[0].Recorded, selects[0].Replayed)
+	require.Equal(t, 2, senderNonce.CountTx())
+}
+
+func TestJournalRemovedTx(t *testing.T) {
+	accounts := simtypes.RandomAccounts(rand.New(rand.NewSource(0)), 1)
+	ctx := testutil.DefaultContext(storetypes.NewKVStoreKey("test"), storetypes.NewTransientStoreKey("transient_test"))
+
+	tx := testTx{id: 0, address: accounts[0].Address, priority: 10}
+
+	var journal bytes.Buffer
+	pool := mempool.NewJournalMempool(log.TestingLogger(), mempool.NewFeeMempool(log.TestingLogger()), &journal, testTxEncoder)
+
+	// a removed tx is forgotten, so it is written again with a new id when inserted back
+	require.NoError(t, pool.Insert(ctx, tx))
+	require.NoError(t, pool.Remove(tx))
+	require.NoError(t, pool.Insert(ctx, tx))
+	selectIDs(ctx, pool)
+	require.NoError(t, pool.Flush())
+
+	selects, err := mempool.ReplayJournal(bytes.NewReader(journal.Bytes()), mempool.NewFeeMempool(log.TestingLogger()), testTxDecoder([]testTx{tx}), testTxEncoder)
+	require.NoError(t, err)
+	require.Len(t, selects, 1)
+	require.Equal(t, []uint64{2}, selects[0].Recorded)
+	require.True(t, selects[0].Equal())
+
+	// a truncated journal is reported
+	_, err = mempool.ReplayJournal(bytes.NewReader(journal.Bytes()[:journal.Len()-1]), mempool.NewFeeMempool(log.TestingLogger()), testTxDecoder([]testTx{tx}), testTxEncoder)
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
+
+func TestJournalRecheck(t *testing.T) {
+	accounts := simtypes.RandomAccounts(rand.New(rand.NewSource(0)), 1)
+	key := storetypes.NewKVStoreKey("test")
+	ctx := testutil.DefaultContext(key, storetypes.NewTransientStoreKey("transient_test"))
+
+	txs := []testTx{
+		{id: 0, address: accounts[0].Address, nonce: 0, priority: 10},
+		{id: 1, address: accounts[0].Address, nonce: 2, priority: 10},
+	}
+
+	var journal bytes.Buffer
+	pool := mempool.NewJournalMempool(log.TestingLogger(), mempool.NewFeeMempool(log.TestingLogger()), &journal, testTxEncoder)
+	index := mempool.NewSenderIndexMempool(pool)
+	for _, tx := range txs {
+		require.NoError(t, index.Insert(ctx, tx))
+	}
+
+	// the recheck removals are recorded, and the recheck does not select the mempool
+	rechecker := mempool.NewRechecker(log.TestingLogger(), index, sequenceAnteHandler(key), testTxEncoder, 0)
+	_, removed := rechecker.Recheck(ctx)
+	require.Equal(t, 1, removed)
+	selectIDs(ctx, pool)
+	require.NoError(t, pool.Flush())
+
+	selects, err := mempool.ReplayJournal(bytes.NewReader(journal.Bytes()), mempool.NewFeeMempool(log.TestingLogger()), testTxDecoder(txs), testTxEncoder)
+	require.NoError(t, err)
+	require.Len(t, selects, 1)
+	require.Equal(t, []uint64{1}, selects[0].Recorded)
+	require.True(t, selects[0].Equal())
+}
+
+// rejectingMempool is a mempool rejecting every insertion.
+type rejectingMempool struct {
+	*mempool.FeeMempool
+}
+
+func (rejectingMempool) Insert(context.Context, sdk.Tx) error {
+	return errors.New("rejected")
+}
+
+func TestJournalRejectedTx(t *testing.T) {
+	accounts := simtypes.RandomAccounts(rand.New(rand.NewSource(0)), 1)
+	ctx := testutil.DefaultContext(storetypes.NewKVStoreKey("test"), storetypes.NewTransientStoreKey("transient_test"))
+
+	tx := testTx{id: 0, address: accounts[0].Address, priority: 10}
+
+	var journal bytes.Buffer
+	pool := mempool.NewJournalMempool(log.TestingLogger(), rejectingMempool{mempool.NewFeeMempool(log.TestingLogger())}, &journal, testTxEncoder)
+
+	// a rejected tx is forgotten, so that the ids do not grow with rejected txs
+	require.Error(t, pool.Insert(ctx, tx))
+	require.Error(t, pool.Insert(ctx, tx))
+	require.NoError(t, pool.Flush())
+
+	selects, err := mempool.ReplayJournal(bytes.NewReader(journal.Bytes()), mempool.NewFeeMempool(log.TestingLogger()), testTxDecoder([]testTx{tx}), testTxEncoder)
+	require.NoError(t, err)
+	require.Empty(t, selects)
+
+	// both insertions carry the tx bytes, so the second one was given a new id
+	require.Equal(t, 2, bytes.Count(journal.Bytes(), []byte(tx.String())))
+}
+
+func TestJournalSelectDeadline(t *testing.T) {
+	accounts := simtypes.RandomAccounts(rand.New(rand.NewSource(0)), 10)
+	ctx := testutil.DefaultContext(storetypes.NewKVStoreKey("test"), storetypes.NewTransientStoreKey("transient_test"))
+
+	var txs []testTx
+	var journal bytes.Buffer
+	pool := mempool.NewJournalMempool(log.TestingLogger(), mempool.NewFeeMempool(log.TestingLogger()), &journal, testTxEncoder)
+	for i, account := range accounts {
+		tx := testTx{id: i, address: account.Address, priority: int64(100 - i)}
+		txs = append(txs, tx)
+		require.NoError(t, pool.Insert(ctx, tx))
+	}
+
+	// the selection stops once the context expires, after the txs iterated so far
+	cancelCtx, cancel := context.WithCancel(ctx.Context())
+	defer cancel()
+
+	var order []int
+	for it := pool.Select(ctx.WithContext(cancelCtx), nil); it != nil; it = it.Next() {
+		order = append(order, it.Tx().(testTx).id)
+		if len(order) == 3 {
+			cancel()
+		}
+	}
+	require.Equal(t, []int{0, 1, 2}, order)
+
+	// an expired context selects nothing
+	require.Nil(t, pool.Select(ctx.WithContext(cancelCtx), nil))
+
+	// a selection whose iteration is not over is written at the next operation
+	it := pool.Select(ctx, nil)
+	require.NotNil(t, it)
+	it.Next()
+	require.NoError(t, pool.Remove(txs[9]))
+	require.NoError(t, pool.Flush())
+
+	selects, err := mempool.ReplayJournal(bytes.NewReader(journal.Bytes()), mempool.NewFeeMempool(log.TestingLogger()), testTxDecoder(txs), testTxEncoder)
+	require.NoError(t, err)
+	require.Len(t, selects, 3)
+	require.Equal(t, []uint64{1, 2, 3}, selects[0].Recorded)
+	require.Empty(t, selects[1].Recorded)
+	require.Equal(t, []uint64{1}, selects[2].Recorded)
+	for _, selected := range selects {
+		require.True(t, selected.Equal())
+	}
+}
+
+func TestJournalSessions(t *testing.T) {
+	accounts := simtypes.RandomAccounts(rand.New(rand.NewSource(0)), 3)
+	ctx := testutil.DefaultContext(storetypes.NewKVStoreKey("test"), storetypes.NewTransientStoreKey("transient_test"))
+
+	txs := []testTx{
+		{id: 0, address: accounts[0].Address, priority: 10},
+		{id: 1, address: accounts[1].Address, priority: 30},
+		{id: 2, address: accounts[2].Address, priority: 20},
+	}
+
+	// a first session leaves a tx in the mempool
+	var journal bytes.Buffer
+	pool := mempool.NewJournalMempool(log.TestingLogger(), mempool.NewFeeMempool(log.TestingLogger()), &journal, testTxEncoder)
+	require.NoError(t, pool.Insert(ctx, txs[0]))
+	require.Equal(t, []int{0}, selectIDs(ctx, pool))
+	require.NoError(t, pool.Close())
+
+	// the node restarts with an empty mempool, appending to the journal with ids starting again at 1
+	pool = mempool.NewJournalMempool(log.TestingLogger(), mempool.NewFeeMempool(log.TestingLogger()), &journal, testTxEncoder)
+	require.NoError(t, pool.Insert(ctx, txs[1]))
+	require.NoError(t, pool.Insert(ctx, txs[2]))
+	require.Equal(t, []int{1, 2}, selectIDs(ctx, pool))
+	require.NoError(t, pool.Close())
+
+	// the replay drops the tx of the first session, and does not mix up the ids of both sessions
+	replayed := mempool.NewFeeMempool(log.TestingLogger())
+	selects, err := mempool.ReplayJournal(bytes.NewReader(journal.Bytes()), replayed, testTxDecoder(txs), testTxEncoder)
+	require.NoError(t, err)
+	require.Len(t, selects, 2)
+	require.Equal(t, []uint64{1}, selects[0].Recorded)
+	require.True(t, selects[0].Equal())
+	require.Equal(t, []uint64{1, 2}, selects[1].Recorded)
+	require.True(t, selects[1].Equal())
+	require.Equal(t, 2, replayed.CountTx())
+}
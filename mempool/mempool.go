@@ -0,0 +1,146 @@
+package mempool
+
+import (
+	"fmt"
+
+	"github.com/cometbft/cometbft/libs/log"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkmempool "github.com/cosmos/cosmos-sdk/types/mempool"
+)
+
+// Mempool types supported by NewMempool.
+const (
+	TypeNone          = "none"
+	TypeSenderNonce   = "sender-nonce"
+	TypePriorityNonce = "priority-nonce"
+	TypeFee           = "fee"
+)
+
+// Config defines the configuration of the mempool created by NewMempool.
+type Config struct {
+	// Type is the mempool type (none|sender-nonce|priority-nonce|fee).
+	Type string
+	// MaxTxs is the maximum number of transactions in the mempool, 0 for unbounded.
+	MaxTxs int
+	// MaxBytes is the maximum total encoded size of the transactions in the fee and sender-nonce mempools, 0 for unbounded.
+	MaxBytes int64
+	// TxEncoder encodes the transactions inserted outside of CheckTx to know their size, it is optional.
+	TxEncoder sdk.TxEncoder
+	// PriorityStrategy is the priority strategy of the fee mempool, it defaults to PriorityStrategyFee (see NewPriorityFunc).
+	PriorityStrategy PriorityStrategy
+	// PriorityFunc is the priority function of the fee mempool, it takes precedence over PriorityStrategy.
+	PriorityFunc PriorityFunc
+	// MsgTypeWeights multiply the priority of the fee mempool transactions per message type, they are optional.
+	MsgTypeWeights *MsgTypeWeights
+	// StakeBoost raises the priority of the fee mempool transactions whose fee payer has bonded tokens, it is optional.
+	StakeBoost StakeBoost
+	// Denom is the fee denomination used by the gas-price priority strategy without base fee keeper.
+	// IBC vouchers can be given by their full path (see ResolveDenom), and are then also ranked by the smallest coin strategies.
+	Denom string
+	// BaseFeeKeeper is used by the fee mempool to rank transactions by their tip, it is optional.
+	BaseFeeKeeper BaseFeeKeeper
+	// MaxFreeTxs is the maximum number of zero-fee transactions in the fee mempool, 0 for unbounded.
+	MaxFreeTxs int
+	// MaxTxsPerPayer is the maximum number of transactions paid by the same account (its fee granter if any) in the fee mempool, 0 for unbounded.
+	MaxTxsPerPayer int
+	// Aging is the priority aging of the fee mempool, disabled by default.
+	Aging PriorityAging
+	// Seed is the random seed of the sender-nonce mempool, 0 for a random seed.
+	Seed int64
+}
+
+// NewMempool creates the mempool of the given configuration.
+func NewMempool(logger log.Logger, cfg Config) (sdkmempool.Mempool, error) {
+	if cfg.Aging.Enabled() && cfg.Type != TypeFee {
+		return nil, fmt.Errorf("priority aging is only supported by the %s mempool, got: %s", TypeFee, cfg.Type)
+	}
+
+	if cfg.MsgTypeWeights != nil && cfg.Type != TypeFee {
+		return nil, fmt.Errorf("message type weights are only supported by the %s mempool, got: %s", TypeFee, cfg.Type)
+	}
+
+	if cfg.StakeBoost.Enabled() && cfg.Type != TypeFee {
+		return nil, fmt.Errorf("stake boost is only supported by the %s mempool, got: %s", TypeFee, cfg.Type)
+	}
+
+	if cfg.MaxBytes > 0 && cfg.Type != TypeFee && cfg.Type != TypeSenderNonce {
+		return nil, fmt.Errorf("bounding the size of the mempool is only supported by the %s and %s mempools, got: %s", TypeFee, TypeSenderNonce, cfg.Type)
+	}
+
+	if cfg.MaxFreeTxs > 0 && cfg.Type != TypeFee {
+		return nil, fmt.Errorf("bounding the zero-fee transactions is only supported by the %s mempool, got: %s", TypeFee, cfg.Type)
+	}
+
+	if cfg.MaxTxsPerPayer > 0 && cfg.Type != TypeFee {
+		return nil, fmt.Errorf("bounding the transactions per payer is only supported by the %s mempool, got: %s", TypeFee, cfg.Type)
+	}
+
+	switch cfg.Type {
+	case TypeNone:
+		// the pending txs are counted for the dynamic minimum gas prices
+		return NewCountingNoOpMempool(), nil
+	case TypeSenderNonce:
+		opts := []SenderNonceOptions{
+			SenderNonceMaxTxOpt(cfg.MaxTxs),
+			SenderNonceMaxBytesOpt(cfg.MaxBytes),
+			SenderNonceTxEncoderOpt(cfg.TxEncoder),
+		}
+		if cfg.Seed != 0 {
+			opts = append(opts, SenderNonceSeedOpt(cfg.Seed))
+		}
+
+		return NewSenderNonceMempool(opts...), nil
+	case TypePriorityNonce:
+		return sdkmempool.NewPriorityMempool(sdkmempool.PriorityNonceWithMaxTx(cfg.MaxTxs)), nil
+	case TypeFee:
+		if cfg.Denom != "" {
+			denom, err := ResolveDenom(cfg.Denom)
+			if err != nil {
+				return nil, fmt.Errorf("invalid fee denom: %w", err)
+			}
+			cfg.Denom = denom
+		}
+
+		priorityFunc := cfg.PriorityFunc
+		if priorityFunc == nil {
+			var err error
+			if priorityFunc, err = NewPriorityFunc(cfg.PriorityStrategy, cfg.BaseFeeKeeper, cfg.Denom); err != nil {
+				return nil, err
+			}
+		}
+
+		if cfg.MsgTypeWeights != nil {
+			priorityFunc = MsgTypeWeightsPriority(priorityFunc, cfg.MsgTypeWeights)
+		}
+
+		// the boost is added after the weights, so that it does not depend on the messages
+		if err := cfg.StakeBoost.Validate(); err != nil {
+			return nil, err
+		}
+
+		if cfg.StakeBoost.Enabled() {
+			priorityFunc = SumPriority(priorityFunc, StakeBoostPriority(cfg.StakeBoost))
+		}
+
+		if err := cfg.Aging.Validate(); err != nil {
+			return nil, err
+		}
+
+		opts := []FeeMempoolOption{
+			FeeMempoolPriorityFuncOpt(priorityFunc),
+			FeeMempoolAgingOpt(cfg.Aging),
+			FeeMempoolMaxFreeTxsOpt(cfg.MaxFreeTxs),
+			FeeMempoolMaxTxsPerPayerOpt(cfg.MaxTxsPerPayer),
+			FeeMempoolMaxBytesOpt(cfg.MaxBytes),
+			FeeMempoolTxEncoderOpt(cfg.TxEncoder),
+		}
+		if cfg.BaseFeeKeeper != nil {
+			opts = append(opts, FeeMempoolBaseFeeOpt(cfg.BaseFeeKeeper))
+		}
+
+		return NewFeeMempool(logger, opts...), nil
+	default:
+		return nil, fmt.Errorf("mempool not supported, got: %s, want %s|%s|%s|%s", cfg.Type, TypeNone, TypeSenderNonce, TypePriorityNonce, TypeFee)
+	}
+}
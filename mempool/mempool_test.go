@@ -44,7 +44,20 @@ type testTx struct {
 	id       int
 	priority int64
 	nonce    uint64
+	gas      uint64
 	address  sdk.AccAddress
+	// granter is the fee granter of the tx, if any
+	granter sdk.AccAddress
+	// feeDenom is the denom of the fee, mini if empty
+	feeDenom string
+	// cosigners are the other signers of the tx
+	cosigners []cosigner
+	msgs      []sdk.Msg
+}
+
+type cosigner struct {
+	address sdk.AccAddress
+	nonce   uint64
 }
 
 func (tx testTx) GetSigners() []sdk.AccAddress { panic("not implemented") }
@@ -52,19 +65,34 @@ func (tx testTx) GetSigners() []sdk.AccAddress { panic("not implemented") }
 func (tx testTx) GetPubKeys() ([]cryptotypes.PubKey, error) { panic("not implemented") }
 
 func (tx testTx) GetSignaturesV2() ([]txsigning.SignatureV2, error) {
-	return []txsigning.SignatureV2{{
+	sigs := []txsigning.SignatureV2{{
 		PubKey:   testPubKey{address: tx.address},
 		Data:     nil,
 		Sequence: tx.nonce,
-	}}, nil
+	}}
+
+	for _, signer := range tx.cosigners {
+		sigs = append(sigs, txsigning.SignatureV2{
+			PubKey:   testPubKey{address: signer.address},
+			Data:     nil,
+			Sequence: signer.nonce,
+		})
+	}
+
+	return sigs, nil
 }
 
 func (tx testTx) GetGas() uint64 {
-	return 10
+	return tx.gas
 }
 
 func (tx testTx) GetFee() sdk.Coins {
-	return sdk.NewCoins(sdk.NewCoin("mini", sdk.NewInt(tx.priority)))
+	denom := tx.feeDenom
+	if denom == "" {
+		denom = "mini"
+	}
+
+	return sdk.NewCoins(sdk.NewCoin(denom, sdk.NewInt(tx.priority)))
 }
 
 func (tx testTx) FeePayer() sdk.AccAddress {
@@ -72,7 +100,7 @@ func (tx testTx) FeePayer() sdk.AccAddress {
 }
 
 func (tx testTx) FeeGranter() sdk.AccAddress {
-	return tx.address
+	return tx.granter
 }
 
 var (
@@ -81,10 +109,10 @@ var (
 	_ cryptotypes.PubKey      = (*testPubKey)(nil)
 )
 
-func (tx testTx) GetMsgs() []sdk.Msg { return nil }
+func (tx testTx) GetMsgs() []sdk.Msg { return tx.msgs }
 
 func (tx testTx) ValidateBasic() error { return nil }
 
 func (tx testTx) String() string {
-	return fmt.Sprintf("tx a: %s, p: %d, n: %d", tx.address, tx.priority, tx.nonce)
+	return fmt.Sprintf("tx a: %s, p: %d, n: %d, c: %v", tx.address, tx.priority, tx.nonce, tx.cosigners)
 }
@@ -0,0 +1,164 @@
+package mempool
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"sync/atomic"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/mempool"
+)
+
+const (
+	// CurveNone disables the dynamic minimum gas prices.
+	CurveNone = "none"
+	// CurveLinear raises the minimum gas prices linearly between the occupancy thresholds.
+	CurveLinear = "linear"
+	// CurveExponential raises the minimum gas prices exponentially between the occupancy thresholds.
+	CurveExponential = "exponential"
+
+	// exponentialSteepness is the steepness of the exponential curve.
+	exponentialSteepness = 5
+)
+
+var (
+	// DefaultMinGasPricesLowOccupancy is the default mempool occupancy from which the minimum gas prices start to rise.
+	DefaultMinGasPricesLowOccupancy = 0.5
+	// DefaultMinGasPricesHighOccupancy is the default mempool occupancy at which the minimum gas prices reach their maximum.
+	DefaultMinGasPricesHighOccupancy = 0.9
+)
+
+// DynamicMinGasPrices computes node-local minimum gas prices from the occupancy of the app-side mempool.
+// Below the low occupancy threshold, the node minimum gas prices are used. Above the high occupancy threshold,
+// the maximum gas prices are used. In between, the minimum gas prices follow the configured curve.
+//
+// The app-side mempools are not safe for concurrent use, so the mempool is only counted by Update, on the ABCI path,
+// and the minimum gas prices can be read from any goroutine (e.g. the node gRPC service).
+type DynamicMinGasPrices struct {
+	mempool mempool.Mempool
+	maxTx   int
+	curve   string
+	low     float64
+	high    float64
+	max     sdk.DecCoins
+
+	// count is the number of transactions in the mempool at the last Update
+	count atomic.Int64
+}
+
+// NewDynamicMinGasPrices creates a new DynamicMinGasPrices for the given mempool.
+// The occupancy of the mempool is computed against maxTx, which must be positive.
+func NewDynamicMinGasPrices(mp mempool.Mempool, maxTx int, curve string, low, high float64, maxGasPrices sdk.DecCoins) (*DynamicMinGasPrices, error) {
+	if curve != CurveLinear && curve != CurveExponential {
+		return nil, fmt.Errorf("invalid curve, got: %s, want %s|%s", curve, CurveLinear, CurveExponential)
+	}
+
+	if maxTx <= 0 {
+		return nil, fmt.Errorf("dynamic minimum gas prices require a bounded mempool, got max txs: %d", maxTx)
+	}
+
+	if low < 0 || high > 1 || low >= high {
+		return nil, fmt.Errorf("invalid occupancy thresholds, got: [%v, %v], want 0 <= low < high <= 1", low, high)
+	}
+
+	if maxGasPrices.Empty() {
+		return nil, fmt.Errorf("maximum gas prices cannot be empty")
+	}
+
+	d := &DynamicMinGasPrices{
+		mempool: mp,
+		maxTx:   maxTx,
+		curve:   curve,
+		low:     low,
+		high:    high,
+		max:     maxGasPrices,
+	}
+	d.Update()
+
+	return d, nil
+}
+
+// Update counts the transactions of the mempool. It must be called where the mempool is changed, i.e. during the
+// ABCI calls (see MinGasPricesDecorator and the app Commit).
+func (d *DynamicMinGasPrices) Update() {
+	d.count.Store(int64(d.mempool.CountTx()))
+}
+
+// Occupancy returns the occupancy of the mempool at the last Update, between 0 and 1.
+func (d *DynamicMinGasPrices) Occupancy() float64 {
+	return math.Min(float64(d.count.Load())/float64(d.maxTx), 1)
+}
+
+// MinGasPrices returns the minimum gas prices for the current mempool occupancy.
+// The node minimum gas prices are never lowered.
+func (d *DynamicMinGasPrices) MinGasPrices(nodeMinGasPrices sdk.DecCoins) sdk.DecCoins {
+	ratio := d.ratio(d.Occupancy())
+	if ratio == 0 {
+		return nodeMinGasPrices
+	}
+
+	// float64 precision is plenty for a price curve
+	ratioDec := sdk.MustNewDecFromStr(strconv.FormatFloat(ratio, 'f', 6, 64))
+
+	prices := sdk.NewDecCoins()
+	for _, price := range nodeMinGasPrices {
+		if d.max.AmountOf(price.Denom).IsZero() {
+			prices = prices.Add(price)
+		}
+	}
+
+	for _, maxPrice := range d.max {
+		nodePrice := nodeMinGasPrices.AmountOf(maxPrice.Denom)
+		if maxPrice.Amount.LTE(nodePrice) {
+			prices = prices.Add(sdk.NewDecCoinFromDec(maxPrice.Denom, nodePrice))
+			continue
+		}
+
+		price := nodePrice.Add(maxPrice.Amount.Sub(nodePrice).Mul(ratioDec))
+		prices = prices.Add(sdk.NewDecCoinFromDec(maxPrice.Denom, price))
+	}
+
+	return prices
+}
+
+// ratio returns the position on the curve (between 0 and 1) of the given occupancy.
+func (d *DynamicMinGasPrices) ratio(occupancy float64) float64 {
+	if occupancy <= d.low {
+		return 0
+	}
+
+	if occupancy >= d.high {
+		return 1
+	}
+
+	x := (occupancy - d.low) / (d.high - d.low)
+	if d.curve == CurveExponential {
+		return math.Expm1(exponentialSteepness*x) / math.Expm1(exponentialSteepness)
+	}
+
+	return x
+}
+
+// MinGasPricesDecorator raises the minimum gas prices of the context to the dynamic minimum gas prices.
+// It only applies to new transactions in CheckTx, so that transactions already accepted in the mempool
+// are not evicted on recheck when the mempool fills up. It must be placed before the DeductFeeDecorator.
+// It updates the occupancy of the mempool, which CheckTx changes.
+type MinGasPricesDecorator struct {
+	minGasPrices *DynamicMinGasPrices
+}
+
+// NewMinGasPricesDecorator creates a new MinGasPricesDecorator.
+// A nil DynamicMinGasPrices makes the decorator a no-op.
+func NewMinGasPricesDecorator(minGasPrices *DynamicMinGasPrices) MinGasPricesDecorator {
+	return MinGasPricesDecorator{minGasPrices: minGasPrices}
+}
+
+func (d MinGasPricesDecorator) AnteHandle(ctx sdk.Context, tx sdk.Tx, simulate bool, next sdk.AnteHandler) (sdk.Context, error) {
+	if d.minGasPrices == nil || !ctx.IsCheckTx() || ctx.IsReCheckTx() || simulate {
+		return next(ctx, tx, simulate)
+	}
+
+	d.minGasPrices.Update()
+	return next(ctx.WithMinGasPrices(d.minGasPrices.MinGasPrices(ctx.MinGasPrices())), tx, simulate)
+}
@@ -0,0 +1,148 @@
+package mempool_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/cometbft/cometbft/libs/log"
+	storetypes "github.com/cosmos/cosmos-sdk/store/types"
+	"github.com/cosmos/cosmos-sdk/testutil"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	simtypes "github.com/cosmos/cosmos-sdk/types/simulation"
+	"github.com/stretchr/testify/require"
+
+	"github.com/julienrbrt/chain-minimal/mempool"
+)
+
+func TestDynamicMinGasPrices(t *testing.T) {
+	accounts := simtypes.RandomAccounts(rand.New(rand.NewSource(0)), 10)
+	ctx := testutil.DefaultContext(storetypes.NewKVStoreKey("test"), storetypes.NewTransientStoreKey("transient_test"))
+
+	nodeMinGasPrices := sdk.NewDecCoins(sdk.NewDecCoinFromDec("mini", sdk.MustNewDecFromStr("0.001")), sdk.NewDecCoin("stake", sdk.NewInt(1)))
+	maxGasPrices := sdk.NewDecCoins(sdk.NewDecCoinFromDec("mini", sdk.MustNewDecFromStr("0.101")))
+
+	tests := []struct {
+		curve    string
+		count    int
+		expected string
+	}{
+		{curve: mempool.CurveLinear, count: 0, expected: "0.001000000000000000mini,1.000000000000000000stake"},
+		{curve: mempool.CurveLinear, count: 5, expected: "0.001000000000000000mini,1.000000000000000000stake"},
+		{curve: mempool.CurveLinear, count: 7, expected: "0.051000000000000000mini,1.000000000000000000stake"},
+		{curve: mempool.CurveLinear, count: 9, expected: "0.101000000000000000mini,1.000000000000000000stake"},
+		{curve: mempool.CurveLinear, count: 10, expected: "0.101000000000000000mini,1.000000000000000000stake"},
+		{curve: mempool.CurveExponential, count: 5, expected: "0.001000000000000000mini,1.000000000000000000stake"},
+		{curve: mempool.CurveExponential, count: 7, expected: "0.008585800000000000mini,1.000000000000000000stake"},
+		{curve: mempool.CurveExponential, count: 9, expected: "0.101000000000000000mini,1.000000000000000000stake"},
+	}
+
+	for _, tt := range tests {
+		pool := mempool.NewFeeMempool(log.TestingLogger())
+		for i := 0; i < tt.count; i++ {
+			require.NoError(t, pool.Insert(ctx, testTx{id: i, address: accounts[i].Address}))
+		}
+
+		minGasPrices, err := mempool.NewDynamicMinGasPrices(pool, 10, tt.curve, 0.5, 0.9, maxGasPrices)
+		require.NoError(t, err)
+		require.Equal(t, tt.expected, minGasPrices.MinGasPrices(nodeMinGasPrices).String(), "curve %s, count %d", tt.curve, tt.count)
+	}
+}
+
+// TestDynamicMinGasPricesConcurrentReads checks that the minimum gas prices can be read while the mempool is changed,
+// the occupancy only following the mempool on Update (run with -race).
+func TestDynamicMinGasPricesConcurrentReads(t *testing.T) {
+	accounts := simtypes.RandomAccounts(rand.New(rand.NewSource(0)), 10)
+	ctx := testutil.DefaultContext(storetypes.NewKVStoreKey("test"), storetypes.NewTransientStoreKey("transient_test"))
+
+	pool := mempool.NewFeeMempool(log.TestingLogger())
+	maxGasPrices := sdk.NewDecCoins(sdk.NewDecCoin("mini", sdk.NewInt(1)))
+	minGasPrices, err := mempool.NewDynamicMinGasPrices(pool, 10, mempool.CurveLinear, 0.5, 0.9, maxGasPrices)
+	require.NoError(t, err)
+
+	done := make(chan struct{})
+	read := make(chan struct{})
+	go func() {
+		defer close(read)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				minGasPrices.MinGasPrices(sdk.NewDecCoins())
+			}
+		}
+	}()
+
+	for i, account := range accounts {
+		require.NoError(t, pool.Insert(ctx, testTx{id: i, address: account.Address}))
+	}
+	require.Zero(t, minGasPrices.Occupancy())
+
+	minGasPrices.Update()
+	close(done)
+	<-read
+	require.Equal(t, float64(1), minGasPrices.Occupancy())
+}
+
+func TestDynamicMinGasPricesValidation(t *testing.T) {
+	pool := mempool.NewFeeMempool(log.TestingLogger())
+	maxGasPrices := sdk.NewDecCoins(sdk.NewDecCoin("mini", sdk.NewInt(1)))
+
+	_, err := mempool.NewDynamicMinGasPrices(pool, 10, "quadratic", 0.5, 0.9, maxGasPrices)
+	require.Error(t, err)
+
+	_, err = mempool.NewDynamicMinGasPrices(pool, 0, mempool.CurveLinear, 0.5, 0.9, maxGasPrices)
+	require.Error(t, err)
+
+	_, err = mempool.NewDynamicMinGasPrices(pool, 10, mempool.CurveLinear, 0.9, 0.5, maxGasPrices)
+	require.Error(t, err)
+
+	_, err = mempool.NewDynamicMinGasPrices(pool, 10, mempool.CurveLinear, 0.5, 0.9, sdk.NewDecCoins())
+	require.Error(t, err)
+}
+
+func TestMinGasPricesDecorator(t *testing.T) {
+	accounts := simtypes.RandomAccounts(rand.New(rand.NewSource(0)), 2)
+	ctx := testutil.DefaultContext(storetypes.NewKVStoreKey("test"), storetypes.NewTransientStoreKey("transient_test")).
+		WithIsCheckTx(true).
+		WithMinGasPrices(sdk.NewDecCoins())
+
+	// a full mempool
+	pool := mempool.NewFeeMempool(log.TestingLogger())
+	require.NoError(t, pool.Insert(ctx, testTx{id: 0, address: accounts[0].Address}))
+
+	maxGasPrices := sdk.NewDecCoins(sdk.NewDecCoin("mini", sdk.NewInt(1)))
+	minGasPrices, err := mempool.NewDynamicMinGasPrices(pool, 1, mempool.CurveLinear, 0.5, 0.9, maxGasPrices)
+	require.NoError(t, err)
+
+	var got sdk.DecCoins
+	next := func(ctx sdk.Context, _ sdk.Tx, _ bool) (sdk.Context, error) {
+		got = ctx.MinGasPrices()
+		return ctx, nil
+	}
+
+	decorator := mempool.NewMinGasPricesDecorator(minGasPrices)
+	tx := testTx{id: 1, address: accounts[1].Address}
+
+	_, err = decorator.AnteHandle(ctx, tx, false, next)
+	require.NoError(t, err)
+	require.Equal(t, maxGasPrices, got)
+
+	// rechecked, simulated and delivered txs are not affected
+	_, err = decorator.AnteHandle(ctx.WithIsReCheckTx(true), tx, false, next)
+	require.NoError(t, err)
+	require.True(t, got.IsZero())
+
+	_, err = decorator.AnteHandle(ctx, tx, true, next)
+	require.NoError(t, err)
+	require.True(t, got.IsZero())
+
+	_, err = decorator.AnteHandle(ctx.WithIsCheckTx(false), tx, false, next)
+	require.NoError(t, err)
+	require.True(t, got.IsZero())
+
+	// disabled
+	_, err = mempool.NewMinGasPricesDecorator(nil).AnteHandle(ctx, tx, false, next)
+	require.NoError(t, err)
+	require.True(t, got.IsZero())
+}
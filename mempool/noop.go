@@ -0,0 +1,139 @@
+package mempool
+
+import (
+	"context"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/mempool"
+)
+
+// noOpPendingTxTTL is the number of blocks after which a transaction is no longer counted as pending.
+const noOpPendingTxTTL = 100
+
+var _ mempool.Mempool = (*CountingNoOpMempool)(nil)
+
+// CountingNoOpMempool is a no-op mempool, like mempool.NoOpMempool, that keeps track of the number of transactions
+// pending in the CometBFT mempool. Transactions are forgotten once a transaction with the same or a higher nonce
+// from the same sender is delivered, or after noOpPendingTxTTL blocks, so the count is an approximation.
+//
+// NOTE: BaseApp only skips the app-side mempool in the default proposal handlers when using mempool.NoOpMempool,
+// the no-op proposal handlers must thus be set explicitly when using this mempool.
+type CountingNoOpMempool struct {
+	mempool.NoOpMempool
+
+	// pending are the pending nonces, per sender
+	pending   map[string]map[uint64]pendingTx
+	count     int
+	sizeBytes int64
+
+	// prunedHeight is the height of the last prune, which runs once per height
+	prunedHeight int64
+}
+
+// pendingTx is a transaction pending in the CometBFT mempool.
+type pendingTx struct {
+	// height is the height at which the transaction was checked
+	height int64
+	// size is the encoded size of the transaction
+	size int64
+}
+
+// NewCountingNoOpMempool creates a new CountingNoOpMempool.
+func NewCountingNoOpMempool() *CountingNoOpMempool {
+	return &CountingNoOpMempool{pending: make(map[string]map[uint64]pendingTx)}
+}
+
+// Insert records the transaction as pending, with the height and the size of the transaction bytes of the context
+// when it is an sdk.Context.
+func (mp *CountingNoOpMempool) Insert(ctx context.Context, tx sdk.Tx) error {
+	sender, nonce, ok := firstSigner(tx)
+	if !ok {
+		return nil
+	}
+
+	var pending pendingTx
+	if sdkCtx, ok := ctx.(sdk.Context); ok {
+		// the size is known during CheckTx only, from the transaction bytes of the context
+		pending = pendingTx{height: sdkCtx.BlockHeight(), size: int64(len(sdkCtx.TxBytes()))}
+	}
+
+	if pending.height > mp.prunedHeight {
+		mp.prune(pending.height)
+		mp.prunedHeight = pending.height
+	}
+
+	nonces, ok := mp.pending[sender]
+	if !ok {
+		nonces = make(map[uint64]pendingTx)
+		mp.pending[sender] = nonces
+	}
+
+	if previous, ok := nonces[nonce]; ok {
+		mp.sizeBytes -= previous.size
+	} else {
+		mp.count++
+	}
+
+	nonces[nonce] = pending
+	mp.sizeBytes += pending.size
+
+	return nil
+}
+
+// CountTx returns the approximate number of transactions pending in the CometBFT mempool.
+func (mp *CountingNoOpMempool) CountTx() int {
+	return mp.count
+}
+
+// SizeBytes returns the approximate encoded size of the transactions pending in the CometBFT mempool.
+func (mp *CountingNoOpMempool) SizeBytes() int64 {
+	return mp.sizeBytes
+}
+
+// Remove forgets the delivered transaction, as well as the pending transactions of the same sender
+// with a lower nonce, as they cannot be valid anymore.
+func (mp *CountingNoOpMempool) Remove(tx sdk.Tx) error {
+	sender, nonce, ok := firstSigner(tx)
+	if !ok {
+		return nil
+	}
+
+	for pendingNonce := range mp.pending[sender] {
+		if pendingNonce <= nonce {
+			mp.forget(sender, pendingNonce)
+		}
+	}
+
+	return nil
+}
+
+// prune forgets the transactions pending for more than noOpPendingTxTTL blocks.
+func (mp *CountingNoOpMempool) prune(height int64) {
+	for sender, nonces := range mp.pending {
+		for nonce, tx := range nonces {
+			if height-tx.height > noOpPendingTxTTL {
+				mp.forget(sender, nonce)
+			}
+		}
+	}
+}
+
+func (mp *CountingNoOpMempool) forget(sender string, nonce uint64) {
+	mp.sizeBytes -= mp.pending[sender][nonce].size
+	delete(mp.pending[sender], nonce)
+	if len(mp.pending[sender]) == 0 {
+		delete(mp.pending, sender)
+	}
+
+	mp.count--
+}
+
+// firstSigner returns the address and the nonce of the first signer of the transaction.
+func firstSigner(tx sdk.Tx) (string, uint64, bool) {
+	signers, err := txSigners(tx)
+	if err != nil {
+		return "", 0, false
+	}
+
+	return signers[0].address, signers[0].nonce, true
+}
@@ -0,0 +1,48 @@
+package mempool_test
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	storetypes "github.com/cosmos/cosmos-sdk/store/types"
+	"github.com/cosmos/cosmos-sdk/testutil"
+	simtypes "github.com/cosmos/cosmos-sdk/types/simulation"
+	"github.com/stretchr/testify/require"
+
+	"github.com/julienrbrt/chain-minimal/mempool"
+)
+
+func TestCountingNoOpMempool(t *testing.T) {
+	accounts := simtypes.RandomAccounts(rand.New(rand.NewSource(0)), 2)
+	sa := accounts[0].Address
+	sb := accounts[1].Address
+
+	ctx := testutil.DefaultContext(storetypes.NewKVStoreKey("test"), storetypes.NewTransientStoreKey("transient_test")).WithBlockHeight(1)
+
+	pool := mempool.NewCountingNoOpMempool()
+	for _, tx := range []testTx{
+		{id: 0, address: sa, nonce: 0},
+		{id: 1, address: sa, nonce: 1},
+		{id: 2, address: sa, nonce: 2},
+		{id: 3, address: sb, nonce: 0},
+		{id: 4, address: sb, nonce: 0}, // replaces the pending tx with the same nonce
+	} {
+		require.NoError(t, pool.Insert(ctx, tx))
+	}
+	require.Equal(t, 4, pool.CountTx())
+	require.Nil(t, pool.Select(ctx, nil))
+
+	// delivering a nonce invalidates the lower ones
+	require.NoError(t, pool.Remove(testTx{address: sa, nonce: 1}))
+	require.Equal(t, 2, pool.CountTx())
+
+	// pending txs are forgotten after a while
+	require.NoError(t, pool.Insert(ctx.WithBlockHeight(1000), testTx{id: 5, address: sa, nonce: 3}))
+	require.Equal(t, 1, pool.CountTx())
+
+	// a plain context is accepted, the tx being counted without height nor size
+	require.NoError(t, pool.Insert(context.Background(), testTx{id: 6, address: sb, nonce: 1}))
+	require.Equal(t, 2, pool.CountTx())
+	require.Zero(t, pool.SizeBytes())
+}
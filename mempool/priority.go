@@ -0,0 +1,302 @@
+package mempool
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// PriorityFunc computes the priority of a transaction inserted in the FeeMempool.
+// The context is the one given to Insert, i.e. the post-ante context during CheckTx.
+type PriorityFunc func(ctx context.Context, tx sdk.Tx) (int64, error)
+
+// PriorityStrategy names a built-in PriorityFunc, selectable with NewPriorityFunc.
+type PriorityStrategy string
+
+const (
+	// PriorityStrategyFee computes the priority from the fee of the transaction (see FeePriority).
+	PriorityStrategyFee PriorityStrategy = "fee"
+	// PriorityStrategyAnte uses the priority set in the context by the ante handler (see AntePriority).
+	PriorityStrategyAnte PriorityStrategy = "ante"
+	// PriorityStrategyMinCoin uses the amount of the smallest coin of the fee (see MinCoinPriority).
+	PriorityStrategyMinCoin PriorityStrategy = "min-coin"
+	// PriorityStrategyGasPrice uses the gas price paid above the base fee (see GasPricePriority).
+	PriorityStrategyGasPrice PriorityStrategy = "gas-price"
+)
+
+// GasPricePrecision is the priority of a gas price of 1, so that fractional gas prices are ranked.
+const GasPricePrecision int64 = 1_000_000
+
+// NewPriorityFunc returns the PriorityFunc of the given strategy. Strategies joined with a "+" are summed,
+// e.g. "gas-price+ante". The base fee keeper is optional, and the denom is the fee denomination of the
+// gas-price strategy when no base fee keeper is given, also ranked by the smallest coin strategies when
+// it is an IBC voucher.
+func NewPriorityFunc(strategy PriorityStrategy, baseFeeKeeper BaseFeeKeeper, denom string) (PriorityFunc, error) {
+	if strategy == "" {
+		strategy = PriorityStrategyFee
+	}
+
+	var vouchers []string
+	if denom != "" {
+		vouchers = append(vouchers, denom)
+	}
+
+	var fns []PriorityFunc
+	for _, name := range strings.Split(string(strategy), "+") {
+		switch PriorityStrategy(strings.TrimSpace(name)) {
+		case PriorityStrategyFee:
+			fns = append(fns, FeePriority(baseFeeKeeper, vouchers...))
+		case PriorityStrategyAnte:
+			fns = append(fns, AntePriority())
+		case PriorityStrategyMinCoin:
+			fns = append(fns, MinCoinPriority(vouchers...))
+		case PriorityStrategyGasPrice:
+			fns = append(fns, GasPricePriority(baseFeeKeeper, denom))
+		default:
+			return nil, fmt.Errorf("priority strategy not supported, got: %s, want %s|%s|%s|%s or a sum of them (e.g. %s+%s)",
+				name, PriorityStrategyFee, PriorityStrategyAnte, PriorityStrategyMinCoin, PriorityStrategyGasPrice, PriorityStrategyGasPrice, PriorityStrategyAnte)
+		}
+	}
+
+	if len(fns) == 1 {
+		return fns[0], nil
+	}
+
+	return SumPriority(fns...), nil
+}
+
+// FeePriority ranks transactions by the tip they pay above the base fee (i.e. the fee minus the base fee times
+// the gas limit) when a base fee keeper is given, and by the smallest coin of their fee otherwise.
+// Without sdk.Context (e.g. when replaying a journal), the base fee is unknown and the smallest coin is used.
+// The IBC vouchers are ranked as the smallest coin only when listed (see MinCoinPriority).
+func FeePriority(baseFeeKeeper BaseFeeKeeper, vouchers ...string) PriorityFunc {
+	minCoin := MinCoinPriority(vouchers...)
+	if baseFeeKeeper == nil {
+		return minCoin
+	}
+
+	return func(ctx context.Context, tx sdk.Tx) (int64, error) {
+		feeTx, ok := tx.(sdk.FeeTx)
+		if !ok {
+			return 0, nil
+		}
+
+		sdkCtx, ok := sdkContext(ctx)
+		if !ok {
+			return minCoin(ctx, tx)
+		}
+
+		baseFee := baseFeeKeeper.GetBaseFee(sdkCtx)
+		return GetTxTip(feeTx.GetFee(), feeTx.GetGas(), baseFee), nil
+	}
+}
+
+// AntePriority uses the priority computed by the ante handler TxFeeChecker during CheckTx.
+// Without sdk.Context, the priority is 0.
+func AntePriority() PriorityFunc {
+	return func(ctx context.Context, _ sdk.Tx) (int64, error) {
+		sdkCtx, ok := sdkContext(ctx)
+		if !ok {
+			return 0, nil
+		}
+
+		return sdkCtx.Priority(), nil
+	}
+}
+
+// MinCoinPriority ranks transactions by the amount of the smallest coin of their fee, whatever its denomination.
+// The IBC vouchers (i.e. ibc/{hash} denoms) are not comparable with the native coins, so they are ignored,
+// unless listed in the given vouchers.
+func MinCoinPriority(vouchers ...string) PriorityFunc {
+	return func(_ context.Context, tx sdk.Tx) (int64, error) {
+		feeTx, ok := tx.(sdk.FeeTx)
+		if !ok {
+			return 0, nil
+		}
+
+		return naiveGetTxPriority(feeTx.GetFee(), vouchers), nil
+	}
+}
+
+// GasPricePriority ranks transactions by the gas price they pay above the base fee, in the base fee denomination,
+// scaled by GasPricePrecision. Without base fee keeper or sdk.Context, the whole gas price paid in the given denom is used.
+// Contrary to FeePriority, a transaction does not outrank another one by only setting a higher gas limit.
+func GasPricePriority(baseFeeKeeper BaseFeeKeeper, denom string) PriorityFunc {
+	return func(ctx context.Context, tx sdk.Tx) (int64, error) {
+		feeTx, ok := tx.(sdk.FeeTx)
+		if !ok || feeTx.GetGas() == 0 {
+			return 0, nil
+		}
+
+		baseFee := sdk.NewDecCoin(denom, sdk.ZeroInt())
+		if sdkCtx, ok := sdkContext(ctx); ok && baseFeeKeeper != nil {
+			baseFee = baseFeeKeeper.GetBaseFee(sdkCtx)
+		}
+
+		tip := GetTxTip(feeTx.GetFee(), feeTx.GetGas(), baseFee)
+		price := sdk.NewDec(tip).MulInt64(GasPricePrecision).QuoInt(sdk.NewIntFromUint64(feeTx.GetGas()))
+
+		return decToPriority(price), nil
+	}
+}
+
+// MsgTypeWeightsPriority multiplies the priority of the given function by the weight of the messages of the
+// transaction (see MsgTypeWeights). The weights are read at each call, so they can be updated at runtime.
+func MsgTypeWeightsPriority(fn PriorityFunc, weights *MsgTypeWeights) PriorityFunc {
+	return func(ctx context.Context, tx sdk.Tx) (int64, error) {
+		priority, err := fn(ctx, tx)
+		if err != nil {
+			return 0, err
+		}
+
+		return decToPriority(weights.Weight(tx.GetMsgs()).MulInt64(priority)), nil
+	}
+}
+
+// StakingKeeper defines the expected staking keeper, returning the tokens bonded by a delegator.
+type StakingKeeper interface {
+	GetDelegatorBonded(ctx sdk.Context, delegator sdk.AccAddress) sdk.Int
+}
+
+// DefaultStakeBoostMax is the default maximum priority boost of the stakers.
+const DefaultStakeBoostMax int64 = 100
+
+// StakeBoost defines the priority boost of the transactions whose fee payer has bonded tokens.
+type StakeBoost struct {
+	// Keeper returns the tokens bonded by the fee payer, at the state of the context given to Insert.
+	Keeper StakingKeeper
+	// TokensPerPriority is the amount of bonded tokens giving a priority boost of 1, 0 to disable the boost.
+	TokensPerPriority sdk.Int
+	// MaxBoost caps the boost, so that the largest stakers cannot outrank every paying transaction.
+	MaxBoost int64
+}
+
+// Enabled returns whether the stake boost is enabled.
+func (b StakeBoost) Enabled() bool {
+	return !b.TokensPerPriority.IsNil() && b.TokensPerPriority.IsPositive()
+}
+
+// Validate validates the stake boost configuration.
+func (b StakeBoost) Validate() error {
+	if !b.Enabled() {
+		return nil
+	}
+
+	if b.Keeper == nil {
+		return fmt.Errorf("stake boost requires a staking keeper")
+	}
+
+	if b.MaxBoost <= 0 {
+		return fmt.Errorf("stake boost must be capped by a positive max boost, got: %d", b.MaxBoost)
+	}
+
+	return nil
+}
+
+// StakeBoostPriority gives the transactions a priority of the amount bonded by their fee payer divided by
+// the tokens per priority, capped at the max boost. It is meant to be summed with a fee based priority
+// (see SumPriority). Zero-fee transactions are not boosted, so that stakers cannot
+// outrank paying transactions for free. Without sdk.Context (e.g. when replaying a journal), there is no boost.
+func StakeBoostPriority(boost StakeBoost) PriorityFunc {
+	return func(ctx context.Context, tx sdk.Tx) (int64, error) {
+		sdkCtx, ok := sdkContext(ctx)
+		if !ok || !boost.Enabled() || IsZeroFee(tx) {
+			return 0, nil
+		}
+
+		feeTx, ok := tx.(sdk.FeeTx)
+		if !ok {
+			return 0, nil
+		}
+
+		bonded := boost.Keeper.GetDelegatorBonded(sdkCtx, feeTx.FeePayer())
+		priority := decToPriority(sdk.NewDecFromInt(bonded.Quo(boost.TokensPerPriority)))
+		if boost.MaxBoost > 0 && priority > boost.MaxBoost {
+			return boost.MaxBoost, nil
+		}
+
+		return priority, nil
+	}
+}
+
+// SumPriority sums the priorities of the given functions, saturating at math.MaxInt64.
+func SumPriority(fns ...PriorityFunc) PriorityFunc {
+	return func(ctx context.Context, tx sdk.Tx) (int64, error) {
+		var sum int64
+		for _, fn := range fns {
+			priority, err := fn(ctx, tx)
+			if err != nil {
+				return 0, err
+			}
+
+			sum = addPriority(sum, priority)
+		}
+
+		return sum, nil
+	}
+}
+
+// MaxPriority uses the highest priority of the given functions.
+func MaxPriority(fns ...PriorityFunc) PriorityFunc {
+	return func(ctx context.Context, tx sdk.Tx) (int64, error) {
+		var highest int64 = math.MinInt64
+		for _, fn := range fns {
+			priority, err := fn(ctx, tx)
+			if err != nil {
+				return 0, err
+			}
+
+			if priority > highest {
+				highest = priority
+			}
+		}
+
+		if len(fns) == 0 {
+			return 0, nil
+		}
+
+		return highest, nil
+	}
+}
+
+// ScalePriority multiplies the priority of the given function by a factor, saturating at math.MaxInt64.
+func ScalePriority(fn PriorityFunc, factor sdk.Dec) PriorityFunc {
+	return func(ctx context.Context, tx sdk.Tx) (int64, error) {
+		priority, err := fn(ctx, tx)
+		if err != nil {
+			return 0, err
+		}
+
+		return decToPriority(factor.MulInt64(priority)), nil
+	}
+}
+
+// addPriority adds two priorities, saturating at math.MaxInt64 and math.MinInt64.
+func addPriority(a, b int64) int64 {
+	if b > 0 && a > math.MaxInt64-b {
+		return math.MaxInt64
+	}
+
+	if b < 0 && a < math.MinInt64-b {
+		return math.MinInt64
+	}
+
+	return a + b
+}
+
+// decToPriority truncates a decimal to a priority, saturating at math.MaxInt64 and math.MinInt64.
+func decToPriority(d sdk.Dec) int64 {
+	i := d.TruncateInt()
+	if !i.IsInt64() {
+		if i.IsNegative() {
+			return math.MinInt64
+		}
+
+		return math.MaxInt64
+	}
+
+	return i.Int64()
+}
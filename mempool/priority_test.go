@@ -0,0 +1,255 @@
+package mempool_test
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/cometbft/cometbft/libs/log"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	simtypes "github.com/cosmos/cosmos-sdk/types/simulation"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/julienrbrt/chain-minimal/mempool"
+)
+
+type testStakingKeeper map[string]sdk.Int
+
+func (k testStakingKeeper) GetDelegatorBonded(_ sdk.Context, delegator sdk.AccAddress) sdk.Int {
+	if bonded, ok := k[delegator.String()]; ok {
+		return bonded
+	}
+
+	return sdk.ZeroInt()
+}
+
+func TestPriorityFuncs(t *testing.T) {
+	accounts := simtypes.RandomAccounts(rand.New(rand.NewSource(0)), 2)
+	sa, sb := accounts[0].Address, accounts[1].Address
+	ctx := sdk.Context{}.WithPriority(7)
+
+	// a fee of 100 for 50 gas, i.e. a gas price of 2
+	tx := testTx{address: sa, priority: 100, gas: 50}
+	keeper := testBaseFeeKeeper{baseFee: sdk.NewDecCoin("mini", sdk.NewInt(1))}
+	stakers := testStakingKeeper{sa.String(): sdk.NewInt(1050)}
+
+	testCases := []struct {
+		name     string
+		fn       mempool.PriorityFunc
+		expected int64
+	}{
+		{"min-coin", mempool.MinCoinPriority(), 100},
+		{"fee without base fee", mempool.FeePriority(nil), 100},
+		{"fee", mempool.FeePriority(keeper), 50},
+		{"ante", mempool.AntePriority(), 7},
+		{"gas price without base fee", mempool.GasPricePriority(nil, "mini"), 2 * mempool.GasPricePrecision},
+		{"gas price", mempool.GasPricePriority(keeper, "mini"), mempool.GasPricePrecision},
+		{"gas price of another denom", mempool.GasPricePriority(nil, "other"), 0},
+		{"sum", mempool.SumPriority(mempool.MinCoinPriority(), mempool.AntePriority()), 107},
+		{"sum saturates", mempool.SumPriority(mempool.ScalePriority(mempool.MinCoinPriority(), sdk.NewDec(math.MaxInt64)), mempool.AntePriority()), math.MaxInt64},
+		{"max", mempool.MaxPriority(mempool.FeePriority(keeper), mempool.AntePriority()), 50},
+		{"scale", mempool.ScalePriority(mempool.MinCoinPriority(), sdk.NewDecWithPrec(15, 1)), 150},
+		{"stake boost", mempool.StakeBoostPriority(mempool.StakeBoost{Keeper: stakers, TokensPerPriority: sdk.NewInt(100), MaxBoost: 100}), 10},
+		{"stake boost capped", mempool.StakeBoostPriority(mempool.StakeBoost{Keeper: stakers, TokensPerPriority: sdk.NewInt(1), MaxBoost: 100}), 100},
+		{"stake boost disabled", mempool.StakeBoostPriority(mempool.StakeBoost{Keeper: stakers, TokensPerPriority: sdk.ZeroInt(), MaxBoost: 100}), 0},
+		{"stake boost without stake", mempool.StakeBoostPriority(mempool.StakeBoost{Keeper: testStakingKeeper{sb.String(): sdk.NewInt(1050)}, TokensPerPriority: sdk.NewInt(100), MaxBoost: 100}), 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			priority, err := tc.fn(ctx, tx)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, priority)
+		})
+	}
+
+	// the stake of the fee payer boosts the txs, not the stake of their fee granter
+	boost := mempool.StakeBoostPriority(mempool.StakeBoost{Keeper: testStakingKeeper{sa.String(): sdk.NewInt(1050), sb.String(): sdk.NewInt(2050)}, TokensPerPriority: sdk.NewInt(100), MaxBoost: 100})
+	priority, err := boost(ctx, testTx{address: sa, granter: sb, priority: 100, gas: 50})
+	require.NoError(t, err)
+	require.Equal(t, int64(10), priority)
+
+	// zero-fee txs are not boosted
+	priority, err = boost(ctx, testTx{address: sa, granter: sb, priority: 0, gas: 50})
+	require.NoError(t, err)
+	require.Zero(t, priority)
+}
+
+func TestPriorityFuncsPlainContext(t *testing.T) {
+	accounts := simtypes.RandomAccounts(rand.New(rand.NewSource(0)), 1)
+	sa := accounts[0].Address
+
+	// a fee of 100 for 50 gas, i.e. a gas price of 2
+	tx := testTx{address: sa, priority: 100, gas: 50}
+	keeper := testBaseFeeKeeper{baseFee: sdk.NewDecCoin("mini", sdk.NewInt(1))}
+	stakers := testStakingKeeper{sa.String(): sdk.NewInt(1050)}
+
+	testCases := []struct {
+		name    string
+		fn      mempool.PriorityFunc
+		plain   int64
+		wrapped int64
+	}{
+		{"fee", mempool.FeePriority(keeper), 100, 50},
+		{"ante", mempool.AntePriority(), 0, 7},
+		{"gas price", mempool.GasPricePriority(keeper, "mini"), 2 * mempool.GasPricePrecision, mempool.GasPricePrecision},
+		{"stake boost", mempool.StakeBoostPriority(mempool.StakeBoost{Keeper: stakers, TokensPerPriority: sdk.NewInt(100), MaxBoost: 100}), 0, 10},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// without sdk.Context, the state dependent parts of the priority are skipped instead of panicking
+			priority, err := tc.fn(context.Background(), tx)
+			require.NoError(t, err)
+			require.Equal(t, tc.plain, priority)
+
+			// while an sdk.Context carried as a value (e.g. by the gRPC handlers) is used
+			priority, err = tc.fn(context.WithValue(context.Background(), sdk.SdkContextKey, sdk.Context{}.WithPriority(7)), tx)
+			require.NoError(t, err)
+			require.Equal(t, tc.wrapped, priority)
+		})
+	}
+}
+
+func TestMsgTypeWeightsPriority(t *testing.T) {
+	weights, err := mempool.NewMsgTypeWeights(mempool.WeightsConfig{
+		Weights: []string{"/cosmos.staking.v1beta1.MsgUndelegate=2", "/cosmos.bank.v1beta1.MsgSend=1"},
+	})
+	require.NoError(t, err)
+	fn := mempool.MsgTypeWeightsPriority(mempool.MinCoinPriority(), weights)
+
+	testCases := []struct {
+		name     string
+		mode     string
+		msgs     []sdk.Msg
+		expected int64
+	}{
+		{"no message", mempool.WeightModeMax, nil, 100},
+		{"weighted", mempool.WeightModeMax, []sdk.Msg{&stakingtypes.MsgUndelegate{}}, 200},
+		{"highest weight", mempool.WeightModeMax, []sdk.Msg{&banktypes.MsgSend{}, &stakingtypes.MsgUndelegate{}}, 200},
+		{"unweighted", mempool.WeightModeMax, []sdk.Msg{&banktypes.MsgMultiSend{}}, 100},
+		{"sum of weights", mempool.WeightModeSum, []sdk.Msg{&banktypes.MsgSend{}, &stakingtypes.MsgUndelegate{}}, 300},
+		{"sum of unweighted", mempool.WeightModeSum, []sdk.Msg{&banktypes.MsgMultiSend{}, &banktypes.MsgMultiSend{}}, 100},
+		{"sum padded with unweighted", mempool.WeightModeSum, []sdk.Msg{&stakingtypes.MsgUndelegate{}, &banktypes.MsgMultiSend{}, &banktypes.MsgMultiSend{}, &banktypes.MsgMultiSend{}}, 200},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.NoError(t, weights.Set(mempool.WeightsConfig{
+				Mode:    tc.mode,
+				Weights: []string{"/cosmos.staking.v1beta1.MsgUndelegate=2", "/cosmos.bank.v1beta1.MsgSend=1"},
+			}))
+
+			priority, err := fn(context.Background(), testTx{priority: 100, msgs: tc.msgs})
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, priority)
+		})
+	}
+}
+
+func TestNewPriorityFunc(t *testing.T) {
+	ctx := sdk.Context{}.WithPriority(7)
+	tx := testTx{priority: 100, gas: 50}
+
+	fn, err := mempool.NewPriorityFunc("", nil, "mini")
+	require.NoError(t, err)
+	priority, err := fn(ctx, tx)
+	require.NoError(t, err)
+	require.Equal(t, int64(100), priority)
+
+	fn, err = mempool.NewPriorityFunc("gas-price+ante", nil, "mini")
+	require.NoError(t, err)
+	priority, err = fn(ctx, tx)
+	require.NoError(t, err)
+	require.Equal(t, 2*mempool.GasPricePrecision+7, priority)
+
+	_, err = mempool.NewPriorityFunc("gas-price+unknown", nil, "mini")
+	require.Error(t, err)
+}
+
+// TestFeeMempoolStakeBoost checks that the stakers outrank the txs paying a slightly higher fee, but not the ones
+// paying more than the max boost.
+func TestFeeMempoolStakeBoost(t *testing.T) {
+	accounts := simtypes.RandomAccounts(rand.New(rand.NewSource(0)), 4)
+	whale, staker, sc, sd := accounts[0].Address, accounts[1].Address, accounts[2].Address, accounts[3].Address
+
+	boost := mempool.StakeBoost{
+		Keeper:            testStakingKeeper{whale.String(): sdk.NewInt(1_000_000_000), staker.String(): sdk.NewInt(2_000)},
+		TokensPerPriority: sdk.NewInt(100),
+		MaxBoost:          50,
+	}
+
+	pool, err := mempool.NewMempool(log.NewNopLogger(), mempool.Config{Type: mempool.TypeFee, StakeBoost: boost})
+	require.NoError(t, err)
+
+	txs := []testTx{
+		{id: 0, address: whale, priority: 10},  // 10 + 50 (capped)
+		{id: 1, address: staker, priority: 10}, // 10 + 20
+		{id: 2, address: sc, priority: 25},     // 25
+		{id: 3, address: sd, priority: 100},    // 100
+	}
+	for _, tx := range txs {
+		require.NoError(t, pool.Insert(sdk.Context{}, tx))
+	}
+
+	var txOrder []int
+	for it := pool.Select(context.Background(), nil); it != nil; it = it.Next() {
+		txOrder = append(txOrder, it.Tx().(testTx).id)
+	}
+	require.Equal(t, []int{3, 0, 1, 2}, txOrder)
+
+	// the boost must be capped, and is only supported by the fee mempool
+	boost.MaxBoost = 0
+	_, err = mempool.NewMempool(log.NewNopLogger(), mempool.Config{Type: mempool.TypeFee, StakeBoost: boost})
+	require.Error(t, err)
+
+	boost.MaxBoost = 50
+	_, err = mempool.NewMempool(log.NewNopLogger(), mempool.Config{Type: mempool.TypeSenderNonce, StakeBoost: boost})
+	require.Error(t, err)
+}
+
+func TestFeeMempoolReprioritize(t *testing.T) {
+	accounts := simtypes.RandomAccounts(rand.New(rand.NewSource(0)), 3)
+	sa, sb, sc := accounts[0].Address, accounts[1].Address, accounts[2].Address
+
+	weights, err := mempool.NewMsgTypeWeights(mempool.DefaultWeightsConfig())
+	require.NoError(t, err)
+
+	fn := mempool.MsgTypeWeightsPriority(mempool.SumPriority(mempool.MinCoinPriority(), mempool.AntePriority()), weights)
+	pool := mempool.NewFeeMempool(log.NewNopLogger(), mempool.FeeMempoolPriorityFuncOpt(fn))
+
+	txs := []testTx{
+		{id: 0, address: sa, priority: 100, msgs: []sdk.Msg{&banktypes.MsgSend{}}},
+		{id: 1, address: sb, priority: 60, msgs: []sdk.Msg{&stakingtypes.MsgUndelegate{}}},
+		{id: 2, address: sc, priority: 10, msgs: []sdk.Msg{&stakingtypes.MsgUndelegate{}}},
+	}
+	for i, tx := range txs {
+		// the last tx gets a priority from the ante handler
+		ctx := sdk.Context{}
+		if i == 2 {
+			ctx = ctx.WithPriority(100)
+		}
+		require.NoError(t, pool.Insert(ctx, tx))
+	}
+
+	txOrder := func() []int {
+		var order []int
+		for it := pool.Select(context.Background(), nil); it != nil; it = it.Next() {
+			order = append(order, it.Tx().(testTx).id)
+		}
+		return order
+	}
+	require.Equal(t, []int{2, 0, 1}, txOrder())
+
+	// the new weights only apply to the pooled txs once they are reprioritized
+	require.NoError(t, weights.Set(mempool.WeightsConfig{Weights: []string{"/cosmos.staking.v1beta1.MsgUndelegate=2"}}))
+	require.Equal(t, []int{2, 0, 1}, txOrder())
+
+	// the ante priority given at insertion is kept
+	require.Equal(t, 2, pool.Reprioritize(sdk.Context{}))
+	require.Equal(t, []int{2, 1, 0}, txOrder())
+	require.Equal(t, 0, pool.Reprioritize(sdk.Context{}))
+}
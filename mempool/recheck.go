@@ -0,0 +1,224 @@
+package mempool
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/cometbft/cometbft/libs/log"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/mempool"
+)
+
+// DefaultRecheckBudget is the default maximum number of transactions rechecked after each commit.
+var DefaultRecheckBudget = 1000
+
+// Rechecker re-validates the transactions of an app-side mempool against the latest committed state
+// and removes the ones that became invalid (e.g. consumed sequence or insufficient funds).
+// Transactions are rechecked sender by sender, in nonce order, the senders sharing a multi-signer tx together.
+// When a budget is set, only that many transactions are collected and rechecked per pass, the next pass resuming
+// from the sender where the previous one stopped. The transactions are walked through the sender index of the
+// mempool, so a pass never selects nor decodes the whole mempool.
+type Rechecker struct {
+	logger      log.Logger
+	mempool     *SenderIndexMempool
+	anteHandler sdk.AnteHandler
+	txEncoder   sdk.TxEncoder
+	budget      int
+
+	// cursor is the last sender rechecked during the previous pass
+	cursor string
+}
+
+// NewRechecker creates a new Rechecker for the given mempool.
+// A budget of 0 means that the whole mempool is rechecked on each pass.
+// The removals go through the given mempool, so they are recorded by a journal it wraps.
+func NewRechecker(logger log.Logger, mp *SenderIndexMempool, anteHandler sdk.AnteHandler, txEncoder sdk.TxEncoder, budget int) *Rechecker {
+	return &Rechecker{
+		logger:      logger.With("module", "mempool-recheck"),
+		mempool:     mp,
+		anteHandler: anteHandler,
+		txEncoder:   txEncoder,
+		budget:      budget,
+	}
+}
+
+type senderTxs struct {
+	sender string
+	txs    []recheckTx
+}
+
+type recheckTx struct {
+	signers []signerNonce
+	tx      sdk.Tx
+}
+
+// Recheck runs the ante handler in recheck mode over the pending transactions and removes the invalid ones.
+// Its cost grows with the number of rechecked transactions, which the budget bounds.
+// The given context must be a check context on top of the latest committed state. It is branched, so the
+// state changes made by the ante handler (e.g. sequence increments) are never written back.
+// It returns the number of rechecked and removed transactions.
+func (r *Rechecker) Recheck(ctx sdk.Context) (checked, removed int) {
+	start := time.Now()
+
+	// resume after the last sender rechecked during the previous pass, a sender being always rechecked fully
+	// so that its nonces stay ordered
+	senders := r.mempool.groups(r.cursor, r.budget)
+	if len(senders) == 0 {
+		r.cursor = ""
+		return 0, 0
+	}
+
+	recheckCtx, _ := ctx.WithIsReCheckTx(true).CacheContext()
+
+	var invalidTxs []sdk.Tx
+	for _, group := range senders {
+		for _, rtx := range group.txs {
+			checked++
+
+			if err := r.runAnte(recheckCtx, rtx.tx); err != nil {
+				r.logger.Debug("removing invalid transaction from mempool", "sender", rtx.signers[0].address, "nonce", rtx.signers[0].nonce, "err", err)
+				invalidTxs = append(invalidTxs, rtx.tx)
+			}
+		}
+
+		r.cursor = group.sender
+	}
+
+	for _, tx := range invalidTxs {
+		if err := r.mempool.Remove(tx); err != nil && !errors.Is(err, mempool.ErrTxNotFound) {
+			r.logger.Error("failed to remove invalid transaction from mempool", "err", err)
+			continue
+		}
+
+		removed++
+	}
+
+	r.logger.Info(fmt.Sprintf("rechecked %d transactions, removed %d invalid transactions", checked, removed), "duration", time.Since(start))
+	return checked, removed
+}
+
+// RecheckBundles removes the expired bundles of the given mempool, then runs the ante handler in recheck mode over
+// the transactions of the remaining ones, in order, and removes the bundles with an invalid transaction.
+// Each bundle is rechecked on its own branch of the given context, which must be a check context on top of
+// the latest committed state. It returns the number of rechecked and removed bundles.
+func (r *Rechecker) RecheckBundles(ctx sdk.Context, bm *BundleMempool) (checked, removed int) {
+	removed = bm.Expire(ctx.BlockHeight())
+
+	recheckCtx := ctx.WithIsReCheckTx(true)
+
+	var invalid []*Bundle
+	for _, bundle := range bm.Bundles() {
+		checked++
+
+		bundleCtx, _ := recheckCtx.CacheContext()
+		for i, tx := range bundle.Txs {
+			if _, err := r.anteHandler(bundleCtx.WithTxBytes(bundle.TxBytes[i]), tx, false); err != nil {
+				r.logger.Debug("removing invalid bundle from mempool", "id", bundle.ID, "tx", i, "err", err)
+				invalid = append(invalid, bundle)
+				break
+			}
+		}
+	}
+
+	for _, bundle := range invalid {
+		if err := bm.Remove(bundle.Txs[0]); err != nil && !errors.Is(err, mempool.ErrTxNotFound) {
+			r.logger.Error("failed to remove invalid bundle from mempool", "err", err)
+			continue
+		}
+
+		removed++
+	}
+
+	if checked > 0 || removed > 0 {
+		r.logger.Info(fmt.Sprintf("rechecked %d bundles, removed %d expired or invalid bundles", checked, removed))
+	}
+
+	return checked, removed
+}
+
+// runAnte runs the ante handler on a branch of the given context and writes the branch back on success.
+func (r *Rechecker) runAnte(ctx sdk.Context, tx sdk.Tx) error {
+	txBytes, err := r.txEncoder(tx)
+	if err != nil {
+		return err
+	}
+
+	txCtx, write := ctx.WithTxBytes(txBytes).CacheContext()
+	if _, err := r.anteHandler(txCtx, tx, false); err != nil {
+		return err
+	}
+
+	write()
+	return nil
+}
+
+// orderByNonce orders the transactions so that every tx comes after the lower nonces of all its signers.
+// The txs which cannot be ordered (e.g. a nonce shared by two txs) come last, by nonce of their first signer.
+func orderByNonce(txs []recheckTx) []recheckTx {
+	sort.SliceStable(txs, func(i, j int) bool { return txs[i].signers[0].nonce < txs[j].signers[0].nonce })
+
+	// the txs of every signer by nonce, and the next one to order
+	bySigner := make(map[string][]int)
+	for i, rtx := range txs {
+		for _, signer := range rtx.signers {
+			bySigner[signer.address] = append(bySigner[signer.address], i)
+		}
+	}
+	for address, indexes := range bySigner {
+		sort.SliceStable(indexes, func(i, j int) bool {
+			return nonceOf(txs[indexes[i]], address) < nonceOf(txs[indexes[j]], address)
+		})
+	}
+	next := make(map[string]int)
+
+	isNext := func(i int) bool {
+		for _, signer := range txs[i].signers {
+			indexes := bySigner[signer.address]
+			if next[signer.address] >= len(indexes) || indexes[next[signer.address]] != i {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	ordered := make([]recheckTx, 0, len(txs))
+	done := make([]bool, len(txs))
+	for progress := true; progress; {
+		progress = false
+		for i := range txs {
+			if done[i] || !isNext(i) {
+				continue
+			}
+
+			for _, signer := range txs[i].signers {
+				next[signer.address]++
+			}
+			ordered = append(ordered, txs[i])
+			done[i] = true
+			progress = true
+		}
+	}
+
+	for i, rtx := range txs {
+		if !done[i] {
+			ordered = append(ordered, rtx)
+		}
+	}
+
+	return ordered
+}
+
+// nonceOf returns the nonce of the given signer of the tx.
+func nonceOf(rtx recheckTx, address string) uint64 {
+	for _, signer := range rtx.signers {
+		if signer.address == address {
+			return signer.nonce
+		}
+	}
+
+	return 0
+}
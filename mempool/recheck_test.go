@@ -0,0 +1,190 @@
+package mempool_test
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/cometbft/cometbft/libs/log"
+	storetypes "github.com/cosmos/cosmos-sdk/store/types"
+	"github.com/cosmos/cosmos-sdk/testutil"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkmempool "github.com/cosmos/cosmos-sdk/types/mempool"
+	simtypes "github.com/cosmos/cosmos-sdk/types/simulation"
+	"github.com/stretchr/testify/require"
+
+	"github.com/julienrbrt/chain-minimal/mempool"
+)
+
+// sequenceAnteHandler is a minimal ante handler checking and incrementing the signer sequences stored under key.
+func sequenceAnteHandler(key storetypes.StoreKey) sdk.AnteHandler {
+	return func(ctx sdk.Context, tx sdk.Tx, _ bool) (sdk.Context, error) {
+		ttx := tx.(testTx)
+		store := ctx.KVStore(key)
+
+		signers := append([]cosigner{{address: ttx.address, nonce: ttx.nonce}}, ttx.cosigners...)
+		for _, signer := range signers {
+			var sequence uint64
+			if bz := store.Get(signer.address); bz != nil {
+				sequence = binary.BigEndian.Uint64(bz)
+			}
+
+			if signer.nonce != sequence {
+				return ctx, fmt.Errorf("account sequence mismatch, expected %d, got %d", sequence, signer.nonce)
+			}
+
+			store.Set(signer.address, binary.BigEndian.AppendUint64(nil, sequence+1))
+		}
+
+		return ctx, nil
+	}
+}
+
+func testTxEncoder(tx sdk.Tx) ([]byte, error) {
+	return []byte(tx.(testTx).String()), nil
+}
+
+func TestRecheck(t *testing.T) {
+	accounts := simtypes.RandomAccounts(rand.New(rand.NewSource(0)), 2)
+	sa := accounts[0].Address
+	sb := accounts[1].Address
+
+	key := storetypes.NewKVStoreKey("test")
+	ctx := testutil.DefaultContext(key, storetypes.NewTransientStoreKey("transient_test"))
+
+	// sa already consumed nonce 0 in the last block
+	ctx.KVStore(key).Set(sa, binary.BigEndian.AppendUint64(nil, 1))
+
+	pool := mempool.NewSenderIndexMempool(mempool.NewFeeMempool(log.TestingLogger()))
+	txs := []testTx{
+		{id: 0, address: sa, nonce: 0, priority: 10},
+		{id: 1, address: sa, nonce: 1, priority: 20},
+		{id: 2, address: sa, nonce: 2, priority: 30},
+		{id: 3, address: sb, nonce: 0, priority: 40},
+		{id: 4, address: sb, nonce: 2, priority: 50},
+	}
+	for _, tx := range txs {
+		require.NoError(t, pool.Insert(ctx, tx))
+	}
+
+	rechecker := mempool.NewRechecker(log.TestingLogger(), pool, sequenceAnteHandler(key), testTxEncoder, 0)
+	checked, removed := rechecker.Recheck(ctx)
+	require.Equal(t, 5, checked)
+	require.Equal(t, 2, removed)
+	require.Equal(t, 3, pool.CountTx())
+
+	var ids []int
+	for it := pool.Select(ctx, nil); it != nil; it = it.Next() {
+		ids = append(ids, it.Tx().(testTx).id)
+	}
+	require.ElementsMatch(t, []int{1, 2, 3}, ids)
+
+	// the recheck must not have modified the committed state
+	require.Equal(t, uint64(1), binary.BigEndian.Uint64(ctx.KVStore(key).Get(sa)))
+	require.Nil(t, ctx.KVStore(key).Get(sb))
+}
+
+func TestRecheckBudget(t *testing.T) {
+	accounts := simtypes.RandomAccounts(rand.New(rand.NewSource(0)), 3)
+
+	key := storetypes.NewKVStoreKey("test")
+	ctx := testutil.DefaultContext(key, storetypes.NewTransientStoreKey("transient_test"))
+
+	pool := mempool.NewSenderIndexMempool(mempool.NewFeeMempool(log.TestingLogger()))
+	for i, acc := range accounts {
+		// every sender has an invalid nonce
+		require.NoError(t, pool.Insert(ctx, testTx{id: i, address: acc.Address, nonce: 1}))
+		require.NoError(t, pool.Insert(ctx, testTx{id: i, address: acc.Address, nonce: 2}))
+	}
+
+	rechecker := mempool.NewRechecker(log.TestingLogger(), pool, sequenceAnteHandler(key), testTxEncoder, 3)
+
+	// senders are always rechecked fully, so only one sender fits in the budget
+	for i := len(accounts) - 1; i >= 0; i-- {
+		checked, removed := rechecker.Recheck(ctx)
+		require.Equal(t, 2, checked)
+		require.Equal(t, 2, removed)
+		require.Equal(t, i*2, pool.CountTx())
+	}
+}
+
+func TestRecheckMultiSigner(t *testing.T) {
+	accounts := simtypes.RandomAccounts(rand.New(rand.NewSource(0)), 3)
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].Address.String() < accounts[j].Address.String() })
+	sa := accounts[0].Address
+	sb := accounts[1].Address
+	sc := accounts[2].Address
+
+	key := storetypes.NewKVStoreKey("test")
+	ctx := testutil.DefaultContext(key, storetypes.NewTransientStoreKey("transient_test"))
+
+	pool := mempool.NewSenderIndexMempool(mempool.NewFeeMempool(log.TestingLogger()))
+	txs := []testTx{
+		// sa signs after the first tx of sb, although sa is rechecked first
+		{id: 0, address: sb, nonce: 0},
+		{id: 1, address: sa, nonce: 0, cosigners: []cosigner{{address: sb, nonce: 1}}},
+		{id: 2, address: sb, nonce: 2},
+		// sc signs twice
+		{id: 3, address: sc, nonce: 0, cosigners: []cosigner{{address: sc, nonce: 0}}},
+	}
+	for _, tx := range txs {
+		require.NoError(t, pool.Insert(ctx, tx))
+	}
+
+	// the txs sharing a signer are a single group, counted as a whole against the budget
+	rechecker := mempool.NewRechecker(log.TestingLogger(), pool, sequenceAnteHandler(key), testTxEncoder, 2)
+	checked, removed := rechecker.Recheck(ctx)
+	require.Equal(t, 3, checked)
+	require.Equal(t, 0, removed)
+
+	checked, removed = rechecker.Recheck(ctx)
+	require.Equal(t, 1, checked)
+	require.Equal(t, 1, removed)
+	require.Equal(t, 3, pool.CountTx())
+}
+
+// unselectableMempool is a mempool failing the test when it is selected.
+type unselectableMempool struct {
+	*mempool.FeeMempool
+	t *testing.T
+}
+
+func (mp unselectableMempool) Select(context.Context, [][]byte) sdkmempool.Iterator {
+	mp.t.Fatal("the recheck must not select the mempool")
+	return nil
+}
+
+func TestRecheckSenderIndex(t *testing.T) {
+	accounts := simtypes.RandomAccounts(rand.New(rand.NewSource(0)), 3)
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].Address.String() < accounts[j].Address.String() })
+
+	key := storetypes.NewKVStoreKey("test")
+	ctx := testutil.DefaultContext(key, storetypes.NewTransientStoreKey("transient_test"))
+
+	pool := mempool.NewSenderIndexMempool(unselectableMempool{mempool.NewFeeMempool(log.TestingLogger()), t})
+	for i, acc := range accounts {
+		require.NoError(t, pool.Insert(ctx, testTx{id: i, address: acc.Address, nonce: 1}))
+	}
+
+	// a tx removed from the mempool is dropped from the index, and is not rechecked
+	require.NoError(t, pool.Remove(testTx{id: 1, address: accounts[1].Address, nonce: 1}))
+
+	// each pass only walks the senders after the previous one
+	rechecker := mempool.NewRechecker(log.TestingLogger(), pool, sequenceAnteHandler(key), testTxEncoder, 1)
+	checked, removed := rechecker.Recheck(ctx)
+	require.Equal(t, 1, checked)
+	require.Equal(t, 1, removed)
+	require.Equal(t, 1, pool.CountTx())
+
+	checked, removed = rechecker.Recheck(ctx)
+	require.Equal(t, 1, checked)
+	require.Equal(t, 1, removed)
+	require.Equal(t, 0, pool.CountTx())
+
+	checked, removed = rechecker.Recheck(ctx)
+	require.Equal(t, 0, checked)
+	require.Equal(t, 0, removed)
+}
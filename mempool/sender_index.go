@@ -0,0 +1,223 @@
+package mempool
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/huandu/skiplist"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/mempool"
+)
+
+var _ mempool.Mempool = (*SenderIndexMempool)(nil)
+
+// SenderIndexMempool wraps a mempool and indexes its transactions by signer, ordered by address, so that the
+// Rechecker walks them sender by sender, from where its previous pass stopped, instead of selecting the whole mempool.
+//
+// A transaction replaces the indexed one with the same signers and sequences, as the mempools identify transactions by them.
+// NOTE: The transactions removed by the wrapped mempool on its own are only dropped from the index once rechecked.
+type SenderIndexMempool struct {
+	mempool.Mempool
+
+	mu sync.Mutex
+	// senders maps every signer address, in order, to the txs it signed
+	senders *skiplist.SkipList
+}
+
+// NewSenderIndexMempool creates a new SenderIndexMempool wrapping the given mempool.
+func NewSenderIndexMempool(mp mempool.Mempool) *SenderIndexMempool {
+	return &SenderIndexMempool{
+		Mempool: mp,
+		senders: skiplist.New(skiplist.String),
+	}
+}
+
+// Insert inserts the tx in the wrapped mempool and indexes it by all its signers once inserted.
+func (im *SenderIndexMempool) Insert(ctx context.Context, tx sdk.Tx) error {
+	signers, err := txSigners(tx)
+	if err != nil {
+		return err
+	}
+
+	if err := im.Mempool.Insert(ctx, tx); err != nil {
+		return err
+	}
+
+	im.mu.Lock()
+	defer im.mu.Unlock()
+
+	im.remove(signers)
+	rtx := &recheckTx{signers: signers, tx: tx}
+	for _, signer := range signers {
+		var txs []*recheckTx
+		if elem := im.senders.Get(signer.address); elem != nil {
+			txs = elem.Value.([]*recheckTx)
+		}
+
+		im.senders.Set(signer.address, append(txs, rtx))
+	}
+
+	return nil
+}
+
+// Remove removes the tx from the wrapped mempool and from the index, also when the wrapped mempool did not find it.
+func (im *SenderIndexMempool) Remove(tx sdk.Tx) error {
+	err := im.Mempool.Remove(tx)
+	if err != nil && !errors.Is(err, mempool.ErrTxNotFound) {
+		return err
+	}
+
+	signers, signersErr := txSigners(tx)
+	if signersErr != nil {
+		return signersErr
+	}
+
+	im.mu.Lock()
+	im.remove(signers)
+	im.mu.Unlock()
+
+	return err
+}
+
+// SizeBytes returns the encoded size of the transactions of the wrapped mempool, 0 when it does not keep track of it.
+func (im *SenderIndexMempool) SizeBytes() int64 {
+	return sizeBytes(im.Mempool)
+}
+
+// remove drops the indexed tx with the given signers and sequences, if any.
+func (im *SenderIndexMempool) remove(signers []signerNonce) {
+	for _, signer := range signers {
+		elem := im.senders.Get(signer.address)
+		if elem == nil {
+			continue
+		}
+
+		txs := elem.Value.([]*recheckTx)
+		for i, rtx := range txs {
+			if !sameSigners(rtx.signers, signers) {
+				continue
+			}
+
+			txs = append(txs[:i:i], txs[i+1:]...)
+			break
+		}
+
+		if len(txs) == 0 {
+			im.senders.RemoveElement(elem)
+		} else {
+			elem.Value = txs
+		}
+	}
+}
+
+// groups returns the indexed transactions grouped by sender, walking the senders in address order after the given
+// cursor and wrapping around, until the budget is spent (0 for no budget). Transactions sharing a signer are grouped
+// together, so that a multi-signer tx is rechecked after the lower nonces of all its signers. A group is identified by
+// its lowest signer address, and is returned when the walk reaches it. The first group is always returned whole.
+func (im *SenderIndexMempool) groups(cursor string, budget int) []senderTxs {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+
+	if im.senders.Len() == 0 {
+		return nil
+	}
+
+	// resume after the cursor
+	first := im.senders.Find(cursor)
+	if first != nil && first.Key().(string) == cursor {
+		first = first.Next()
+	}
+	if first == nil {
+		first = im.senders.Front()
+	}
+
+	var (
+		groups  []senderTxs
+		count   int
+		visited = make(map[string]bool)
+	)
+	for elem := first; ; {
+		sender := elem.Key().(string)
+		if !visited[sender] {
+			group, txs := im.group(sender, visited)
+
+			// the groups reached through a higher signer address are left to the walk of their lowest address
+			if group == sender {
+				if budget > 0 && count > 0 && count+len(txs) > budget {
+					break
+				}
+
+				groups = append(groups, senderTxs{sender: group, txs: orderByNonce(txs)})
+				count += len(txs)
+			}
+		}
+
+		if elem = elem.Next(); elem == nil {
+			elem = im.senders.Front()
+		}
+		if elem == first {
+			break
+		}
+	}
+
+	return groups
+}
+
+// group returns the lowest signer address and the transactions of the group of the given sender,
+// every signer address of the group being marked as visited.
+func (im *SenderIndexMempool) group(sender string, visited map[string]bool) (string, []recheckTx) {
+	var (
+		lowest = sender
+		txs    []recheckTx
+		seen   = make(map[*recheckTx]bool)
+		queue  = []string{sender}
+	)
+	visited[sender] = true
+
+	for len(queue) > 0 {
+		address := queue[0]
+		queue = queue[1:]
+		if address < lowest {
+			lowest = address
+		}
+
+		elem := im.senders.Get(address)
+		if elem == nil {
+			continue
+		}
+
+		for _, rtx := range elem.Value.([]*recheckTx) {
+			if seen[rtx] {
+				continue
+			}
+
+			seen[rtx] = true
+			txs = append(txs, *rtx)
+			for _, signer := range rtx.signers {
+				if !visited[signer.address] {
+					visited[signer.address] = true
+					queue = append(queue, signer.address)
+				}
+			}
+		}
+	}
+
+	return lowest, txs
+}
+
+// sameSigners reports whether both txs have the same signers with the same sequences, in the same order.
+func sameSigners(a, b []signerNonce) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -4,18 +4,17 @@ import (
 	"context"
 	crand "crypto/rand"
 	"encoding/binary"
-	"fmt"
 	"math/rand"
 
 	"github.com/huandu/skiplist"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/types/mempool"
-	"github.com/cosmos/cosmos-sdk/x/auth/signing"
 )
 
-// THIS IS COPIED FROM THE COSMOS-SDK REPO AS IS FOR REDABILITY IN THE WORKSHOP
+// THIS IS COPIED FROM THE COSMOS-SDK REPO FOR REDABILITY IN THE WORKSHOP
 // source: https://github.com/cosmos/cosmos-sdk/blob/release/v0.47.x/types/mempool/sender_nonce.go
+// It has been modified to index transactions under all their signers, instead of only the first one.
 
 var (
 	_ mempool.Mempool  = (*SenderNonceMempool)(nil)
@@ -32,27 +31,39 @@ var DefaultMaxTx = 0
 // 2) For each select iteration, randomly choose a sender and pick the next nonce ordered tx from their list
 // 3) Repeat 1,2 until the mempool is exhausted
 //
+// A transaction with several signers is indexed under every signer/nonce pair,
+// and is only selected once it is the next transaction of all its signers.
+//
 // Note that PrepareProposal could choose to stop iteration before reaching the
 // end if maxBytes is reached.
 type SenderNonceMempool struct {
 	senders    map[string]*skiplist.SkipList
 	rnd        *rand.Rand
 	maxTx      int
-	existingTx map[snmTxKey]bool
+	existingTx map[snmTxKey]*snmTx
+	txCount    int
+	txEncoder  sdk.TxEncoder
+	maxBytes   int64
+	sizeBytes  int64
 }
 
 type SenderNonceOptions func(*SenderNonceMempool)
 
-type snmTxKey struct {
-	address string
-	nonce   uint64
+type snmTxKey = signerNonce
+
+// snmTx is a transaction indexed under all its signers.
+type snmTx struct {
+	tx   sdk.Tx
+	keys []snmTxKey
+	// bytes is the encoded tx, nil when unknown
+	bytes []byte
 }
 
 // NewSenderNonceMempool creates a new mempool that prioritizes transactions by
 // nonce, the lowest first, picking a random sender on each iteration.
 func NewSenderNonceMempool(opts ...SenderNonceOptions) *SenderNonceMempool {
 	senderMap := make(map[string]*skiplist.SkipList)
-	existingTx := make(map[snmTxKey]bool)
+	existingTx := make(map[snmTxKey]*snmTx)
 	snp := &SenderNonceMempool{
 		senders:    senderMap,
 		maxTx:      DefaultMaxTx,
@@ -99,6 +110,30 @@ func SenderNonceMaxTxOpt(maxTx int) SenderNonceOptions {
 	}
 }
 
+// SenderNonceMaxBytesOpt Option To bound the total encoded size of the txs when calling the constructor
+// NewSenderNonceMempool. A value of 0 does not bound it.
+//
+// Example:
+//
+//	NewSenderNonceMempool(SenderNonceMaxBytesOpt(64 << 20))
+func SenderNonceMaxBytesOpt(maxBytes int64) SenderNonceOptions {
+	return func(snp *SenderNonceMempool) {
+		snp.maxBytes = maxBytes
+	}
+}
+
+// SenderNonceTxEncoderOpt Option To encode the txs inserted without tx bytes in their context (i.e. outside
+// of CheckTx) when calling the constructor NewSenderNonceMempool, so that their size is known.
+//
+// Example:
+//
+//	NewSenderNonceMempool(SenderNonceTxEncoderOpt(txConfig.TxEncoder()))
+func SenderNonceTxEncoderOpt(txEncoder sdk.TxEncoder) SenderNonceOptions {
+	return func(snp *SenderNonceMempool) {
+		snp.txEncoder = txEncoder
+	}
+}
+
 func (snm *SenderNonceMempool) setSeed(seed int64) {
 	s1 := rand.NewSource(seed)
 	snm.rnd = rand.New(s1) //#nosec // math/rand is seeded from crypto/rand by default
@@ -114,12 +149,13 @@ func (snm *SenderNonceMempool) NextSenderTx(sender string) sdk.Tx {
 	}
 
 	cursor := senderIndex.Front()
-	return cursor.Value.(sdk.Tx)
+	return cursor.Value.(*snmTx).tx
 }
 
-// Insert adds a tx to the mempool. It returns an error if the tx does not have
-// at least one signer. Note, priority is ignored.
-func (snm *SenderNonceMempool) Insert(_ context.Context, tx sdk.Tx) error {
+// Insert adds a tx to the mempool, under each of its signers. It returns an error if the tx does not have
+// at least one signer. A tx already in the mempool for any of the signer/nonce pairs is replaced.
+// Note, priority is ignored.
+func (snm *SenderNonceMempool) Insert(ctx context.Context, tx sdk.Tx) error {
 	if snm.maxTx > 0 && snm.CountTx() >= snm.maxTx {
 		return mempool.ErrMempoolTxMaxCapacity
 	}
@@ -127,28 +163,53 @@ func (snm *SenderNonceMempool) Insert(_ context.Context, tx sdk.Tx) error {
 		return nil
 	}
 
-	sigs, err := tx.(signing.SigVerifiableTx).GetSignaturesV2()
+	keys, err := txSigners(tx)
+	if err != nil {
+		return err
+	}
+
+	bz, err := txBytes(ctx, tx, snm.txEncoder)
 	if err != nil {
 		return err
 	}
-	if len(sigs) == 0 {
-		return fmt.Errorf("tx must have at least one signer")
+	size := int64(len(bz))
+
+	// the replaced txs do not count towards the max bytes
+	replaced := make(map[*snmTx]bool)
+	for _, key := range keys {
+		if existing, found := snm.existingTx[key]; found {
+			replaced[existing] = true
+		}
 	}
 
-	sig := sigs[0]
-	sender := sdk.AccAddress(sig.PubKey.Address()).String()
-	nonce := sig.Sequence
+	if snm.maxBytes > 0 {
+		sizeBytes := snm.sizeBytes + size
+		for existing := range replaced {
+			sizeBytes -= int64(len(existing.bytes))
+		}
 
-	senderTxs, found := snm.senders[sender]
-	if !found {
-		senderTxs = skiplist.New(skiplist.Uint64)
-		snm.senders[sender] = senderTxs
+		if sizeBytes > snm.maxBytes {
+			return mempool.ErrMempoolTxMaxCapacity
+		}
 	}
 
-	senderTxs.Set(nonce, tx)
+	for existing := range replaced {
+		snm.remove(existing)
+	}
 
-	key := snmTxKey{nonce: nonce, address: sender}
-	snm.existingTx[key] = true
+	stx := &snmTx{tx: tx, keys: keys, bytes: bz}
+	for _, key := range keys {
+		senderTxs, found := snm.senders[key.address]
+		if !found {
+			senderTxs = skiplist.New(skiplist.Uint64)
+			snm.senders[key.address] = senderTxs
+		}
+
+		senderTxs.Set(key.nonce, stx)
+		snm.existingTx[key] = stx
+	}
+	snm.txCount++
+	snm.sizeBytes += size
 
 	return nil
 }
@@ -156,9 +217,11 @@ func (snm *SenderNonceMempool) Insert(_ context.Context, tx sdk.Tx) error {
 // Select returns an iterator ordering transactions the mempool with the lowest
 // nonce of a random selected sender first.
 //
+// The iteration stops once the context is cancelled or past its deadline.
+//
 // NOTE: It is not safe to use this iterator while removing transactions from
 // the underlying mempool.
-func (snm *SenderNonceMempool) Select(_ context.Context, _ [][]byte) mempool.Iterator {
+func (snm *SenderNonceMempool) Select(ctx context.Context, _ [][]byte) mempool.Iterator {
 	var senders []string
 
 	senderCursors := make(map[string]*skiplist.Element)
@@ -178,6 +241,7 @@ func (snm *SenderNonceMempool) Select(_ context.Context, _ [][]byte) mempool.Ite
 	}
 
 	iter := &senderNonceMempoolIterator{
+		ctx:           ctx,
 		senders:       senders,
 		rnd:           snm.rnd,
 		senderCursors: senderCursors,
@@ -188,45 +252,56 @@ func (snm *SenderNonceMempool) Select(_ context.Context, _ [][]byte) mempool.Ite
 
 // CountTx returns the total count of txs in the mempool.
 func (snm *SenderNonceMempool) CountTx() int {
-	return len(snm.existingTx)
+	return snm.txCount
+}
+
+// SizeBytes returns the total encoded size of the txs in the mempool.
+func (snm *SenderNonceMempool) SizeBytes() int64 {
+	return snm.sizeBytes
 }
 
 // Remove removes a tx from the mempool. It returns an error if the tx does not
 // have at least one signer or the tx was not found in the pool.
 func (snm *SenderNonceMempool) Remove(tx sdk.Tx) error {
-	sigs, err := tx.(signing.SigVerifiableTx).GetSignaturesV2()
+	keys, err := txSigners(tx)
 	if err != nil {
 		return err
 	}
-	if len(sigs) == 0 {
-		return fmt.Errorf("tx must have at least one signer")
-	}
 
-	sig := sigs[0]
-	sender := sdk.AccAddress(sig.PubKey.Address()).String()
-	nonce := sig.Sequence
-
-	senderTxs, found := snm.senders[sender]
+	existing, found := snm.existingTx[keys[0]]
 	if !found {
 		return mempool.ErrTxNotFound
 	}
 
-	res := senderTxs.Remove(nonce)
-	if res == nil {
-		return mempool.ErrTxNotFound
-	}
+	snm.remove(existing)
 
-	if senderTxs.Len() == 0 {
-		delete(snm.senders, sender)
-	}
+	return nil
+}
 
-	key := snmTxKey{nonce: nonce, address: sender}
-	delete(snm.existingTx, key)
+// remove removes a tx from the mempool, under all its signers.
+func (snm *SenderNonceMempool) remove(stx *snmTx) {
+	for _, key := range stx.keys {
+		senderTxs, found := snm.senders[key.address]
+		if !found {
+			continue
+		}
 
-	return nil
+		senderTxs.Remove(key.nonce)
+		if senderTxs.Len() == 0 {
+			delete(snm.senders, key.address)
+		}
+
+		delete(snm.existingTx, key)
+	}
+
+	snm.txCount--
+	snm.sizeBytes -= int64(len(stx.bytes))
 }
 
+var _ SizedIterator = (*senderNonceMempoolIterator)(nil)
+
 type senderNonceMempoolIterator struct {
+	ctx           context.Context
 	rnd           *rand.Rand
 	currentTx     *skiplist.Element
 	senders       []string
@@ -234,24 +309,40 @@ type senderNonceMempoolIterator struct {
 }
 
 // Next returns the next iterator state which will contain a tx with the next
-// smallest nonce of a randomly selected sender.
+// smallest nonce of a randomly selected sender. A tx with several signers is only
+// returned once it is the next tx of all its signers, when it is not the case, the
+// following senders are tried in turn. The iteration stops when no tx can be returned, or once the context
+// of the selection is done.
 func (i *senderNonceMempoolIterator) Next() mempool.Iterator {
-	for len(i.senders) > 0 {
-		senderIndex := i.rnd.Intn(len(i.senders))
-		sender := i.senders[senderIndex]
+	if len(i.senders) == 0 || Done(i.ctx) {
+		return nil
+	}
+
+	start := i.rnd.Intn(len(i.senders))
+	for n := 0; n < len(i.senders); n++ {
+		sender := i.senders[(start+n)%len(i.senders)]
 		senderCursor, found := i.senderCursors[sender]
 		if !found {
-			i.senders = removeAtIndex(i.senders, senderIndex)
 			continue
 		}
 
-		if nextCursor := senderCursor.Next(); nextCursor != nil {
-			i.senderCursors[sender] = nextCursor
-		} else {
-			i.senders = removeAtIndex(i.senders, senderIndex)
+		stx := senderCursor.Value.(*snmTx)
+		if !i.isNext(stx) {
+			continue
+		}
+
+		// move the cursors of all the signers past the tx
+		for _, key := range stx.keys {
+			if nextCursor := i.senderCursors[key.address].Next(); nextCursor != nil {
+				i.senderCursors[key.address] = nextCursor
+			} else {
+				delete(i.senderCursors, key.address)
+				i.removeSender(key.address)
+			}
 		}
 
 		return &senderNonceMempoolIterator{
+			ctx:           i.ctx,
 			senders:       i.senders,
 			currentTx:     senderCursor,
 			rnd:           i.rnd,
@@ -262,8 +353,39 @@ func (i *senderNonceMempoolIterator) Next() mempool.Iterator {
 	return nil
 }
 
+// isNext returns whether the tx is the next tx of all its signers.
+func (i *senderNonceMempoolIterator) isNext(stx *snmTx) bool {
+	for _, key := range stx.keys {
+		cursor, found := i.senderCursors[key.address]
+		if !found || cursor.Value.(*snmTx) != stx {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (i *senderNonceMempoolIterator) removeSender(sender string) {
+	for idx, s := range i.senders {
+		if s == sender {
+			i.senders = removeAtIndex(i.senders, idx)
+			return
+		}
+	}
+}
+
+// Size returns the encoded size of the current tx, 0 when unknown.
+func (i *senderNonceMempoolIterator) Size() int64 {
+	return int64(len(i.currentTx.Value.(*snmTx).bytes))
+}
+
+// TxBytes returns the encoded current tx, nil when unknown.
+func (i *senderNonceMempoolIterator) TxBytes() []byte {
+	return i.currentTx.Value.(*snmTx).bytes
+}
+
 func (i *senderNonceMempoolIterator) Tx() sdk.Tx {
-	return i.currentTx.Value.(sdk.Tx)
+	return i.currentTx.Value.(*snmTx).tx
 }
 
 func removeAtIndex[T any](slice []T, index int) []T {
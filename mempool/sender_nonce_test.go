@@ -0,0 +1,105 @@
+package mempool_test
+
+import (
+	"math/rand"
+	"testing"
+
+	storetypes "github.com/cosmos/cosmos-sdk/store/types"
+	"github.com/cosmos/cosmos-sdk/testutil"
+	sdkmempool "github.com/cosmos/cosmos-sdk/types/mempool"
+	simtypes "github.com/cosmos/cosmos-sdk/types/simulation"
+	"github.com/stretchr/testify/require"
+
+	"github.com/julienrbrt/chain-minimal/mempool"
+)
+
+func TestSenderNonceMultiSigner(t *testing.T) {
+	accounts := simtypes.RandomAccounts(rand.New(rand.NewSource(0)), 3)
+	sa := accounts[0].Address
+	sb := accounts[1].Address
+	sc := accounts[2].Address
+
+	ctx := testutil.DefaultContext(storetypes.NewKVStoreKey("test"), storetypes.NewTransientStoreKey("transient_test"))
+
+	txs := []testTx{
+		{id: 0, address: sa, nonce: 0},
+		{id: 1, address: sb, nonce: 0},
+		{id: 2, address: sa, nonce: 1, cosigners: []cosigner{{address: sb, nonce: 1}}},
+		{id: 3, address: sb, nonce: 2},
+		{id: 4, address: sc, nonce: 0, cosigners: []cosigner{{address: sb, nonce: 3}, {address: sa, nonce: 2}}},
+	}
+
+	for seed := int64(0); seed < 20; seed++ {
+		pool := mempool.NewSenderNonceMempool(mempool.SenderNonceSeedOpt(seed))
+		for _, tx := range txs {
+			require.NoError(t, pool.Insert(ctx, tx))
+		}
+		require.Equal(t, len(txs), pool.CountTx())
+
+		// every tx is selected once, after the lower nonces of all its signers
+		position := make(map[int]int)
+		for it := pool.Select(ctx, nil); it != nil; it = it.Next() {
+			id := it.Tx().(testTx).id
+			require.NotContains(t, position, id)
+			position[id] = len(position)
+		}
+		require.Len(t, position, len(txs))
+		require.Less(t, position[0], position[2])
+		require.Less(t, position[1], position[2])
+		require.Less(t, position[2], position[3])
+		require.Less(t, position[3], position[4])
+	}
+}
+
+func TestSenderNonceMultiSignerRemove(t *testing.T) {
+	accounts := simtypes.RandomAccounts(rand.New(rand.NewSource(0)), 2)
+	sa := accounts[0].Address
+	sb := accounts[1].Address
+
+	ctx := testutil.DefaultContext(storetypes.NewKVStoreKey("test"), storetypes.NewTransientStoreKey("transient_test"))
+
+	pool := mempool.NewSenderNonceMempool(mempool.SenderNonceSeedOpt(0))
+	multi := testTx{id: 0, address: sa, nonce: 0, cosigners: []cosigner{{address: sb, nonce: 5}}}
+	require.NoError(t, pool.Insert(ctx, multi))
+	require.Equal(t, 1, pool.CountTx())
+	require.Equal(t, multi, pool.NextSenderTx(sb.String()))
+
+	// a tx using one of the signer nonces replaces the multi-signer tx under all its signers
+	replacement := testTx{id: 1, address: sb, nonce: 5}
+	require.NoError(t, pool.Insert(ctx, replacement))
+	require.Equal(t, 1, pool.CountTx())
+	require.Nil(t, pool.NextSenderTx(sa.String()))
+	require.ErrorIs(t, pool.Remove(multi), sdkmempool.ErrTxNotFound)
+
+	require.NoError(t, pool.Insert(ctx, testTx{id: 2, address: sa, nonce: 0}))
+	require.NoError(t, pool.Remove(replacement))
+	require.Equal(t, 1, pool.CountTx())
+	require.Nil(t, pool.NextSenderTx(sb.String()))
+}
+
+func TestSenderNonceDuplicateSigner(t *testing.T) {
+	accounts := simtypes.RandomAccounts(rand.New(rand.NewSource(0)), 1)
+	sa := accounts[0].Address
+
+	ctx := testutil.DefaultContext(storetypes.NewKVStoreKey("test"), storetypes.NewTransientStoreKey("transient_test"))
+
+	pool := mempool.NewSenderNonceMempool(mempool.SenderNonceSeedOpt(0))
+	txs := []testTx{
+		{id: 0, address: sa, nonce: 0, cosigners: []cosigner{{address: sa, nonce: 0}}},
+		{id: 1, address: sa, nonce: 1},
+	}
+	for _, tx := range txs {
+		require.NoError(t, pool.Insert(ctx, tx))
+	}
+	require.Equal(t, 2, pool.CountTx())
+
+	// a signer signing twice is indexed once, so that its next tx is not skipped
+	var ids []int
+	for it := pool.Select(ctx, nil); it != nil; it = it.Next() {
+		ids = append(ids, it.Tx().(testTx).id)
+	}
+	require.Equal(t, []int{0, 1}, ids)
+
+	require.NoError(t, pool.Remove(txs[0]))
+	require.Equal(t, txs[1], pool.NextSenderTx(sa.String()))
+}
@@ -0,0 +1,46 @@
+package mempool
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth/signing"
+)
+
+// signerNonce is a signer of a transaction with the sequence it signed with.
+type signerNonce struct {
+	address string
+	nonce   uint64
+}
+
+// txSigners returns every signer of the transaction with its sequence, in signature order.
+// A signer signing several times is only returned once, with the sequence of its first signature.
+// It returns an error if the tx does not have at least one signer.
+func txSigners(tx sdk.Tx) ([]signerNonce, error) {
+	sigTx, ok := tx.(signing.SigVerifiableTx)
+	if !ok {
+		return nil, fmt.Errorf("tx of type %T does not implement SigVerifiableTx", tx)
+	}
+
+	sigs, err := sigTx.GetSignaturesV2()
+	if err != nil {
+		return nil, err
+	}
+	if len(sigs) == 0 {
+		return nil, fmt.Errorf("tx must have at least one signer")
+	}
+
+	signers := make([]signerNonce, 0, len(sigs))
+	seen := make(map[string]bool, len(sigs))
+	for _, sig := range sigs {
+		address := sdk.AccAddress(sig.PubKey.Address()).String()
+		if seen[address] {
+			continue
+		}
+
+		seen[address] = true
+		signers = append(signers, signerNonce{address: address, nonce: sig.Sequence})
+	}
+
+	return signers, nil
+}
@@ -0,0 +1,339 @@
+package mempool
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/mempool"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+)
+
+// Fee distributions of a simulated workload.
+const (
+	FeeDistributionUniform     = "uniform"
+	FeeDistributionExponential = "exponential"
+	FeeDistributionBimodal     = "bimodal"
+)
+
+// simGasLimit is the gas limit of the simulated transactions.
+const simGasLimit = 200_000
+
+// SimConfig defines a synthetic workload and the blocks it is simulated over.
+type SimConfig struct {
+	// Senders is the number of senders.
+	Senders int
+	// Nonces is the length of the nonce chain of each sender, i.e. its number of transactions.
+	Nonces int
+	// FeeDistribution is the distribution of the fees between MinFee and MaxFee (uniform|exponential|bimodal).
+	FeeDistribution string
+	MinFee          int64
+	MaxFee          int64
+	// ArrivalRate is the mean number of transactions arriving per block (poisson distributed).
+	ArrivalRate float64
+	// BlockSize is the maximum number of transactions per block.
+	BlockSize int
+	// MaxBlocks is the maximum number of simulated blocks.
+	MaxBlocks int
+	// Denom is the fee denomination, IBC vouchers can be given by their full path (see ResolveDenom).
+	Denom string
+	// Seed is the seed of the workload generation.
+	Seed int64
+}
+
+// Validate validates the simulation configuration.
+func (cfg SimConfig) Validate() error {
+	if cfg.Senders <= 0 || cfg.Nonces <= 0 {
+		return fmt.Errorf("senders and nonces must be positive, got: %d and %d", cfg.Senders, cfg.Nonces)
+	}
+
+	if cfg.MinFee < 0 || cfg.MaxFee < cfg.MinFee {
+		return fmt.Errorf("invalid fee range, got: [%d, %d]", cfg.MinFee, cfg.MaxFee)
+	}
+
+	if cfg.ArrivalRate <= 0 || cfg.BlockSize <= 0 || cfg.MaxBlocks <= 0 {
+		return fmt.Errorf("arrival rate, block size and max blocks must be positive")
+	}
+
+	switch cfg.FeeDistribution {
+	case FeeDistributionUniform, FeeDistributionExponential, FeeDistributionBimodal:
+	default:
+		return fmt.Errorf("fee distribution not supported, got: %s, want %s|%s|%s", cfg.FeeDistribution, FeeDistributionUniform, FeeDistributionExponential, FeeDistributionBimodal)
+	}
+
+	_, err := ResolveDenom(cfg.Denom)
+	return err
+}
+
+// SimTx is a transaction of a simulated workload.
+type SimTx struct {
+	Tx      sdk.Tx
+	Sender  string
+	Nonce   uint64
+	Fee     int64
+	Arrival int
+}
+
+// GenerateWorkload generates the fake signed transactions of the workload, ordered by arrival.
+// The transactions of a sender arrive in nonce order, interleaved with the ones of the other senders.
+// Signatures only carry a public key and a sequence, which is all the mempools look at.
+func GenerateWorkload(cfg SimConfig, txConfig client.TxConfig) ([]SimTx, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	// the fees are paid in the denom of the coins on chain, the denom is valid once validated
+	cfg.Denom, _ = ResolveDenom(cfg.Denom)
+
+	r := rand.New(rand.NewSource(cfg.Seed)) //#nosec // deterministic workload
+
+	keys := make([]*secp256k1.PrivKey, cfg.Senders)
+	for i := range keys {
+		keys[i] = secp256k1.GenPrivKeyFromSecret([]byte(fmt.Sprintf("sender-%d", i)))
+	}
+
+	// interleave the nonce chains of the senders
+	senders := make([]int, 0, cfg.Senders*cfg.Nonces)
+	for i := 0; i < cfg.Senders; i++ {
+		for n := 0; n < cfg.Nonces; n++ {
+			senders = append(senders, i)
+		}
+	}
+	r.Shuffle(len(senders), func(i, j int) { senders[i], senders[j] = senders[j], senders[i] })
+
+	var (
+		workload = make([]SimTx, 0, len(senders))
+		nonces   = make([]uint64, cfg.Senders)
+		block    = 1
+		arrivals = poisson(r, cfg.ArrivalRate)
+	)
+
+	for _, sender := range senders {
+		for arrivals == 0 {
+			block++
+			arrivals = poisson(r, cfg.ArrivalRate)
+		}
+		arrivals--
+
+		pubKey := keys[sender].PubKey()
+		fee := simFee(r, cfg)
+
+		txBuilder := txConfig.NewTxBuilder()
+		if err := txBuilder.SetMsgs(banktypes.NewMsgSend(
+			sdk.AccAddress(pubKey.Address()),
+			sdk.AccAddress(keys[(sender+1)%cfg.Senders].PubKey().Address()),
+			sdk.NewCoins(sdk.NewInt64Coin(cfg.Denom, 1)),
+		)); err != nil {
+			return nil, err
+		}
+		txBuilder.SetGasLimit(simGasLimit)
+		txBuilder.SetFeeAmount(sdk.NewCoins(sdk.NewInt64Coin(cfg.Denom, fee)))
+		if err := txBuilder.SetSignatures(signing.SignatureV2{
+			PubKey:   pubKey,
+			Data:     &signing.SingleSignatureData{SignMode: signing.SignMode_SIGN_MODE_DIRECT},
+			Sequence: nonces[sender],
+		}); err != nil {
+			return nil, err
+		}
+
+		workload = append(workload, SimTx{
+			Tx:      txBuilder.GetTx(),
+			Sender:  sdk.AccAddress(pubKey.Address()).String(),
+			Nonce:   nonces[sender],
+			Fee:     fee,
+			Arrival: block,
+		})
+		nonces[sender]++
+	}
+
+	return workload, nil
+}
+
+// poisson returns a poisson distributed number of mean lambda (Knuth's algorithm).
+func poisson(r *rand.Rand, lambda float64) int {
+	l, k, p := math.Exp(-lambda), 0, 1.0
+	for {
+		p *= r.Float64()
+		if p <= l {
+			return k
+		}
+		k++
+	}
+}
+
+// simFee returns a random fee following the fee distribution of the configuration.
+func simFee(r *rand.Rand, cfg SimConfig) int64 {
+	feeRange := float64(cfg.MaxFee - cfg.MinFee)
+
+	var fee float64
+	switch cfg.FeeDistribution {
+	case FeeDistributionExponential:
+		// most fees are low, a few are high
+		fee = math.Min(r.ExpFloat64()*feeRange/4, feeRange)
+	case FeeDistributionBimodal:
+		// 80% of the fees are in the lowest tenth of the range, 20% in the highest one
+		fee = r.Float64() * feeRange / 10
+		if r.Float64() < 0.2 {
+			fee += feeRange * 9 / 10
+		}
+	default:
+		fee = r.Float64() * feeRange
+	}
+
+	return cfg.MinFee + int64(fee)
+}
+
+// SimBlock is the composition of a simulated block.
+type SimBlock struct {
+	Height  int   `json:"height"`
+	Txs     int   `json:"txs"`
+	Senders int   `json:"senders"`
+	Fees    int64 `json:"fees"`
+}
+
+// SimResult holds the metrics of a simulation.
+type SimResult struct {
+	Mempool string `json:"mempool"`
+	// Included is the number of transactions included in a block.
+	Included int `json:"included"`
+	// Pending is the number of transactions left in the mempool after the last block.
+	Pending int `json:"pending"`
+	// Rejected is the number of transactions the mempool refused to insert.
+	Rejected int `json:"rejected"`
+	// OutOfOrder is the number of times a transaction was selected before a lower nonce of its sender, thus skipped.
+	OutOfOrder int `json:"out_of_order"`
+	// FeeRevenue is the sum of the fees of the included transactions.
+	FeeRevenue int64 `json:"fee_revenue"`
+	// MeanLatency and MaxLatency are the number of blocks between the arrival and the inclusion of a transaction.
+	MeanLatency float64 `json:"mean_latency"`
+	MaxLatency  int     `json:"max_latency"`
+	// SenderLatency is the mean latency per sender.
+	SenderLatency map[string]float64 `json:"sender_latency"`
+	// Fairness is the Jain's fairness index of the mean latency of the senders, 1 being perfectly fair.
+	Fairness float64    `json:"fairness"`
+	Blocks   []SimBlock `json:"blocks"`
+}
+
+// Simulate feeds the workload into the mempool, block by block, and returns the resulting metrics.
+// Each block includes up to cfg.BlockSize transactions, in the order of the mempool. A transaction selected
+// before a lower nonce of its sender would fail, so it is skipped and kept in the mempool. With a no-op
+// mempool, blocks are built in arrival order, like the CometBFT FIFO mempool.
+func Simulate(name string, mp mempool.Mempool, workload []SimTx, cfg SimConfig) SimResult {
+	res := SimResult{Mempool: name, SenderLatency: make(map[string]float64)}
+
+	_, isNoOp := mp.(mempool.NoOpMempool)
+	_, isCountingNoOp := mp.(*CountingNoOpMempool)
+	isNoOp = isNoOp || isCountingNoOp
+
+	var (
+		byTx          = make(map[sdk.Tx]int, len(workload))
+		nextNonce     = make(map[string]uint64)
+		senderLatency = make(map[string][]int)
+		pending       []int // inserted txs not included yet, in arrival order
+		next          int
+		totalLatency  int
+	)
+	for i, stx := range workload {
+		byTx[stx.Tx] = i
+	}
+
+	for height := 1; height <= cfg.MaxBlocks; height++ {
+		ctx := sdk.Context{}.WithContext(context.Background()).WithBlockHeight(int64(height))
+
+		// insert the txs arriving before the block
+		for ; next < len(workload) && workload[next].Arrival <= height; next++ {
+			// the priority is the fee, as computed by the app TxFeeChecker with no base fee
+			if err := mp.Insert(ctx.WithPriority(workload[next].Fee), workload[next].Tx); err != nil {
+				res.Rejected++
+				continue
+			}
+
+			pending = append(pending, next)
+		}
+
+		var candidates []int
+		if isNoOp {
+			candidates = pending
+		} else {
+			for it := mp.Select(ctx, nil); it != nil; it = it.Next() {
+				candidates = append(candidates, byTx[it.Tx()])
+			}
+		}
+
+		block := SimBlock{Height: height}
+		senders := make(map[string]bool)
+		included := make(map[int]bool)
+		for _, i := range candidates {
+			if block.Txs >= cfg.BlockSize {
+				break
+			}
+
+			stx := workload[i]
+			if stx.Nonce != nextNonce[stx.Sender] {
+				res.OutOfOrder++
+				continue
+			}
+
+			nextNonce[stx.Sender]++
+			included[i] = true
+			senders[stx.Sender] = true
+			block.Txs++
+			block.Fees += stx.Fee
+
+			latency := height - stx.Arrival
+			senderLatency[stx.Sender] = append(senderLatency[stx.Sender], latency)
+			totalLatency += latency
+			if latency > res.MaxLatency {
+				res.MaxLatency = latency
+			}
+
+			_ = mp.Remove(stx.Tx)
+		}
+
+		remaining := pending[:0]
+		for _, i := range pending {
+			if !included[i] {
+				remaining = append(remaining, i)
+			}
+		}
+		pending = remaining
+
+		block.Senders = len(senders)
+		res.Included += block.Txs
+		res.FeeRevenue += block.Fees
+		res.Blocks = append(res.Blocks, block)
+
+		if next == len(workload) && len(pending) == 0 {
+			break
+		}
+	}
+
+	res.Pending = len(pending)
+	if res.Included > 0 {
+		res.MeanLatency = float64(totalLatency) / float64(res.Included)
+	}
+
+	// Jain's fairness index: (sum x)^2 / (n * sum x^2), with x the mean latency (+1 to count zero latencies) of a sender
+	var sum, sumSquares float64
+	for sender, latencies := range senderLatency {
+		var total int
+		for _, l := range latencies {
+			total += l
+		}
+
+		mean := float64(total) / float64(len(latencies))
+		res.SenderLatency[sender] = mean
+		sum += mean + 1
+		sumSquares += (mean + 1) * (mean + 1)
+	}
+	if len(senderLatency) > 0 {
+		res.Fairness = sum * sum / (float64(len(senderLatency)) * sumSquares)
+	}
+
+	return res
+}
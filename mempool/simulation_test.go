@@ -0,0 +1,66 @@
+package mempool_test
+
+import (
+	"testing"
+
+	"github.com/cometbft/cometbft/libs/log"
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	authtx "github.com/cosmos/cosmos-sdk/x/auth/tx"
+	"github.com/stretchr/testify/require"
+
+	"github.com/julienrbrt/chain-minimal/mempool"
+)
+
+func TestSimulate(t *testing.T) {
+	txConfig := authtx.NewTxConfig(codec.NewProtoCodec(codectypes.NewInterfaceRegistry()), authtx.DefaultSignModes)
+	cfg := mempool.SimConfig{
+		Senders:         10,
+		Nonces:          4,
+		FeeDistribution: mempool.FeeDistributionBimodal,
+		MinFee:          1,
+		MaxFee:          1000,
+		ArrivalRate:     10,
+		BlockSize:       5,
+		MaxBlocks:       100,
+		Denom:           "mini",
+		Seed:            1,
+	}
+
+	workload, err := mempool.GenerateWorkload(cfg, txConfig)
+	require.NoError(t, err)
+	require.Len(t, workload, 40)
+
+	// the workload is deterministic
+	again, err := mempool.GenerateWorkload(cfg, txConfig)
+	require.NoError(t, err)
+	for i := range workload {
+		require.Equal(t, workload[i].Sender, again[i].Sender)
+		require.Equal(t, workload[i].Fee, again[i].Fee)
+		require.Equal(t, workload[i].Arrival, again[i].Arrival)
+	}
+
+	for _, mempoolType := range []string{mempool.TypeFee, mempool.TypeSenderNonce, mempool.TypePriorityNonce, mempool.TypeNone} {
+		mp, err := mempool.NewMempool(log.NewNopLogger(), mempool.Config{Type: mempoolType, Seed: cfg.Seed})
+		require.NoError(t, err)
+
+		res := mempool.Simulate(mempoolType, mp, workload, cfg)
+		require.Equal(t, len(workload), res.Included, mempoolType)
+		require.Zero(t, res.Pending, mempoolType)
+		require.Len(t, res.SenderLatency, cfg.Senders, mempoolType)
+		require.Greater(t, res.Fairness, 0.0, mempoolType)
+		require.LessOrEqual(t, res.Fairness, 1.0, mempoolType)
+
+		// only the fee mempool ignores nonces
+		if mempoolType != mempool.TypeFee {
+			require.Zero(t, res.OutOfOrder, mempoolType)
+		}
+
+		for _, block := range res.Blocks {
+			require.LessOrEqual(t, block.Txs, cfg.BlockSize, mempoolType)
+		}
+	}
+
+	_, err = mempool.GenerateWorkload(mempool.SimConfig{}, txConfig)
+	require.Error(t, err)
+}
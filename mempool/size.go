@@ -0,0 +1,77 @@
+package mempool
+
+import (
+	"context"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/mempool"
+)
+
+// SizedMempool is a mempool keeping track of the encoded size of its transactions.
+type SizedMempool interface {
+	mempool.Mempool
+
+	// SizeBytes returns the total encoded size of the transactions in the mempool.
+	SizeBytes() int64
+}
+
+// SizedIterator is a mempool iterator exposing the encoded size and bytes of the current transaction, so that a
+// proposal handler can check that it fits in the block before verifying it, and include it without encoding it.
+type SizedIterator interface {
+	mempool.Iterator
+
+	// Size returns the encoded size of the current transaction, 0 when unknown.
+	Size() int64
+	// TxBytes returns the encoded bytes of the current transaction, nil when unknown.
+	TxBytes() []byte
+}
+
+var (
+	_ SizedMempool = (*FeeMempool)(nil)
+	_ SizedMempool = (*SenderNonceMempool)(nil)
+	_ SizedMempool = (*CountingNoOpMempool)(nil)
+	_ SizedMempool = (*BundleMempool)(nil)
+	_ SizedMempool = (*JournalMempool)(nil)
+)
+
+// txBytes returns the encoded bytes of the transaction: the transaction bytes of the context (set by BaseApp
+// during CheckTx), or else the transaction encoded with the given encoder. It returns nil when the bytes cannot
+// be known.
+func txBytes(ctx context.Context, tx sdk.Tx, txEncoder sdk.TxEncoder) ([]byte, error) {
+	if sdkCtx, ok := ctx.(sdk.Context); ok && len(sdkCtx.TxBytes()) > 0 {
+		return sdkCtx.TxBytes(), nil
+	}
+
+	if txEncoder == nil {
+		return nil, nil
+	}
+
+	return txEncoder(tx)
+}
+
+// sizeBytes returns the size of the mempool when it keeps track of it, 0 otherwise.
+func sizeBytes(mp mempool.Mempool) int64 {
+	if sized, ok := mp.(SizedMempool); ok {
+		return sized.SizeBytes()
+	}
+
+	return 0
+}
+
+// IteratorSize returns the encoded size of the current transaction of a mempool iterator, 0 when unknown.
+func IteratorSize(it mempool.Iterator) int64 {
+	if sized, ok := it.(SizedIterator); ok {
+		return sized.Size()
+	}
+
+	return 0
+}
+
+// IteratorTxBytes returns the encoded bytes of the current transaction of a mempool iterator, nil when unknown.
+func IteratorTxBytes(it mempool.Iterator) []byte {
+	if sized, ok := it.(SizedIterator); ok {
+		return sized.TxBytes()
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package mempool_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/cometbft/cometbft/libs/log"
+	storetypes "github.com/cosmos/cosmos-sdk/store/types"
+	"github.com/cosmos/cosmos-sdk/testutil"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkmempool "github.com/cosmos/cosmos-sdk/types/mempool"
+	simtypes "github.com/cosmos/cosmos-sdk/types/simulation"
+	"github.com/stretchr/testify/require"
+
+	"github.com/julienrbrt/chain-minimal/mempool"
+)
+
+func TestMempoolSizeBytes(t *testing.T) {
+	accounts := simtypes.RandomAccounts(rand.New(rand.NewSource(0)), 3)
+	sa, sb, sc := accounts[0].Address, accounts[1].Address, accounts[2].Address
+
+	for _, mempoolType := range []string{mempool.TypeFee, mempool.TypeSenderNonce} {
+		t.Run(mempoolType, func(t *testing.T) {
+			mp, err := mempool.NewMempool(log.NewNopLogger(), mempool.Config{Type: mempoolType, MaxBytes: 200, TxEncoder: testTxEncoder})
+			require.NoError(t, err)
+			pool := mp.(mempool.SizedMempool)
+
+			// the size is the one of the tx bytes of the context, or of the encoded tx
+			ctx := sdk.Context{}.WithTxBytes(make([]byte, 40))
+			require.NoError(t, pool.Insert(ctx, testTx{id: 0, address: sa, priority: 20}))
+			require.EqualValues(t, 40, pool.SizeBytes())
+
+			tx := testTx{id: 1, address: sb, priority: 10}
+			bz, _ := testTxEncoder(tx)
+			require.Less(t, len(bz), 160)
+			require.NoError(t, pool.Insert(sdk.Context{}, tx))
+			require.EqualValues(t, 40+len(bz), pool.SizeBytes())
+
+			// the mempool is bounded by its size
+			require.ErrorIs(t, pool.Insert(sdk.Context{}.WithTxBytes(make([]byte, 200-40-len(bz)+1)), testTx{id: 2, address: sc}), sdkmempool.ErrMempoolTxMaxCapacity)
+
+			// the iterators expose the size and the bytes of the txs
+			sizes := make(map[int]int64)
+			for it := pool.Select(sdk.Context{}, nil); it != nil; it = it.Next() {
+				sizes[it.Tx().(testTx).id] = mempool.IteratorSize(it)
+				if it.Tx().(testTx).id == 1 {
+					require.Equal(t, bz, mempool.IteratorTxBytes(it))
+				}
+			}
+			require.Equal(t, map[int]int64{0: 40, 1: int64(len(bz))}, sizes)
+
+			require.NoError(t, pool.Remove(tx))
+			require.EqualValues(t, 40, pool.SizeBytes())
+		})
+	}
+
+	_, err := mempool.NewMempool(log.NewNopLogger(), mempool.Config{Type: mempool.TypePriorityNonce, MaxBytes: 100})
+	require.Error(t, err)
+}
+
+func TestSenderNonceSizeBytesReplace(t *testing.T) {
+	accounts := simtypes.RandomAccounts(rand.New(rand.NewSource(0)), 1)
+	sa := accounts[0].Address
+
+	pool := mempool.NewSenderNonceMempool(mempool.SenderNonceMaxBytesOpt(100))
+	require.NoError(t, pool.Insert(sdk.Context{}.WithTxBytes(make([]byte, 60)), testTx{id: 0, address: sa}))
+
+	// a tx replacing another one only needs room for the difference
+	require.NoError(t, pool.Insert(sdk.Context{}.WithTxBytes(make([]byte, 90)), testTx{id: 1, address: sa}))
+	require.EqualValues(t, 90, pool.SizeBytes())
+	require.Equal(t, 1, pool.CountTx())
+}
+
+func TestWrappedMempoolSizeBytes(t *testing.T) {
+	accounts := simtypes.RandomAccounts(rand.New(rand.NewSource(0)), 2)
+	sa, sb := accounts[0].Address, accounts[1].Address
+	ctx := testutil.DefaultContext(storetypes.NewKVStoreKey("test"), storetypes.NewTransientStoreKey("transient_test")).WithBlockHeight(1)
+
+	noop := mempool.NewCountingNoOpMempool()
+	require.NoError(t, noop.Insert(ctx.WithTxBytes(make([]byte, 10)), testTx{address: sa, nonce: 0}))
+	require.NoError(t, noop.Insert(ctx.WithTxBytes(make([]byte, 20)), testTx{address: sa, nonce: 0}))
+	require.NoError(t, noop.Insert(ctx.WithTxBytes(make([]byte, 30)), testTx{address: sa, nonce: 1}))
+	require.EqualValues(t, 50, noop.SizeBytes())
+	require.NoError(t, noop.Remove(testTx{address: sa, nonce: 0}))
+	require.EqualValues(t, 30, noop.SizeBytes())
+
+	pool := mempool.NewBundleMempool(mempool.NewFeeMempool(log.NewNopLogger()), testTxEncoder, "mini", 10, 0)
+	require.NoError(t, pool.Insert(ctx.WithTxBytes(make([]byte, 10)), testTx{id: 0, address: sa, priority: 10, gas: 1}))
+
+	bundleTx := testTx{id: 1, address: sb, priority: 20, gas: 1}
+	bundle, err := pool.InsertBundle(ctx, []sdk.Tx{bundleTx})
+	require.NoError(t, err)
+	require.Equal(t, 10+bundle.Size(), pool.SizeBytes())
+
+	var sizes []int64
+	for it := pool.Select(ctx, nil); it != nil; it = it.Next() {
+		sizes = append(sizes, mempool.IteratorSize(it))
+	}
+	require.Equal(t, []int64{bundle.Size(), 10}, sizes)
+}
@@ -1,3 +1,36 @@
 package mempool
 
-const FlagMempoolType = "mempool-type"
+const (
+	FlagMempoolType   = "mempool-type"
+	FlagRecheckBudget = "mempool-recheck-budget"
+	FlagMaxBytes      = "mempool-max-bytes"
+
+	FlagMaxTxsPerPayer = "mempool-max-txs-per-payer"
+
+	FlagPrepareProposalBudget = "mempool-prepare-proposal-budget"
+
+	FlagPriorityStrategy = "mempool-priority-strategy"
+	FlagAgingCurve       = "mempool-aging-curve"
+	FlagAgingRate        = "mempool-aging-rate"
+	FlagJournal          = "mempool-journal"
+	FlagMaxBundles       = "mempool-max-bundles"
+	FlagBundleTTL        = "mempool-bundle-ttl"
+	FlagStakeBoostTokens = "mempool-stake-boost-tokens"
+	FlagStakeBoostMax    = "mempool-stake-boost-max"
+
+	// FlagWeightMode and FlagMsgTypeWeights are the app.toml keys of the message type weights (see WeightsConfig)
+	FlagWeightMode     = "mempool-weights.mode"
+	FlagMsgTypeWeights = "mempool-weights.weights"
+	// OptWeightsReloadOnSignal is the app option reloading the message type weights from app.toml on SIGHUP.
+	// It is not a flag: the signal handling is process-wide, so it is only set by the start command.
+	OptWeightsReloadOnSignal = "mempool-weights-reload-on-signal"
+
+	FlagMaxFreeTxs  = "mempool-max-free-txs"
+	FlagFreeTxSlots = "mempool-free-tx-slots"
+	FlagFreeTxGas   = "mempool-free-tx-gas"
+
+	FlagMinGasPricesCurve         = "mempool-min-gas-prices-curve"
+	FlagMinGasPricesLowOccupancy  = "mempool-min-gas-prices-low-occupancy"
+	FlagMinGasPricesHighOccupancy = "mempool-min-gas-prices-high-occupancy"
+	FlagMaxGasPrices              = "mempool-max-gas-prices"
+)
@@ -0,0 +1,211 @@
+package mempool
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+
+	"github.com/cometbft/cometbft/libs/log"
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Modes combining the weights of the messages of a transaction.
+const (
+	// WeightModeMax uses the highest weight of the messages.
+	WeightModeMax = "max"
+	// WeightModeSum sums the weights of the weighted messages, so that unweighted messages cannot multiply the priority.
+	WeightModeSum = "sum"
+)
+
+// WeightsConfig defines the message type weights section of the app configuration (app.toml).
+type WeightsConfig struct {
+	// Mode combines the weights of the messages of a transaction (max|sum).
+	Mode string `mapstructure:"mode"`
+	// Weights are the priority multipliers of the message types, as type_url=weight entries.
+	Weights []string `mapstructure:"weights"`
+}
+
+// DefaultWeightsConfig returns the default message type weights configuration, weighting no message type.
+func DefaultWeightsConfig() WeightsConfig {
+	return WeightsConfig{Mode: WeightModeMax, Weights: []string{}}
+}
+
+// WeightsConfigTemplate is the app.toml template of the WeightsConfig.
+const WeightsConfigTemplate = `
+###############################################################################
+###                      Mempool Message Type Weights                       ###
+###############################################################################
+
+[mempool-weights]
+
+# Mode combines the weights of the messages of a transaction (max|sum).
+mode = "{{ .MempoolWeights.Mode }}"
+
+# Weights multiply the priority of the transactions of the fee mempool, per message type URL.
+# Messages without weight have a weight of 1, and add nothing to the sum of the weights in sum mode.
+# The weights are reloaded on SIGHUP, and the pooled transactions are re-ranked at the next commit.
+# Example: weights = ["/cosmos.staking.v1beta1.MsgUndelegate=2", "/cosmos.bank.v1beta1.MsgSend=1"]
+weights = [{{ range $i, $w := .MempoolWeights.Weights }}{{ if $i }}, {{ end }}"{{ $w }}"{{ end }}]
+`
+
+// MsgTypeWeights holds the priority multipliers of message types. They can be updated concurrently with their use.
+type MsgTypeWeights struct {
+	mu      sync.RWMutex
+	mode    string
+	weights map[string]sdk.Dec
+	// version is incremented each time the weights are set
+	version uint64
+}
+
+// NewMsgTypeWeights creates message type weights from their configuration.
+func NewMsgTypeWeights(cfg WeightsConfig) (*MsgTypeWeights, error) {
+	w := &MsgTypeWeights{}
+	if err := w.Set(cfg); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// Set replaces the weights by the ones of the given configuration. The weights are left unchanged on error.
+func (w *MsgTypeWeights) Set(cfg WeightsConfig) error {
+	mode := cfg.Mode
+	if mode == "" {
+		mode = WeightModeMax
+	}
+
+	if mode != WeightModeMax && mode != WeightModeSum {
+		return fmt.Errorf("invalid weight mode, got: %s, want %s|%s", mode, WeightModeMax, WeightModeSum)
+	}
+
+	weights := make(map[string]sdk.Dec, len(cfg.Weights))
+	for _, entry := range cfg.Weights {
+		typeURL, weight, ok := strings.Cut(entry, "=")
+		typeURL = strings.TrimSpace(typeURL)
+		if !ok || !strings.HasPrefix(typeURL, "/") {
+			return fmt.Errorf("invalid message type weight, got: %s, want /type.url=weight", entry)
+		}
+
+		dec, err := sdk.NewDecFromStr(strings.TrimSpace(weight))
+		if err != nil || dec.IsNegative() {
+			return fmt.Errorf("invalid weight of %s, got: %s, want a non-negative decimal", typeURL, weight)
+		}
+
+		if _, ok := weights[typeURL]; ok {
+			return fmt.Errorf("duplicate weight of %s", typeURL)
+		}
+		weights[typeURL] = dec
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.mode = mode
+	w.weights = weights
+	w.version++
+
+	return nil
+}
+
+// Reload replaces the weights by the ones of the mempool-weights section of the given app.toml file.
+func (w *MsgTypeWeights) Reload(appConfigPath string) error {
+	v := viper.New()
+	v.SetConfigFile(appConfigPath)
+	if err := v.ReadInConfig(); err != nil {
+		return fmt.Errorf("failed to read %s: %w", appConfigPath, err)
+	}
+
+	return w.Set(WeightsConfig{
+		Mode:    cast.ToString(v.Get(FlagWeightMode)),
+		Weights: cast.ToStringSlice(v.Get(FlagMsgTypeWeights)),
+	})
+}
+
+// ReloadOnSignal reloads the weights from the given app.toml file each time the process receives one of the given
+// signals (e.g. SIGHUP), until the returned function is called. A failed reload keeps the previous weights.
+func (w *MsgTypeWeights) ReloadOnSignal(logger log.Logger, appConfigPath string, sigs ...os.Signal) (stop func()) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sigs...)
+
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ch:
+				if err := w.Reload(appConfigPath); err != nil {
+					logger.Error("failed to reload mempool message type weights", "err", err)
+					continue
+				}
+
+				logger.Info("reloaded mempool message type weights", "path", appConfigPath, "weights", w.Len())
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+}
+
+// Version returns the number of times the weights have been set, so that their users know when they are reloaded.
+func (w *MsgTypeWeights) Version() uint64 {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	return w.version
+}
+
+// Len returns the number of weighted message types.
+func (w *MsgTypeWeights) Len() int {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	return len(w.weights)
+}
+
+// Weight returns the weight of the given messages, 1 without message. In sum mode, only the weighted messages are
+// summed, and messages without any weighted one have a weight of 1.
+func (w *MsgTypeWeights) Weight(msgs []sdk.Msg) sdk.Dec {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if len(msgs) == 0 {
+		return sdk.OneDec()
+	}
+
+	weight, weighted := sdk.ZeroDec(), false
+	for _, msg := range msgs {
+		msgWeight, ok := w.weights[sdk.MsgTypeURL(msg)]
+		switch w.mode {
+		case WeightModeSum:
+			if ok {
+				weight, weighted = weight.Add(msgWeight), true
+			}
+		default:
+			if !ok {
+				msgWeight = sdk.OneDec()
+			}
+
+			if msgWeight.GT(weight) {
+				weight = msgWeight
+			}
+		}
+	}
+
+	if w.mode == WeightModeSum && !weighted {
+		return sdk.OneDec()
+	}
+
+	return weight
+}
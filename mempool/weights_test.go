@@ -0,0 +1,67 @@
+package mempool_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/julienrbrt/chain-minimal/mempool"
+)
+
+func TestMsgTypeWeightsSet(t *testing.T) {
+	weights, err := mempool.NewMsgTypeWeights(mempool.DefaultWeightsConfig())
+	require.NoError(t, err)
+	require.Equal(t, 0, weights.Len())
+
+	for _, cfg := range []mempool.WeightsConfig{
+		{Mode: "min"},
+		{Weights: []string{"cosmos.bank.v1beta1.MsgSend=2"}},
+		{Weights: []string{"/cosmos.bank.v1beta1.MsgSend"}},
+		{Weights: []string{"/cosmos.bank.v1beta1.MsgSend=-1"}},
+		{Weights: []string{"/cosmos.bank.v1beta1.MsgSend=1", "/cosmos.bank.v1beta1.MsgSend=2"}},
+	} {
+		require.Error(t, weights.Set(cfg), cfg)
+	}
+
+	// invalid weights leave the previous ones
+	require.NoError(t, weights.Set(mempool.WeightsConfig{Weights: []string{"/cosmos.bank.v1beta1.MsgSend=0.5"}}))
+	require.Error(t, weights.Set(mempool.WeightsConfig{Mode: "min"}))
+	require.Equal(t, sdk.NewDecWithPrec(5, 1), weights.Weight([]sdk.Msg{&banktypes.MsgSend{}}))
+}
+
+func TestMsgTypeWeightsReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.toml")
+	writeWeights := func(content string) {
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+
+	weights, err := mempool.NewMsgTypeWeights(mempool.DefaultWeightsConfig())
+	require.NoError(t, err)
+	version := weights.Version()
+
+	msgs := []sdk.Msg{&banktypes.MsgSend{}, &banktypes.MsgSend{}}
+	writeWeights(`
+[mempool-weights]
+mode = "sum"
+weights = ["/cosmos.bank.v1beta1.MsgSend=3"]
+`)
+	require.NoError(t, weights.Reload(path))
+	require.Equal(t, sdk.NewDec(6), weights.Weight(msgs))
+	require.Equal(t, version+1, weights.Version())
+
+	// an invalid configuration is not loaded
+	writeWeights(`
+[mempool-weights]
+mode = "min"
+`)
+	require.Error(t, weights.Reload(path))
+	require.Equal(t, sdk.NewDec(6), weights.Weight(msgs))
+	require.Equal(t, version+1, weights.Version())
+
+	// nor a missing file
+	require.Error(t, weights.Reload(filepath.Join(t.TempDir(), "missing.toml")))
+}
@@ -0,0 +1,24 @@
+#!/usr/bin/env bash
+
+# This script generates the Go code of the proto files of the mini chain.
+# It requires buf, protoc-gen-gocosmos, protoc-gen-grpc-gateway and protoc-gen-go.
+
+set -e
+
+cd proto
+
+echo "--> generating gogo proto code"
+for file in $(find ./mini -name '*.proto' -not -path '*/module/*'); do
+  buf generate --template buf.gen.gogo.yaml "$file"
+done
+
+echo "--> generating app config proto code"
+for file in $(find ./mini -path '*/module/*' -name '*.proto'); do
+  buf generate --template buf.gen.api.yaml "$file"
+done
+
+cd ..
+
+# move the generated files to the right places
+cp -r github.com/julienrbrt/chain-minimal/* ./
+rm -rf github.com
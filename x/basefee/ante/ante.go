@@ -0,0 +1,51 @@
+package ante
+
+import (
+	errorsmod "cosmossdk.io/errors"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+)
+
+// BaseFeeKeeper defines the expected basefee keeper.
+type BaseFeeKeeper interface {
+	GetBaseFee(ctx sdk.Context) sdk.DecCoin
+}
+
+// BaseFeeDecorator rejects transactions whose fee does not cover the current base fee for their gas limit.
+// Contrary to the node minimum gas prices, the base fee is part of the state and is enforced by every validator,
+// in CheckTx as well as in DeliverTx.
+type BaseFeeDecorator struct {
+	keeper BaseFeeKeeper
+}
+
+// NewBaseFeeDecorator creates a new BaseFeeDecorator.
+func NewBaseFeeDecorator(keeper BaseFeeKeeper) BaseFeeDecorator {
+	return BaseFeeDecorator{keeper: keeper}
+}
+
+// AnteHandle implements the sdk.AnteDecorator interface.
+func (d BaseFeeDecorator) AnteHandle(ctx sdk.Context, tx sdk.Tx, simulate bool, next sdk.AnteHandler) (sdk.Context, error) {
+	feeTx, ok := tx.(sdk.FeeTx)
+	if !ok {
+		return ctx, errorsmod.Wrap(sdkerrors.ErrTxDecode, "Tx must be a FeeTx")
+	}
+
+	// genesis transactions are not charged
+	if simulate || ctx.BlockHeight() == 0 {
+		return next(ctx, tx, simulate)
+	}
+
+	required := RequiredFee(d.keeper.GetBaseFee(ctx), feeTx.GetGas())
+	if fee := feeTx.GetFee(); !required.IsZero() && fee.AmountOf(required.Denom).LT(required.Amount) {
+		return ctx, errorsmod.Wrapf(sdkerrors.ErrInsufficientFee, "insufficient fee to cover the base fee; got: %s required: %s", fee, required)
+	}
+
+	return next(ctx, tx, simulate)
+}
+
+// RequiredFee returns the fee needed to cover the base fee for the given gas.
+func RequiredFee(baseFee sdk.DecCoin, gas uint64) sdk.Coin {
+	amount := baseFee.Amount.MulInt(sdk.NewIntFromUint64(gas)).Ceil().RoundInt()
+	return sdk.NewCoin(baseFee.Denom, amount)
+}
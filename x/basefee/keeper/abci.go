@@ -0,0 +1,26 @@
+package keeper
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/julienrbrt/chain-minimal/x/basefee/types"
+)
+
+// EndBlocker adjusts the base fee of the next block according to the gas used by the current block.
+func (k Keeper) EndBlocker(ctx sdk.Context) {
+	params := k.GetParams(ctx)
+	gasUsed := ctx.BlockGasMeter().GasConsumedToLimit()
+
+	baseFee := params.NextBaseFee(k.GetBaseFeeAmount(ctx), gasUsed)
+	k.SetBaseFeeAmount(ctx, baseFee)
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeBaseFee,
+			sdk.NewAttribute(types.AttributeKeyBaseFee, baseFee.String()),
+			sdk.NewAttribute(types.AttributeKeyGasUsed, fmt.Sprintf("%d", gasUsed)),
+		),
+	)
+}
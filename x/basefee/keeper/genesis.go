@@ -0,0 +1,21 @@
+package keeper
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/julienrbrt/chain-minimal/x/basefee/types"
+)
+
+// InitGenesis initializes the basefee module's state from a given genesis state.
+func (k Keeper) InitGenesis(ctx sdk.Context, genState *types.GenesisState) {
+	if err := k.SetParams(ctx, genState.Params); err != nil {
+		panic(err)
+	}
+
+	k.SetBaseFeeAmount(ctx, genState.BaseFee)
+}
+
+// ExportGenesis returns the basefee module's exported genesis.
+func (k Keeper) ExportGenesis(ctx sdk.Context) *types.GenesisState {
+	return types.NewGenesisState(k.GetParams(ctx), k.GetBaseFeeAmount(ctx))
+}
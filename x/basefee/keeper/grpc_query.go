@@ -0,0 +1,23 @@
+package keeper
+
+import (
+	"context"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/julienrbrt/chain-minimal/x/basefee/types"
+)
+
+var _ types.QueryServer = Keeper{}
+
+// Params returns the parameters of the basefee module.
+func (k Keeper) Params(goCtx context.Context, _ *types.QueryParamsRequest) (*types.QueryParamsResponse, error) {
+	ctx := sdk.UnwrapSDKContext(goCtx)
+	return &types.QueryParamsResponse{Params: k.GetParams(ctx)}, nil
+}
+
+// BaseFee returns the current base fee.
+func (k Keeper) BaseFee(goCtx context.Context, _ *types.QueryBaseFeeRequest) (*types.QueryBaseFeeResponse, error) {
+	ctx := sdk.UnwrapSDKContext(goCtx)
+	return &types.QueryBaseFeeResponse{BaseFee: k.GetBaseFee(ctx)}, nil
+}
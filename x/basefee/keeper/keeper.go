@@ -0,0 +1,91 @@
+package keeper
+
+import (
+	"github.com/cometbft/cometbft/libs/log"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	storetypes "github.com/cosmos/cosmos-sdk/store/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/julienrbrt/chain-minimal/x/basefee/types"
+)
+
+// Keeper of the basefee store
+type Keeper struct {
+	cdc      codec.BinaryCodec
+	storeKey storetypes.StoreKey
+
+	// the address capable of executing a MsgUpdateParams message. Typically, this
+	// should be the x/gov module account.
+	authority string
+}
+
+// NewKeeper creates a new basefee Keeper instance.
+func NewKeeper(cdc codec.BinaryCodec, storeKey storetypes.StoreKey, authority string) Keeper {
+	return Keeper{
+		cdc:       cdc,
+		storeKey:  storeKey,
+		authority: authority,
+	}
+}
+
+// GetAuthority returns the x/basefee module's authority.
+func (k Keeper) GetAuthority() string {
+	return k.authority
+}
+
+// Logger returns a module-specific logger.
+func (k Keeper) Logger(ctx sdk.Context) log.Logger {
+	return ctx.Logger().With("module", "x/"+types.ModuleName)
+}
+
+// GetParams returns the current x/basefee module parameters.
+func (k Keeper) GetParams(ctx sdk.Context) (params types.Params) {
+	bz := ctx.KVStore(k.storeKey).Get(types.ParamsKey)
+	if bz == nil {
+		return params
+	}
+
+	k.cdc.MustUnmarshal(bz, &params)
+	return params
+}
+
+// SetParams sets the x/basefee module parameters.
+func (k Keeper) SetParams(ctx sdk.Context, params types.Params) error {
+	if err := params.Validate(); err != nil {
+		return err
+	}
+
+	ctx.KVStore(k.storeKey).Set(types.ParamsKey, k.cdc.MustMarshal(&params))
+	return nil
+}
+
+// GetBaseFeeAmount returns the current base fee amount, per unit of gas.
+func (k Keeper) GetBaseFeeAmount(ctx sdk.Context) sdk.Dec {
+	bz := ctx.KVStore(k.storeKey).Get(types.BaseFeeKey)
+	if bz == nil {
+		return sdk.ZeroDec()
+	}
+
+	var baseFee sdk.Dec
+	if err := baseFee.Unmarshal(bz); err != nil {
+		panic(err)
+	}
+
+	return baseFee
+}
+
+// SetBaseFeeAmount sets the current base fee amount, per unit of gas.
+func (k Keeper) SetBaseFeeAmount(ctx sdk.Context, baseFee sdk.Dec) {
+	bz, err := baseFee.Marshal()
+	if err != nil {
+		panic(err)
+	}
+
+	ctx.KVStore(k.storeKey).Set(types.BaseFeeKey, bz)
+}
+
+// GetBaseFee returns the current base fee, per unit of gas, in the denomination set in the parameters.
+func (k Keeper) GetBaseFee(ctx sdk.Context) sdk.DecCoin {
+	return sdk.NewDecCoinFromDec(k.GetParams(ctx).Denom, k.GetBaseFeeAmount(ctx))
+}
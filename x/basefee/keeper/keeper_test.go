@@ -0,0 +1,95 @@
+package keeper_test
+
+import (
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	storetypes "github.com/cosmos/cosmos-sdk/store/types"
+	"github.com/cosmos/cosmos-sdk/testutil"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/julienrbrt/chain-minimal/x/basefee/keeper"
+	"github.com/julienrbrt/chain-minimal/x/basefee/types"
+)
+
+func newKeeper(t *testing.T, params types.Params, baseFee sdk.Dec) (keeper.Keeper, sdk.Context) {
+	t.Helper()
+
+	key := storetypes.NewKVStoreKey(types.StoreKey)
+	ctx := testutil.DefaultContext(key, storetypes.NewTransientStoreKey("transient_test"))
+	k := keeper.NewKeeper(codec.NewProtoCodec(codectypes.NewInterfaceRegistry()), key, authtypes.NewModuleAddress(govtypes.ModuleName).String())
+	k.InitGenesis(ctx, types.NewGenesisState(params, baseFee))
+
+	return k, ctx
+}
+
+// endBlockWithGas ends a block which used the given gas, and returns the base fee of the next block.
+func endBlockWithGas(k keeper.Keeper, ctx sdk.Context, gasUsed uint64) sdk.Dec {
+	meter := sdk.NewGasMeter(100_000_000)
+	meter.ConsumeGas(gasUsed, "block")
+
+	k.EndBlocker(ctx.WithBlockGasMeter(meter))
+	return k.GetBaseFeeAmount(ctx)
+}
+
+func TestEndBlockerTargetGas(t *testing.T) {
+	params := types.NewParams("mini", 1_000_000, sdk.NewDecWithPrec(125, 3), sdk.NewDecWithPrec(1, 4), sdk.NewDec(1_000))
+	k, ctx := newKeeper(t, params, sdk.NewDec(10))
+
+	// a block using the target keeps the base fee
+	require.Equal(t, sdk.NewDec(10).String(), endBlockWithGas(k, ctx, 1_000_000).String())
+
+	// a block above the target raises it, proportionally to the distance to the target
+	require.Equal(t, "10.625000000000000000", endBlockWithGas(k, ctx, 1_500_000).String())
+
+	// and a block below the target lowers it
+	require.Equal(t, "10.359375000000000000", endBlockWithGas(k, ctx, 800_000).String())
+
+	require.Equal(t, sdk.NewDecCoinFromDec("mini", sdk.MustNewDecFromStr("10.359375")), k.GetBaseFee(ctx))
+}
+
+func TestEndBlockerMaxChangeRate(t *testing.T) {
+	params := types.NewParams("mini", 1_000_000, sdk.NewDecWithPrec(5, 1), sdk.NewDecWithPrec(1, 4), sdk.NewDec(1_000))
+	k, ctx := newKeeper(t, params, sdk.NewDec(10))
+
+	// the change is the max change rate for a block using twice the target, and is not exceeded beyond
+	require.Equal(t, sdk.NewDec(15).String(), endBlockWithGas(k, ctx, 2_000_000).String())
+	require.Equal(t, sdk.MustNewDecFromStr("22.5").String(), endBlockWithGas(k, ctx, 10_000_000).String())
+
+	// an empty block lowers the base fee by the max change rate
+	require.Equal(t, sdk.MustNewDecFromStr("11.25").String(), endBlockWithGas(k, ctx, 0).String())
+}
+
+func TestEndBlockerBounds(t *testing.T) {
+	params := types.NewParams("mini", 1_000_000, sdk.NewDecWithPrec(125, 3), sdk.NewDec(1), sdk.NewDec(2))
+	k, ctx := newKeeper(t, params, sdk.NewDec(1))
+
+	// the base fee does not fall below the min base fee
+	require.Equal(t, sdk.NewDec(1).String(), endBlockWithGas(k, ctx, 0).String())
+
+	// nor rises above the max base fee
+	for i := 0; i < 10; i++ {
+		endBlockWithGas(k, ctx, 2_000_000)
+	}
+	require.Equal(t, sdk.NewDec(2).String(), k.GetBaseFeeAmount(ctx).String())
+
+	// a zero min base fee lets the base fee reach zero, from which it never rises
+	params.MinBaseFee = sdk.ZeroDec()
+	require.NoError(t, k.SetParams(ctx, params))
+	k.SetBaseFeeAmount(ctx, sdk.ZeroDec())
+	require.True(t, endBlockWithGas(k, ctx, 2_000_000).IsZero())
+}
+
+func TestDefaultParams(t *testing.T) {
+	params := types.DefaultParams()
+	require.Equal(t, "mini", params.Denom)
+	require.True(t, params.MinBaseFee.IsPositive())
+
+	// on a default chain, the base fee rises with full blocks
+	k, ctx := newKeeper(t, params, types.DefaultGenesisState().BaseFee)
+	require.True(t, endBlockWithGas(k, ctx, 2*params.TargetGas).GT(params.MinBaseFee))
+}
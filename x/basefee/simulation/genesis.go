@@ -0,0 +1,94 @@
+package simulation
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/module"
+	simtypes "github.com/cosmos/cosmos-sdk/types/simulation"
+
+	"github.com/julienrbrt/chain-minimal/x/basefee/types"
+)
+
+// Simulation parameter constants
+const (
+	TargetGas     = "target_gas"
+	MaxChangeRate = "max_change_rate"
+	MinBaseFee    = "min_base_fee"
+	MaxBaseFee    = "max_base_fee"
+	BaseFee       = "base_fee"
+)
+
+// MaxSimBaseFee is the highest simulated base fee. The simulated operations pay random fees of at least 1 in the
+// bond denom for the default gas limit (simtestutil.DefaultGenTxGas), which this base fee requires.
+var MaxSimBaseFee = sdk.NewDecWithPrec(1, 7)
+
+// GenTargetGas randomized TargetGas
+func GenTargetGas(r *rand.Rand) uint64 {
+	return uint64(simtypes.RandIntBetween(r, 1_000_000, 100_000_000))
+}
+
+// GenMaxChangeRate randomized MaxChangeRate, in (0, 1]
+func GenMaxChangeRate(r *rand.Rand) sdk.Dec {
+	return sdk.NewDecWithPrec(int64(simtypes.RandIntBetween(r, 1, 1001)), 3)
+}
+
+// GenMinBaseFee randomized MinBaseFee, up to MaxSimBaseFee
+func GenMinBaseFee(r *rand.Rand) sdk.Dec {
+	return simtypes.RandomDecAmount(r, MaxSimBaseFee)
+}
+
+// GenMaxBaseFee randomized MaxBaseFee, between the min base fee and MaxSimBaseFee
+func GenMaxBaseFee(r *rand.Rand, minBaseFee sdk.Dec) sdk.Dec {
+	return minBaseFee.Add(simtypes.RandomDecAmount(r, MaxSimBaseFee.Sub(minBaseFee)))
+}
+
+// GenBaseFee randomized BaseFee, between the min and max base fee
+func GenBaseFee(r *rand.Rand, minBaseFee, maxBaseFee sdk.Dec) sdk.Dec {
+	return minBaseFee.Add(simtypes.RandomDecAmount(r, maxBaseFee.Sub(minBaseFee)))
+}
+
+// RandomizedGenState generates a random GenesisState for basefee, in the default bond denom of the simulations.
+func RandomizedGenState(simState *module.SimulationState) {
+	var targetGas uint64
+	simState.AppParams.GetOrGenerate(
+		simState.Cdc, TargetGas, &targetGas, simState.Rand,
+		func(r *rand.Rand) { targetGas = GenTargetGas(r) },
+	)
+
+	var maxChangeRate sdk.Dec
+	simState.AppParams.GetOrGenerate(
+		simState.Cdc, MaxChangeRate, &maxChangeRate, simState.Rand,
+		func(r *rand.Rand) { maxChangeRate = GenMaxChangeRate(r) },
+	)
+
+	var minBaseFee sdk.Dec
+	simState.AppParams.GetOrGenerate(
+		simState.Cdc, MinBaseFee, &minBaseFee, simState.Rand,
+		func(r *rand.Rand) { minBaseFee = GenMinBaseFee(r) },
+	)
+
+	var maxBaseFee sdk.Dec
+	simState.AppParams.GetOrGenerate(
+		simState.Cdc, MaxBaseFee, &maxBaseFee, simState.Rand,
+		func(r *rand.Rand) { maxBaseFee = GenMaxBaseFee(r, minBaseFee) },
+	)
+
+	var baseFee sdk.Dec
+	simState.AppParams.GetOrGenerate(
+		simState.Cdc, BaseFee, &baseFee, simState.Rand,
+		func(r *rand.Rand) { baseFee = GenBaseFee(r, minBaseFee, maxBaseFee) },
+	)
+
+	params := types.NewParams(sdk.DefaultBondDenom, targetGas, maxChangeRate, minBaseFee, maxBaseFee)
+	baseFeeGenesis := types.NewGenesisState(params, baseFee)
+
+	bz, err := json.MarshalIndent(&baseFeeGenesis, "", " ")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("Selected randomly generated base fee parameters:\n%s\n", bz)
+	simState.GenState[types.ModuleName] = simState.Cdc.MustMarshalJSON(baseFeeGenesis)
+}
@@ -0,0 +1,48 @@
+package simulation_test
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+
+	sdkmath "cosmossdk.io/math"
+	simtestutil "github.com/cosmos/cosmos-sdk/testutil/sims"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/module"
+	moduletestutil "github.com/cosmos/cosmos-sdk/types/module/testutil"
+	simtypes "github.com/cosmos/cosmos-sdk/types/simulation"
+	"github.com/stretchr/testify/require"
+
+	"github.com/julienrbrt/chain-minimal/x/basefee"
+	"github.com/julienrbrt/chain-minimal/x/basefee/ante"
+	"github.com/julienrbrt/chain-minimal/x/basefee/simulation"
+	"github.com/julienrbrt/chain-minimal/x/basefee/types"
+)
+
+func TestRandomizedGenState(t *testing.T) {
+	encCfg := moduletestutil.MakeTestEncodingConfig(basefee.AppModuleBasic{})
+
+	for seed := int64(0); seed < 100; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		simState := module.SimulationState{
+			AppParams:    make(simtypes.AppParams),
+			Cdc:          encCfg.Codec,
+			Rand:         r,
+			NumBonded:    3,
+			Accounts:     simtypes.RandomAccounts(r, 3),
+			InitialStake: sdkmath.NewInt(1000),
+			GenState:     make(map[string]json.RawMessage),
+		}
+
+		simulation.RandomizedGenState(&simState)
+
+		var genState types.GenesisState
+		simState.Cdc.MustUnmarshalJSON(simState.GenState[types.ModuleName], &genState)
+		require.NoError(t, genState.Validate())
+		require.Equal(t, sdk.DefaultBondDenom, genState.Params.Denom)
+
+		// the random fees of the simulated operations always cover the highest base fee
+		maxBaseFee := sdk.NewDecCoinFromDec(genState.Params.Denom, genState.Params.MaxBaseFee)
+		require.True(t, ante.RequiredFee(maxBaseFee, simtestutil.DefaultGenTxGas).Amount.LTE(sdk.OneInt()))
+	}
+}
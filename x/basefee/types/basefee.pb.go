@@ -0,0 +1,515 @@
+// Code generated by protoc-gen-gogo. DO NOT EDIT.
+// source: mini/basefee/v1/basefee.proto
+
+package types
+
+import (
+	fmt "fmt"
+	_ "github.com/cosmos/cosmos-proto"
+	github_com_cosmos_cosmos_sdk_types "github.com/cosmos/cosmos-sdk/types"
+	_ "github.com/cosmos/cosmos-sdk/types/tx/amino"
+	_ "github.com/cosmos/gogoproto/gogoproto"
+	proto "github.com/cosmos/gogoproto/proto"
+	io "io"
+	math "math"
+	math_bits "math/bits"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
+
+// Params defines the parameters of the base fee adjustment.
+type Params struct {
+	// denom is the denomination in which the base fee must be paid.
+	Denom string `protobuf:"bytes,1,opt,name=denom,proto3" json:"denom,omitempty"`
+	// target_gas is the block gas usage for which the base fee stays the same.
+	// The base fee increases when a block uses more gas than the target and decreases otherwise.
+	// A target of 0 disables the base fee adjustment.
+	TargetGas uint64 `protobuf:"varint,2,opt,name=target_gas,json=targetGas,proto3" json:"target_gas,omitempty"`
+	// max_change_rate is the maximum relative change of the base fee between two blocks.
+	MaxChangeRate github_com_cosmos_cosmos_sdk_types.Dec `protobuf:"bytes,3,opt,name=max_change_rate,json=maxChangeRate,proto3,customtype=github.com/cosmos/cosmos-sdk/types.Dec" json:"max_change_rate"`
+	// min_base_fee is the lower bound of the base fee, per unit of gas.
+	MinBaseFee github_com_cosmos_cosmos_sdk_types.Dec `protobuf:"bytes,4,opt,name=min_base_fee,json=minBaseFee,proto3,customtype=github.com/cosmos/cosmos-sdk/types.Dec" json:"min_base_fee"`
+	// max_base_fee is the upper bound of the base fee, per unit of gas.
+	MaxBaseFee github_com_cosmos_cosmos_sdk_types.Dec `protobuf:"bytes,5,opt,name=max_base_fee,json=maxBaseFee,proto3,customtype=github.com/cosmos/cosmos-sdk/types.Dec" json:"max_base_fee"`
+}
+
+func (m *Params) Reset()         { *m = Params{} }
+func (m *Params) String() string { return proto.CompactTextString(m) }
+func (*Params) ProtoMessage()    {}
+func (*Params) Descriptor() ([]byte, []int) {
+	return fileDescriptor_315f86dc9ce673c2, []int{0}
+}
+func (m *Params) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *Params) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_Params.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *Params) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Params.Merge(m, src)
+}
+func (m *Params) XXX_Size() int {
+	return m.Size()
+}
+func (m *Params) XXX_DiscardUnknown() {
+	xxx_messageInfo_Params.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Params proto.InternalMessageInfo
+
+func (m *Params) GetDenom() string {
+	if m != nil {
+		return m.Denom
+	}
+	return ""
+}
+
+func (m *Params) GetTargetGas() uint64 {
+	if m != nil {
+		return m.TargetGas
+	}
+	return 0
+}
+
+func init() {
+	proto.RegisterType((*Params)(nil), "mini.basefee.v1.Params")
+}
+
+func init() { proto.RegisterFile("mini/basefee/v1/basefee.proto", fileDescriptor_315f86dc9ce673c2) }
+
+var fileDescriptor_315f86dc9ce673c2 = []byte{
+	// 359 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x91, 0x4f, 0x8b, 0xda, 0x40,
+	0x18, 0xc6, 0x93, 0xfa, 0x07, 0x1c, 0x5a, 0xa4, 0xc1, 0x42, 0x2a, 0x18, 0xa5, 0x87, 0x22, 0x82,
+	0x09, 0xe2, 0xad, 0xb7, 0x5a, 0x69, 0x4f, 0x85, 0x92, 0x63, 0x2f, 0xe1, 0x4d, 0x7c, 0x8d, 0xd3,
+	0x3a, 0x33, 0x92, 0x19, 0x25, 0xfb, 0x15, 0xf6, 0xb0, 0xec, 0xc7, 0xd8, 0xa3, 0x87, 0xfd, 0x10,
+	0x1e, 0x65, 0x4f, 0xcb, 0x1e, 0x64, 0xd1, 0x83, 0x5f, 0x63, 0x49, 0x26, 0xee, 0xfa, 0x01, 0xbc,
+	0x24, 0xef, 0xfb, 0x3e, 0x33, 0xef, 0xef, 0x61, 0x1e, 0xd2, 0x62, 0x94, 0x53, 0x2f, 0x04, 0x89,
+	0x53, 0x44, 0x6f, 0x35, 0x38, 0x95, 0xee, 0x22, 0x11, 0x4a, 0x58, 0xf5, 0x4c, 0x76, 0x4f, 0xb3,
+	0xd5, 0xa0, 0xd9, 0x88, 0x45, 0x2c, 0x72, 0xcd, 0xcb, 0x2a, 0x7d, 0xac, 0xf9, 0x39, 0x12, 0x92,
+	0x09, 0x19, 0x68, 0x41, 0x37, 0x85, 0xf4, 0x11, 0x18, 0xe5, 0xc2, 0xcb, 0xbf, 0x7a, 0xf4, 0xe5,
+	0xa6, 0x44, 0xaa, 0x7f, 0x20, 0x01, 0x26, 0xad, 0x06, 0xa9, 0x4c, 0x90, 0x0b, 0x66, 0x9b, 0x1d,
+	0xb3, 0x5b, 0xf3, 0x75, 0x63, 0xb5, 0x08, 0x51, 0x90, 0xc4, 0xa8, 0x82, 0x18, 0xa4, 0xfd, 0xae,
+	0x63, 0x76, 0xcb, 0x7e, 0x4d, 0x4f, 0x7e, 0x81, 0xb4, 0x28, 0xa9, 0x33, 0x48, 0x83, 0x68, 0x06,
+	0x3c, 0xc6, 0x20, 0x01, 0x85, 0x76, 0x29, 0xbb, 0x3e, 0xfa, 0xbe, 0xd9, 0xb5, 0x8d, 0xa7, 0x5d,
+	0xfb, 0x6b, 0x4c, 0xd5, 0x6c, 0x19, 0xba, 0x91, 0x60, 0x85, 0x99, 0xe2, 0xd7, 0x97, 0x93, 0xff,
+	0x9e, 0xba, 0x5a, 0xa0, 0x74, 0xc7, 0x18, 0x3d, 0xdc, 0xf7, 0x49, 0xe1, 0x75, 0x8c, 0xd1, 0xdd,
+	0x71, 0xdd, 0x33, 0xfd, 0x0f, 0x0c, 0xd2, 0x1f, 0xf9, 0x62, 0x1f, 0x14, 0x5a, 0x11, 0x79, 0xcf,
+	0x28, 0x0f, 0xb2, 0x07, 0x08, 0xa6, 0x88, 0x76, 0xf9, 0x52, 0x1c, 0xc2, 0x28, 0x1f, 0x81, 0xc4,
+	0x9f, 0xa8, 0x21, 0x90, 0xbe, 0x41, 0x2a, 0x97, 0x83, 0x40, 0x5a, 0x40, 0xbe, 0x35, 0xaf, 0x8f,
+	0xeb, 0xde, 0xa7, 0x3c, 0xed, 0xf4, 0x35, 0x6f, 0x9d, 0xc2, 0xe8, 0xf7, 0x66, 0xef, 0x98, 0xdb,
+	0xbd, 0x63, 0x3e, 0xef, 0x1d, 0xf3, 0xf6, 0xe0, 0x18, 0xdb, 0x83, 0x63, 0x3c, 0x1e, 0x1c, 0xe3,
+	0xef, 0xf0, 0x0c, 0xfe, 0x6f, 0x39, 0xa7, 0xc8, 0x93, 0x30, 0x51, 0x5e, 0x34, 0x03, 0xca, 0xfb,
+	0xd9, 0x32, 0x06, 0xf3, 0xb3, 0x7d, 0xb9, 0x9b, 0xb0, 0x9a, 0xc7, 0x3c, 0x7c, 0x19, 0x00, 0x46,
+	0xcc, 0x22, 0xd9, 0x5c, 0x02, 0x00, 0x00,
+}
+
+func (m *Params) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Params) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Params) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	{
+		size := m.MaxBaseFee.Size()
+		i -= size
+		if _, err := m.MaxBaseFee.MarshalTo(dAtA[i:]); err != nil {
+			return 0, err
+		}
+		i = encodeVarintBasefee(dAtA, i, uint64(size))
+	}
+	i--
+	dAtA[i] = 0x2a
+	{
+		size := m.MinBaseFee.Size()
+		i -= size
+		if _, err := m.MinBaseFee.MarshalTo(dAtA[i:]); err != nil {
+			return 0, err
+		}
+		i = encodeVarintBasefee(dAtA, i, uint64(size))
+	}
+	i--
+	dAtA[i] = 0x22
+	{
+		size := m.MaxChangeRate.Size()
+		i -= size
+		if _, err := m.MaxChangeRate.MarshalTo(dAtA[i:]); err != nil {
+			return 0, err
+		}
+		i = encodeVarintBasefee(dAtA, i, uint64(size))
+	}
+	i--
+	dAtA[i] = 0x1a
+	if m.TargetGas != 0 {
+		i = encodeVarintBasefee(dAtA, i, uint64(m.TargetGas))
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.Denom) > 0 {
+		i -= len(m.Denom)
+		copy(dAtA[i:], m.Denom)
+		i = encodeVarintBasefee(dAtA, i, uint64(len(m.Denom)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func encodeVarintBasefee(dAtA []byte, offset int, v uint64) int {
+	offset -= sovBasefee(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+func (m *Params) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Denom)
+	if l > 0 {
+		n += 1 + l + sovBasefee(uint64(l))
+	}
+	if m.TargetGas != 0 {
+		n += 1 + sovBasefee(uint64(m.TargetGas))
+	}
+	l = m.MaxChangeRate.Size()
+	n += 1 + l + sovBasefee(uint64(l))
+	l = m.MinBaseFee.Size()
+	n += 1 + l + sovBasefee(uint64(l))
+	l = m.MaxBaseFee.Size()
+	n += 1 + l + sovBasefee(uint64(l))
+	return n
+}
+
+func sovBasefee(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozBasefee(x uint64) (n int) {
+	return sovBasefee(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (m *Params) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowBasefee
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Params: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Params: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Denom", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowBasefee
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthBasefee
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthBasefee
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Denom = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TargetGas", wireType)
+			}
+			m.TargetGas = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowBasefee
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.TargetGas |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxChangeRate", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowBasefee
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthBasefee
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthBasefee
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.MaxChangeRate.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MinBaseFee", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowBasefee
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthBasefee
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthBasefee
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.MinBaseFee.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxBaseFee", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowBasefee
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthBasefee
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthBasefee
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.MaxBaseFee.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipBasefee(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthBasefee
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func skipBasefee(dAtA []byte) (n int, err error) {
+	l := len(dAtA)
+	iNdEx := 0
+	depth := 0
+	for iNdEx < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return 0, ErrIntOverflowBasefee
+			}
+			if iNdEx >= l {
+				return 0, io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		wireType := int(wire & 0x7)
+		switch wireType {
+		case 0:
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowBasefee
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				iNdEx++
+				if dAtA[iNdEx-1] < 0x80 {
+					break
+				}
+			}
+		case 1:
+			iNdEx += 8
+		case 2:
+			var length int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowBasefee
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				length |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if length < 0 {
+				return 0, ErrInvalidLengthBasefee
+			}
+			iNdEx += length
+		case 3:
+			depth++
+		case 4:
+			if depth == 0 {
+				return 0, ErrUnexpectedEndOfGroupBasefee
+			}
+			depth--
+		case 5:
+			iNdEx += 4
+		default:
+			return 0, fmt.Errorf("proto: illegal wireType %d", wireType)
+		}
+		if iNdEx < 0 {
+			return 0, ErrInvalidLengthBasefee
+		}
+		if depth == 0 {
+			return iNdEx, nil
+		}
+	}
+	return 0, io.ErrUnexpectedEOF
+}
+
+var (
+	ErrInvalidLengthBasefee        = fmt.Errorf("proto: negative length found during unmarshaling")
+	ErrIntOverflowBasefee          = fmt.Errorf("proto: integer overflow")
+	ErrUnexpectedEndOfGroupBasefee = fmt.Errorf("proto: unexpected end of group")
+)
@@ -0,0 +1,10 @@
+package types
+
+import (
+	errorsmod "cosmossdk.io/errors"
+)
+
+// x/basefee module sentinel errors
+var (
+	ErrInvalidSigner = errorsmod.Register(ModuleName, 2, "expected authority account as only signer for proposal message")
+)
@@ -0,0 +1,33 @@
+package types
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// NewGenesisState creates a new genesis state.
+func NewGenesisState(params Params, baseFee sdk.Dec) *GenesisState {
+	return &GenesisState{
+		Params:  params,
+		BaseFee: baseFee,
+	}
+}
+
+// DefaultGenesisState returns a default genesis state.
+func DefaultGenesisState() *GenesisState {
+	return NewGenesisState(DefaultParams(), DefaultMinBaseFee)
+}
+
+// Validate performs basic genesis state validation.
+func (gs GenesisState) Validate() error {
+	if err := gs.Params.Validate(); err != nil {
+		return err
+	}
+
+	if gs.BaseFee.IsNil() || gs.BaseFee.LT(gs.Params.MinBaseFee) || gs.BaseFee.GT(gs.Params.MaxBaseFee) {
+		return fmt.Errorf("base fee must be between %s and %s, got %s", gs.Params.MinBaseFee, gs.Params.MaxBaseFee, gs.BaseFee)
+	}
+
+	return nil
+}
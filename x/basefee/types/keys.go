@@ -0,0 +1,17 @@
+package types
+
+const (
+	// ModuleName defines the module name
+	ModuleName = "basefee"
+
+	// StoreKey defines the primary module store key
+	StoreKey = ModuleName
+)
+
+var (
+	// ParamsKey is the key of the module parameters
+	ParamsKey = []byte{0x01}
+
+	// BaseFeeKey is the key of the current base fee
+	BaseFeeKey = []byte{0x02}
+)
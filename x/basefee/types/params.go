@@ -0,0 +1,88 @@
+package types
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/julienrbrt/chain-minimal/app/params"
+)
+
+const (
+	// DefaultTargetGas is the default block gas usage for which the base fee stays the same.
+	DefaultTargetGas uint64 = 10_000_000
+)
+
+var (
+	// DefaultMaxChangeRate is the default maximum change of the base fee between two blocks (12.5%, as in EIP-1559).
+	DefaultMaxChangeRate = sdk.NewDecWithPrec(125, 3)
+	// DefaultMinBaseFee is the default minimum base fee (i.e. 20mini for 200,000 gas).
+	// NOTE, with a minimum of zero, a zero base fee never increases.
+	DefaultMinBaseFee = sdk.NewDecWithPrec(1, 4)
+	// DefaultMaxBaseFee is the default maximum base fee.
+	DefaultMaxBaseFee = sdk.NewDec(1_000)
+)
+
+// NewParams creates a new Params instance.
+func NewParams(denom string, targetGas uint64, maxChangeRate, minBaseFee, maxBaseFee sdk.Dec) Params {
+	return Params{
+		Denom:         denom,
+		TargetGas:     targetGas,
+		MaxChangeRate: maxChangeRate,
+		MinBaseFee:    minBaseFee,
+		MaxBaseFee:    maxBaseFee,
+	}
+}
+
+// DefaultParams returns the default parameters of the basefee module, in the fee denom of the chain.
+func DefaultParams() Params {
+	return NewParams(params.DefaultBondDenom, DefaultTargetGas, DefaultMaxChangeRate, DefaultMinBaseFee, DefaultMaxBaseFee)
+}
+
+// Validate validates the parameters.
+func (p Params) Validate() error {
+	if err := sdk.ValidateDenom(p.Denom); err != nil {
+		return err
+	}
+
+	if p.MaxChangeRate.IsNil() || !p.MaxChangeRate.IsPositive() || p.MaxChangeRate.GT(sdk.OneDec()) {
+		return fmt.Errorf("max change rate must be in (0, 1], got %s", p.MaxChangeRate)
+	}
+
+	if p.MinBaseFee.IsNil() || p.MinBaseFee.IsNegative() {
+		return fmt.Errorf("min base fee cannot be negative, got %s", p.MinBaseFee)
+	}
+
+	if p.MaxBaseFee.IsNil() || p.MaxBaseFee.LT(p.MinBaseFee) {
+		return fmt.Errorf("max base fee must be greater or equal to min base fee (%s), got %s", p.MinBaseFee, p.MaxBaseFee)
+	}
+
+	return nil
+}
+
+// NextBaseFee returns the base fee of the next block given the current base fee and the gas used by the current block.
+// As in EIP-1559, the base fee changes proportionally to the distance between the gas used and the target gas,
+// the maximum change being reached when a block uses twice the target. The result is bounded by the min and max base fee.
+func (p Params) NextBaseFee(baseFee sdk.Dec, gasUsed uint64) sdk.Dec {
+	if p.TargetGas > 0 {
+		target := sdk.NewDecFromInt(sdk.NewIntFromUint64(p.TargetGas))
+		delta := sdk.NewDecFromInt(sdk.NewIntFromUint64(gasUsed)).Sub(target).Quo(target)
+
+		// bound the change when the gas used is more than twice the target
+		if delta.GT(sdk.OneDec()) {
+			delta = sdk.OneDec()
+		}
+
+		baseFee = baseFee.Add(baseFee.Mul(p.MaxChangeRate).Mul(delta))
+	}
+
+	if baseFee.LT(p.MinBaseFee) {
+		return p.MinBaseFee
+	}
+
+	if baseFee.GT(p.MaxBaseFee) {
+		return p.MaxBaseFee
+	}
+
+	return baseFee
+}
@@ -0,0 +1,50 @@
+package types_test
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/julienrbrt/chain-minimal/x/basefee/types"
+)
+
+func TestNextBaseFee(t *testing.T) {
+	params := types.NewParams("mini", 1000, sdk.NewDecWithPrec(125, 3), sdk.NewDec(1), sdk.NewDec(20))
+
+	tests := []struct {
+		name     string
+		baseFee  sdk.Dec
+		gasUsed  uint64
+		expected sdk.Dec
+	}{
+		{"target reached", sdk.NewDec(10), 1000, sdk.NewDec(10)},
+		{"full block", sdk.NewDec(10), 2000, sdk.NewDecWithPrec(1125, 2)},
+		{"more than twice the target", sdk.NewDec(10), 5000, sdk.NewDecWithPrec(1125, 2)},
+		{"half the target", sdk.NewDec(10), 500, sdk.NewDecWithPrec(9375, 3)},
+		{"empty block", sdk.NewDec(10), 0, sdk.NewDecWithPrec(875, 2)},
+		{"bounded by min", sdk.NewDec(1), 0, sdk.NewDec(1)},
+		{"bounded by max", sdk.NewDec(19), 2000, sdk.NewDec(20)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected.String(), params.NextBaseFee(tt.baseFee, tt.gasUsed).String())
+		})
+	}
+
+	// a target of 0 disables the adjustment
+	params.TargetGas = 0
+	require.Equal(t, sdk.NewDec(10).String(), params.NextBaseFee(sdk.NewDec(10), 5000).String())
+}
+
+func TestGenesisValidate(t *testing.T) {
+	require.NoError(t, types.DefaultGenesisState().Validate())
+
+	params := types.DefaultParams()
+	params.MaxChangeRate = sdk.NewDec(2)
+	require.Error(t, types.NewGenesisState(params, sdk.ZeroDec()).Validate())
+
+	params = types.DefaultParams()
+	require.Error(t, types.NewGenesisState(params, params.MaxBaseFee.Add(sdk.OneDec())).Validate())
+}
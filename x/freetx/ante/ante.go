@@ -0,0 +1,84 @@
+package ante
+
+import (
+	errorsmod "cosmossdk.io/errors"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+
+	"github.com/julienrbrt/chain-minimal/x/freetx/types"
+)
+
+// FreeTxKeeper defines the expected freetx keeper.
+type FreeTxKeeper interface {
+	GetParams(ctx sdk.Context) types.Params
+	UseFreeTx(ctx sdk.Context, addr sdk.AccAddress) error
+}
+
+type freeTxKey struct{}
+
+// IsFreeTx returns whether the transaction of the context has been accepted as a free transaction by the FreeTxDecorator.
+func IsFreeTx(ctx sdk.Context) bool {
+	isFree, _ := ctx.Value(freeTxKey{}).(bool)
+	return isFree
+}
+
+// FreeTxDecorator accepts the transactions paying no fee within the allowance of their fee payer, and rejects
+// the ones exceeding it. The allowance is part of the state, so it is enforced by every validator, in CheckTx as
+// well as in DeliverTx. The accepted transactions are flagged in the context (see IsFreeTx), so that the fee
+// checks placed after this decorator can skip them (see SkipFreeTx).
+// When free transactions are disabled, transactions paying no fee are subject to the usual fee checks.
+type FreeTxDecorator struct {
+	keeper FreeTxKeeper
+}
+
+// NewFreeTxDecorator creates a new FreeTxDecorator.
+func NewFreeTxDecorator(keeper FreeTxKeeper) FreeTxDecorator {
+	return FreeTxDecorator{keeper: keeper}
+}
+
+// AnteHandle implements the sdk.AnteDecorator interface.
+func (d FreeTxDecorator) AnteHandle(ctx sdk.Context, tx sdk.Tx, simulate bool, next sdk.AnteHandler) (sdk.Context, error) {
+	feeTx, ok := tx.(sdk.FeeTx)
+	if !ok {
+		return ctx, errorsmod.Wrap(sdkerrors.ErrTxDecode, "Tx must be a FeeTx")
+	}
+
+	// genesis transactions and simulations are not charged
+	if simulate || ctx.BlockHeight() == 0 || !feeTx.GetFee().IsZero() {
+		return next(ctx, tx, simulate)
+	}
+
+	params := d.keeper.GetParams(ctx)
+	if !params.Enabled() {
+		return next(ctx, tx, simulate)
+	}
+
+	if feeTx.GetGas() > params.MaxGas {
+		return ctx, errorsmod.Wrapf(types.ErrFreeTxGasTooHigh, "got: %d, max: %d", feeTx.GetGas(), params.MaxGas)
+	}
+
+	if err := d.keeper.UseFreeTx(ctx, feeTx.FeePayer()); err != nil {
+		return ctx, err
+	}
+
+	return next(ctx.WithValue(freeTxKey{}, true), tx, simulate)
+}
+
+// SkipFreeTx wraps a decorator so that it is skipped for the free transactions accepted by the FreeTxDecorator.
+// It is meant for the decorators checking the fees (e.g. base fee or minimum gas prices).
+func SkipFreeTx(decorator sdk.AnteDecorator) sdk.AnteDecorator {
+	return skipFreeTxDecorator{decorator: decorator}
+}
+
+type skipFreeTxDecorator struct {
+	decorator sdk.AnteDecorator
+}
+
+func (d skipFreeTxDecorator) AnteHandle(ctx sdk.Context, tx sdk.Tx, simulate bool, next sdk.AnteHandler) (sdk.Context, error) {
+	if IsFreeTx(ctx) {
+		return next(ctx, tx, simulate)
+	}
+
+	return d.decorator.AnteHandle(ctx, tx, simulate, next)
+}
@@ -0,0 +1,125 @@
+package ante_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	storetypes "github.com/cosmos/cosmos-sdk/store/types"
+	"github.com/cosmos/cosmos-sdk/testutil"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/julienrbrt/chain-minimal/x/freetx/ante"
+	"github.com/julienrbrt/chain-minimal/x/freetx/keeper"
+	"github.com/julienrbrt/chain-minimal/x/freetx/types"
+)
+
+// testFeeTx is a dummy implementation of FeeTx used for testing.
+type testFeeTx struct {
+	sdk.FeeTx
+
+	fee   sdk.Coins
+	gas   uint64
+	payer sdk.AccAddress
+}
+
+func (tx testFeeTx) GetFee() sdk.Coins { return tx.fee }
+
+func (tx testFeeTx) GetGas() uint64 { return tx.gas }
+
+func (tx testFeeTx) FeePayer() sdk.AccAddress { return tx.payer }
+
+var errFeeCheck = errors.New("fee check")
+
+// feeCheckDecorator rejects every transaction, like a fee check the free transactions do not pass.
+type feeCheckDecorator struct{}
+
+func (feeCheckDecorator) AnteHandle(ctx sdk.Context, _ sdk.Tx, _ bool, _ sdk.AnteHandler) (sdk.Context, error) {
+	return ctx, errFeeCheck
+}
+
+func setup(t *testing.T, params types.Params) (keeper.Keeper, sdk.Context) {
+	t.Helper()
+
+	key := storetypes.NewKVStoreKey(types.StoreKey)
+	ctx := testutil.DefaultContext(key, storetypes.NewTransientStoreKey("transient_test")).
+		WithBlockHeight(1).
+		WithBlockTime(time.Unix(3600, 0))
+	k := keeper.NewKeeper(codec.NewProtoCodec(codectypes.NewInterfaceRegistry()), key, authtypes.NewModuleAddress(govtypes.ModuleName).String())
+	require.NoError(t, k.SetParams(ctx, params))
+
+	return k, ctx
+}
+
+func TestFreeTxDecorator(t *testing.T) {
+	k, ctx := setup(t, types.NewParams(2, time.Hour, 100_000))
+	payer := sdk.AccAddress("payer_______________")
+
+	// isFree records whether the last transaction was flagged as free for the next decorators
+	var isFree bool
+	anteHandler := func(ctx sdk.Context, tx sdk.Tx, simulate bool) (sdk.Context, error) {
+		return ante.NewFreeTxDecorator(k).AnteHandle(ctx, tx, simulate, func(ctx sdk.Context, _ sdk.Tx, _ bool) (sdk.Context, error) {
+			isFree = ante.IsFreeTx(ctx)
+			return ctx, nil
+		})
+	}
+
+	freeTx := testFeeTx{gas: 100_000, payer: payer}
+
+	// the free transactions are accepted within the allowance, and rejected beyond
+	for i := 0; i < 2; i++ {
+		_, err := anteHandler(ctx, freeTx, false)
+		require.NoError(t, err)
+		require.True(t, isFree)
+	}
+	_, err := anteHandler(ctx, freeTx, false)
+	require.ErrorIs(t, err, types.ErrAllowanceExhausted)
+
+	// a fee-paying transaction is not charged against the allowance
+	_, err = anteHandler(ctx, testFeeTx{fee: sdk.NewCoins(sdk.NewInt64Coin("mini", 1)), gas: 100_000, payer: payer}, false)
+	require.NoError(t, err)
+	require.False(t, isFree)
+	require.Equal(t, uint32(2), k.UsedInWindow(ctx, payer))
+
+	// nor are the simulations
+	_, err = anteHandler(ctx, freeTx, true)
+	require.NoError(t, err)
+	require.False(t, isFree)
+
+	// the gas of a free transaction is capped
+	_, err = anteHandler(ctx, testFeeTx{gas: 100_001, payer: sdk.AccAddress("other_______________")}, false)
+	require.ErrorIs(t, err, types.ErrFreeTxGasTooHigh)
+	require.Equal(t, uint32(0), k.UsedInWindow(ctx, sdk.AccAddress("other_______________")))
+}
+
+func TestFreeTxDecoratorDisabled(t *testing.T) {
+	k, ctx := setup(t, types.NewParams(0, time.Hour, 0))
+
+	// zero-fee transactions are subject to the usual fee checks
+	anteHandler := sdk.ChainAnteDecorators(ante.NewFreeTxDecorator(k), ante.SkipFreeTx(feeCheckDecorator{}))
+	_, err := anteHandler(ctx, testFeeTx{gas: 100_000, payer: sdk.AccAddress("payer_______________")}, false)
+	require.ErrorIs(t, err, errFeeCheck)
+}
+
+func TestSkipFreeTx(t *testing.T) {
+	k, ctx := setup(t, types.NewParams(1, time.Hour, 100_000))
+	payer := sdk.AccAddress("payer_______________")
+
+	anteHandler := sdk.ChainAnteDecorators(ante.NewFreeTxDecorator(k), ante.SkipFreeTx(feeCheckDecorator{}))
+
+	// the free transactions skip the fee checks
+	_, err := anteHandler(ctx, testFeeTx{gas: 100_000, payer: payer}, false)
+	require.NoError(t, err)
+
+	// the others do not
+	_, err = anteHandler(ctx, testFeeTx{fee: sdk.NewCoins(sdk.NewInt64Coin("mini", 1)), gas: 100_000, payer: payer}, false)
+	require.ErrorIs(t, err, errFeeCheck)
+
+	_, err = anteHandler(ctx, testFeeTx{gas: 100_000, payer: payer}, false)
+	require.ErrorIs(t, err, types.ErrAllowanceExhausted)
+}
@@ -0,0 +1,15 @@
+package keeper
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// MaxPrunedUsagesPerBlock is the maximum number of usages of the past windows deleted at the end of a block.
+const MaxPrunedUsagesPerBlock = 1000
+
+// EndBlocker prunes the free transaction usages of the past windows.
+func (k Keeper) EndBlocker(ctx sdk.Context) {
+	if pruned := k.PruneUsages(ctx, MaxPrunedUsagesPerBlock); pruned > 0 {
+		k.Logger(ctx).Debug("pruned free transaction usages", "count", pruned)
+	}
+}
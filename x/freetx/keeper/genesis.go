@@ -0,0 +1,29 @@
+package keeper
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/julienrbrt/chain-minimal/x/freetx/types"
+)
+
+// InitGenesis initializes the freetx module's state from a given genesis state.
+func (k Keeper) InitGenesis(ctx sdk.Context, genState *types.GenesisState) {
+	if err := k.SetParams(ctx, genState.Params); err != nil {
+		panic(err)
+	}
+
+	for _, usage := range genState.Usages {
+		k.SetUsage(ctx, sdk.MustAccAddressFromBech32(usage.Address), usage)
+	}
+}
+
+// ExportGenesis returns the freetx module's exported genesis.
+func (k Keeper) ExportGenesis(ctx sdk.Context) *types.GenesisState {
+	usages := []types.Usage{}
+	k.IterateUsages(ctx, func(_ sdk.AccAddress, usage types.Usage) bool {
+		usages = append(usages, usage)
+		return false
+	})
+
+	return types.NewGenesisState(k.GetParams(ctx), usages)
+}
@@ -0,0 +1,42 @@
+package keeper
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/julienrbrt/chain-minimal/x/freetx/types"
+)
+
+var _ types.QueryServer = Keeper{}
+
+// Params returns the parameters of the freetx module.
+func (k Keeper) Params(goCtx context.Context, _ *types.QueryParamsRequest) (*types.QueryParamsResponse, error) {
+	ctx := sdk.UnwrapSDKContext(goCtx)
+	return &types.QueryParamsResponse{Params: k.GetParams(ctx)}, nil
+}
+
+// Allowance returns the free transactions left to an account in the current window.
+func (k Keeper) Allowance(goCtx context.Context, req *types.QueryAllowanceRequest) (*types.QueryAllowanceResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "empty request")
+	}
+
+	addr, err := sdk.AccAddressFromBech32(req.Address)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx := sdk.UnwrapSDKContext(goCtx)
+	used := k.UsedInWindow(ctx, addr)
+
+	var remaining uint32
+	if maxTxs := k.GetParams(ctx).MaxTxsPerWindow; used < maxTxs {
+		remaining = maxTxs - used
+	}
+
+	return &types.QueryAllowanceResponse{Used: used, Remaining: remaining}, nil
+}
@@ -0,0 +1,163 @@
+package keeper
+
+import (
+	"fmt"
+
+	"github.com/cometbft/cometbft/libs/log"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	storetypes "github.com/cosmos/cosmos-sdk/store/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/julienrbrt/chain-minimal/x/freetx/types"
+)
+
+// Keeper of the freetx store
+type Keeper struct {
+	cdc      codec.BinaryCodec
+	storeKey storetypes.StoreKey
+
+	// the address capable of executing a MsgUpdateParams message. Typically, this
+	// should be the x/gov module account.
+	authority string
+}
+
+// NewKeeper creates a new freetx Keeper instance.
+func NewKeeper(cdc codec.BinaryCodec, storeKey storetypes.StoreKey, authority string) Keeper {
+	return Keeper{
+		cdc:       cdc,
+		storeKey:  storeKey,
+		authority: authority,
+	}
+}
+
+// GetAuthority returns the x/freetx module's authority.
+func (k Keeper) GetAuthority() string {
+	return k.authority
+}
+
+// Logger returns a module-specific logger.
+func (k Keeper) Logger(ctx sdk.Context) log.Logger {
+	return ctx.Logger().With("module", "x/"+types.ModuleName)
+}
+
+// GetParams returns the current x/freetx module parameters.
+func (k Keeper) GetParams(ctx sdk.Context) (params types.Params) {
+	bz := ctx.KVStore(k.storeKey).Get(types.ParamsKey)
+	if bz == nil {
+		return params
+	}
+
+	k.cdc.MustUnmarshal(bz, &params)
+	return params
+}
+
+// SetParams sets the x/freetx module parameters.
+func (k Keeper) SetParams(ctx sdk.Context, params types.Params) error {
+	if err := params.Validate(); err != nil {
+		return err
+	}
+
+	ctx.KVStore(k.storeKey).Set(types.ParamsKey, k.cdc.MustMarshal(&params))
+	return nil
+}
+
+// GetUsage returns the free transaction usage of an account in the given window.
+func (k Keeper) GetUsage(ctx sdk.Context, window uint64, addr sdk.AccAddress) (types.Usage, bool) {
+	bz := ctx.KVStore(k.storeKey).Get(types.UsageKey(window, addr))
+	if bz == nil {
+		return types.Usage{}, false
+	}
+
+	var usage types.Usage
+	k.cdc.MustUnmarshal(bz, &usage)
+	return usage, true
+}
+
+// SetUsage sets the free transaction usage of an account, in the window of the usage.
+func (k Keeper) SetUsage(ctx sdk.Context, addr sdk.AccAddress, usage types.Usage) {
+	// the address is part of the key
+	usage.Address = ""
+	ctx.KVStore(k.storeKey).Set(types.UsageKey(usage.Window, addr), k.cdc.MustMarshal(&usage))
+}
+
+// IterateUsages iterates over the stored free transaction usages, by window.
+func (k Keeper) IterateUsages(ctx sdk.Context, cb func(addr sdk.AccAddress, usage types.Usage) (stop bool)) {
+	iterator := storetypes.KVStorePrefixIterator(ctx.KVStore(k.storeKey), types.UsagePrefix)
+	defer iterator.Close()
+
+	for ; iterator.Valid(); iterator.Next() {
+		// skip the prefix, the window and the address length
+		addr := sdk.AccAddress(iterator.Key()[len(types.UsageWindowPrefix(0))+1:])
+
+		var usage types.Usage
+		k.cdc.MustUnmarshal(iterator.Value(), &usage)
+		usage.Address = addr.String()
+
+		if cb(addr, usage) {
+			break
+		}
+	}
+}
+
+// PruneUsages deletes up to limit usages of the windows other than the current one, and returns their number.
+// The usages of the past windows are never read again, they are only kept until pruned.
+func (k Keeper) PruneUsages(ctx sdk.Context, limit int) int {
+	store := ctx.KVStore(k.storeKey)
+	window := k.GetParams(ctx).WindowAt(ctx.BlockTime())
+
+	// the usages of a later window are left by a shortened window duration
+	ranges := [][2][]byte{
+		{types.UsagePrefix, types.UsageWindowPrefix(window)},
+		{types.UsageWindowPrefix(window + 1), storetypes.PrefixEndBytes(types.UsagePrefix)},
+	}
+
+	var keys [][]byte
+	for _, r := range ranges {
+		iterator := store.Iterator(r[0], r[1])
+		for ; iterator.Valid() && len(keys) < limit; iterator.Next() {
+			keys = append(keys, iterator.Key())
+		}
+		iterator.Close()
+	}
+
+	for _, key := range keys {
+		store.Delete(key)
+	}
+
+	return len(keys)
+}
+
+// UsedInWindow returns the number of free transactions sent by an account during the current window.
+func (k Keeper) UsedInWindow(ctx sdk.Context, addr sdk.AccAddress) uint32 {
+	window := k.GetParams(ctx).WindowAt(ctx.BlockTime())
+
+	usage, ok := k.GetUsage(ctx, window, addr)
+	if !ok {
+		return 0
+	}
+
+	return usage.Count
+}
+
+// UseFreeTx consumes a free transaction of the account allowance in the current window.
+// It returns an error when the allowance is exhausted.
+func (k Keeper) UseFreeTx(ctx sdk.Context, addr sdk.AccAddress) error {
+	params := k.GetParams(ctx)
+	window := params.WindowAt(ctx.BlockTime())
+
+	used := k.UsedInWindow(ctx, addr)
+	if used >= params.MaxTxsPerWindow {
+		return types.ErrAllowanceExhausted.Wrapf("%s already sent %d free transactions in the current window", addr, used)
+	}
+
+	k.SetUsage(ctx, addr, types.Usage{Window: window, Count: used + 1})
+
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		types.EventTypeFreeTx,
+		sdk.NewAttribute(types.AttributeKeyFeePayer, addr.String()),
+		sdk.NewAttribute(types.AttributeKeyUsed, fmt.Sprintf("%d", used+1)),
+	))
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package keeper_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	storetypes "github.com/cosmos/cosmos-sdk/store/types"
+	"github.com/cosmos/cosmos-sdk/testutil"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/julienrbrt/chain-minimal/x/freetx/keeper"
+	"github.com/julienrbrt/chain-minimal/x/freetx/types"
+)
+
+func TestUseFreeTx(t *testing.T) {
+	key := storetypes.NewKVStoreKey(types.StoreKey)
+	ctx := testutil.DefaultContext(key, storetypes.NewTransientStoreKey("transient_test"))
+	k := keeper.NewKeeper(codec.NewProtoCodec(codectypes.NewInterfaceRegistry()), key, authtypes.NewModuleAddress(govtypes.ModuleName).String())
+	require.NoError(t, k.SetParams(ctx, types.NewParams(2, time.Hour, 200_000)))
+
+	addr := sdk.AccAddress("addr________________")
+	ctx = ctx.WithBlockTime(time.Unix(3600, 0))
+
+	require.NoError(t, k.UseFreeTx(ctx, addr))
+	require.NoError(t, k.UseFreeTx(ctx, addr))
+	require.Equal(t, uint32(2), k.UsedInWindow(ctx, addr))
+	require.ErrorIs(t, k.UseFreeTx(ctx, addr), types.ErrAllowanceExhausted)
+
+	// the allowance is per account
+	require.NoError(t, k.UseFreeTx(ctx, sdk.AccAddress("other_______________")))
+
+	// and resets with the next window
+	ctx = ctx.WithBlockTime(time.Unix(2*3600, 0))
+	require.Equal(t, uint32(0), k.UsedInWindow(ctx, addr))
+	require.NoError(t, k.UseFreeTx(ctx, addr))
+
+	// an exported state is imported back as is, the usage of the past window being kept until pruned
+	genesis := k.ExportGenesis(ctx)
+	require.Len(t, genesis.Usages, 3)
+	require.NoError(t, genesis.Validate())
+
+	k.EndBlocker(ctx)
+	require.Len(t, k.ExportGenesis(ctx).Usages, 1)
+}
+
+func TestPruneUsages(t *testing.T) {
+	key := storetypes.NewKVStoreKey(types.StoreKey)
+	ctx := testutil.DefaultContext(key, storetypes.NewTransientStoreKey("transient_test"))
+	k := keeper.NewKeeper(codec.NewProtoCodec(codectypes.NewInterfaceRegistry()), key, authtypes.NewModuleAddress(govtypes.ModuleName).String())
+	require.NoError(t, k.SetParams(ctx, types.NewParams(2, time.Hour, 200_000)))
+
+	addrs := []sdk.AccAddress{sdk.AccAddress("addr1_______________"), sdk.AccAddress("addr2_______________"), sdk.AccAddress("addr3_______________")}
+	for _, addr := range addrs {
+		require.NoError(t, k.UseFreeTx(ctx.WithBlockTime(time.Unix(3600, 0)), addr))
+	}
+
+	// nothing is pruned during the current window
+	require.Equal(t, 0, k.PruneUsages(ctx.WithBlockTime(time.Unix(3600, 0)), 10))
+
+	require.NoError(t, k.UseFreeTx(ctx.WithBlockTime(time.Unix(2*3600, 0)), addrs[0]))
+
+	countUsages := func() int {
+		var count int
+		k.IterateUsages(ctx, func(sdk.AccAddress, types.Usage) bool {
+			count++
+			return false
+		})
+		return count
+	}
+	require.Equal(t, 4, countUsages())
+
+	// the usages of the past windows are pruned, up to the limit
+	ctx = ctx.WithBlockTime(time.Unix(2*3600, 0))
+	require.Equal(t, 2, k.PruneUsages(ctx, 2))
+	require.Equal(t, 1, k.PruneUsages(ctx, 2))
+	require.Equal(t, 0, k.PruneUsages(ctx, 2))
+	require.Equal(t, 1, countUsages())
+	require.Equal(t, uint32(1), k.UsedInWindow(ctx, addrs[0]))
+
+	// the usages of a later window, left by a shortened window duration, are pruned too
+	require.NoError(t, k.SetParams(ctx, types.NewParams(2, 2*time.Hour, 200_000)))
+	k.EndBlocker(ctx)
+	require.Equal(t, 0, countUsages())
+}
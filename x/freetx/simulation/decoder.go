@@ -0,0 +1,32 @@
+package simulation
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/cosmos-sdk/types/kv"
+
+	"github.com/julienrbrt/chain-minimal/x/freetx/types"
+)
+
+// NewDecodeStore returns a decoder function closure that unmarshals the KVPair's
+// Value to the corresponding freetx type.
+func NewDecodeStore(cdc codec.BinaryCodec) func(kvA, kvB kv.Pair) string {
+	return func(kvA, kvB kv.Pair) string {
+		switch {
+		case bytes.Equal(kvA.Key, types.ParamsKey):
+			var paramsA, paramsB types.Params
+			cdc.MustUnmarshal(kvA.Value, &paramsA)
+			cdc.MustUnmarshal(kvB.Value, &paramsB)
+			return fmt.Sprintf("%v\n%v", paramsA, paramsB)
+		case bytes.HasPrefix(kvA.Key, types.UsagePrefix):
+			var usageA, usageB types.Usage
+			cdc.MustUnmarshal(kvA.Value, &usageA)
+			cdc.MustUnmarshal(kvB.Value, &usageB)
+			return fmt.Sprintf("%v\n%v", usageA, usageB)
+		default:
+			panic(fmt.Sprintf("invalid freetx key %X", kvA.Key))
+		}
+	}
+}
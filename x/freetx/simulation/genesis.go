@@ -0,0 +1,67 @@
+package simulation
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"time"
+
+	simtestutil "github.com/cosmos/cosmos-sdk/testutil/sims"
+	"github.com/cosmos/cosmos-sdk/types/module"
+	simtypes "github.com/cosmos/cosmos-sdk/types/simulation"
+
+	"github.com/julienrbrt/chain-minimal/x/freetx/types"
+)
+
+// Simulation parameter constants
+const (
+	MaxTxsPerWindow = "max_txs_per_window"
+	Window          = "window"
+	MaxGas          = "max_gas"
+)
+
+// GenMaxTxsPerWindow randomized MaxTxsPerWindow. The simulated operations of an account without spendable coins
+// pay no fee, so the allowance stays high enough for all of them.
+func GenMaxTxsPerWindow(r *rand.Rand) uint32 {
+	return uint32(simtypes.RandIntBetween(r, 1_000, 100_000))
+}
+
+// GenWindow randomized Window, between a minute and a day
+func GenWindow(r *rand.Rand) time.Duration {
+	return time.Duration(simtypes.RandIntBetween(r, 60, 24*60*60)) * time.Second
+}
+
+// GenMaxGas randomized MaxGas, at least the gas limit of the simulated operations (simtestutil.DefaultGenTxGas)
+func GenMaxGas(r *rand.Rand) uint64 {
+	return uint64(simtypes.RandIntBetween(r, simtestutil.DefaultGenTxGas, 2*simtestutil.DefaultGenTxGas))
+}
+
+// RandomizedGenState generates a random GenesisState for freetx
+func RandomizedGenState(simState *module.SimulationState) {
+	var maxTxsPerWindow uint32
+	simState.AppParams.GetOrGenerate(
+		simState.Cdc, MaxTxsPerWindow, &maxTxsPerWindow, simState.Rand,
+		func(r *rand.Rand) { maxTxsPerWindow = GenMaxTxsPerWindow(r) },
+	)
+
+	var window time.Duration
+	simState.AppParams.GetOrGenerate(
+		simState.Cdc, Window, &window, simState.Rand,
+		func(r *rand.Rand) { window = GenWindow(r) },
+	)
+
+	var maxGas uint64
+	simState.AppParams.GetOrGenerate(
+		simState.Cdc, MaxGas, &maxGas, simState.Rand,
+		func(r *rand.Rand) { maxGas = GenMaxGas(r) },
+	)
+
+	freeTxGenesis := types.NewGenesisState(types.NewParams(maxTxsPerWindow, window, maxGas), []types.Usage{})
+
+	bz, err := json.MarshalIndent(&freeTxGenesis, "", " ")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("Selected randomly generated free transaction parameters:\n%s\n", bz)
+	simState.GenState[types.ModuleName] = simState.Cdc.MustMarshalJSON(freeTxGenesis)
+}
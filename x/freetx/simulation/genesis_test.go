@@ -0,0 +1,45 @@
+package simulation_test
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+
+	sdkmath "cosmossdk.io/math"
+	simtestutil "github.com/cosmos/cosmos-sdk/testutil/sims"
+	"github.com/cosmos/cosmos-sdk/types/module"
+	moduletestutil "github.com/cosmos/cosmos-sdk/types/module/testutil"
+	simtypes "github.com/cosmos/cosmos-sdk/types/simulation"
+	"github.com/stretchr/testify/require"
+
+	"github.com/julienrbrt/chain-minimal/x/freetx"
+	"github.com/julienrbrt/chain-minimal/x/freetx/simulation"
+	"github.com/julienrbrt/chain-minimal/x/freetx/types"
+)
+
+func TestRandomizedGenState(t *testing.T) {
+	encCfg := moduletestutil.MakeTestEncodingConfig(freetx.AppModuleBasic{})
+
+	for seed := int64(0); seed < 100; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		simState := module.SimulationState{
+			AppParams:    make(simtypes.AppParams),
+			Cdc:          encCfg.Codec,
+			Rand:         r,
+			NumBonded:    3,
+			Accounts:     simtypes.RandomAccounts(r, 3),
+			InitialStake: sdkmath.NewInt(1000),
+			GenState:     make(map[string]json.RawMessage),
+		}
+
+		simulation.RandomizedGenState(&simState)
+
+		var genState types.GenesisState
+		simState.Cdc.MustUnmarshalJSON(simState.GenState[types.ModuleName], &genState)
+		require.NoError(t, genState.Validate())
+
+		// the simulated operations paying no fee are always allowed
+		require.True(t, genState.Params.Enabled())
+		require.GreaterOrEqual(t, genState.Params.MaxGas, uint64(simtestutil.DefaultGenTxGas))
+	}
+}
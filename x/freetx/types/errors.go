@@ -0,0 +1,12 @@
+package types
+
+import (
+	errorsmod "cosmossdk.io/errors"
+)
+
+// x/freetx module sentinel errors
+var (
+	ErrInvalidSigner      = errorsmod.Register(ModuleName, 2, "expected authority account as only signer for proposal message")
+	ErrAllowanceExhausted = errorsmod.Register(ModuleName, 3, "free transaction allowance exhausted")
+	ErrFreeTxGasTooHigh   = errorsmod.Register(ModuleName, 4, "gas limit too high for a free transaction")
+)
@@ -0,0 +1,9 @@
+package types
+
+// freetx module event types
+const (
+	EventTypeFreeTx = "free_tx"
+
+	AttributeKeyFeePayer = "fee_payer"
+	AttributeKeyUsed     = "used"
+)
@@ -0,0 +1,654 @@
+// Code generated by protoc-gen-gogo. DO NOT EDIT.
+// source: mini/freetx/v1/freetx.proto
+
+package types
+
+import (
+	fmt "fmt"
+	_ "github.com/cosmos/cosmos-proto"
+	_ "github.com/cosmos/cosmos-sdk/types/tx/amino"
+	_ "github.com/cosmos/gogoproto/gogoproto"
+	proto "github.com/cosmos/gogoproto/proto"
+	github_com_cosmos_gogoproto_types "github.com/cosmos/gogoproto/types"
+	_ "google.golang.org/protobuf/types/known/durationpb"
+	io "io"
+	math "math"
+	math_bits "math/bits"
+	time "time"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+var _ = time.Kitchen
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
+
+// Params defines the parameters of the free transaction allowance.
+type Params struct {
+	// max_txs_per_window is the number of zero-fee transactions an account can send per window.
+	// A value of 0 disables the free transactions.
+	MaxTxsPerWindow uint32 `protobuf:"varint,1,opt,name=max_txs_per_window,json=maxTxsPerWindow,proto3" json:"max_txs_per_window,omitempty"`
+	// window is the duration of a window, windows are aligned on multiples of the duration since the unix epoch.
+	Window time.Duration `protobuf:"bytes,2,opt,name=window,proto3,stdduration" json:"window"`
+	// max_gas is the maximum gas limit of a free transaction.
+	MaxGas uint64 `protobuf:"varint,3,opt,name=max_gas,json=maxGas,proto3" json:"max_gas,omitempty"`
+}
+
+func (m *Params) Reset()         { *m = Params{} }
+func (m *Params) String() string { return proto.CompactTextString(m) }
+func (*Params) ProtoMessage()    {}
+func (*Params) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ffccf910cf8f0b5, []int{0}
+}
+func (m *Params) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *Params) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_Params.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *Params) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Params.Merge(m, src)
+}
+func (m *Params) XXX_Size() int {
+	return m.Size()
+}
+func (m *Params) XXX_DiscardUnknown() {
+	xxx_messageInfo_Params.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Params proto.InternalMessageInfo
+
+func (m *Params) GetMaxTxsPerWindow() uint32 {
+	if m != nil {
+		return m.MaxTxsPerWindow
+	}
+	return 0
+}
+
+func (m *Params) GetWindow() time.Duration {
+	if m != nil {
+		return m.Window
+	}
+	return 0
+}
+
+func (m *Params) GetMaxGas() uint64 {
+	if m != nil {
+		return m.MaxGas
+	}
+	return 0
+}
+
+// Usage defines the number of free transactions sent by an account during a window.
+type Usage struct {
+	// address is the address of the account paying the fees.
+	Address string `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	// window is the index of the window, i.e. the block time divided by the window duration.
+	Window uint64 `protobuf:"varint,2,opt,name=window,proto3" json:"window,omitempty"`
+	// count is the number of free transactions sent during the window.
+	Count uint32 `protobuf:"varint,3,opt,name=count,proto3" json:"count,omitempty"`
+}
+
+func (m *Usage) Reset()         { *m = Usage{} }
+func (m *Usage) String() string { return proto.CompactTextString(m) }
+func (*Usage) ProtoMessage()    {}
+func (*Usage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ffccf910cf8f0b5, []int{1}
+}
+func (m *Usage) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *Usage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_Usage.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *Usage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Usage.Merge(m, src)
+}
+func (m *Usage) XXX_Size() int {
+	return m.Size()
+}
+func (m *Usage) XXX_DiscardUnknown() {
+	xxx_messageInfo_Usage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Usage proto.InternalMessageInfo
+
+func (m *Usage) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *Usage) GetWindow() uint64 {
+	if m != nil {
+		return m.Window
+	}
+	return 0
+}
+
+func (m *Usage) GetCount() uint32 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+func init() {
+	proto.RegisterType((*Params)(nil), "mini.freetx.v1.Params")
+	proto.RegisterType((*Usage)(nil), "mini.freetx.v1.Usage")
+}
+
+func init() { proto.RegisterFile("mini/freetx/v1/freetx.proto", fileDescriptor_8ffccf910cf8f0b5) }
+
+var fileDescriptor_8ffccf910cf8f0b5 = []byte{
+	// 384 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x54, 0x90, 0xc1, 0x8a, 0x13, 0x31,
+	0x1c, 0xc6, 0x27, 0xba, 0x3b, 0x8b, 0x91, 0x2a, 0x0e, 0x83, 0xb6, 0x2b, 0xcc, 0x96, 0x3d, 0x95,
+	0x95, 0x9d, 0xd0, 0x7a, 0xf3, 0xa4, 0x45, 0xf0, 0xe2, 0xa1, 0x8c, 0x8a, 0xe0, 0x65, 0xc8, 0xcc,
+	0xa4, 0x69, 0xa4, 0x49, 0x4a, 0x92, 0x69, 0xc7, 0x57, 0xf0, 0xe4, 0xd1, 0x47, 0x10, 0xbc, 0xf4,
+	0xe0, 0x43, 0xf4, 0x58, 0x3c, 0x79, 0x52, 0x69, 0x0f, 0x7d, 0x0d, 0x99, 0x24, 0x55, 0xbc, 0x84,
+	0xff, 0xff, 0xff, 0x7d, 0xe1, 0xfb, 0xf1, 0xc1, 0x87, 0x9c, 0x09, 0x86, 0xa6, 0x8a, 0x10, 0xd3,
+	0xa0, 0xe5, 0xd0, 0x4f, 0xe9, 0x42, 0x49, 0x23, 0xa3, 0x3b, 0xad, 0x98, 0xfa, 0xd3, 0x72, 0x78,
+	0x1e, 0x53, 0x49, 0xa5, 0x95, 0x50, 0x3b, 0x39, 0xd7, 0x79, 0xaf, 0x94, 0x9a, 0x4b, 0x9d, 0x3b,
+	0xc1, 0x2d, 0x5e, 0xba, 0x87, 0x39, 0x13, 0x12, 0xd9, 0xd7, 0x9f, 0x12, 0x2a, 0x25, 0x9d, 0x13,
+	0x64, 0xb7, 0xa2, 0x9e, 0xa2, 0xaa, 0x56, 0xd8, 0x30, 0x29, 0x9c, 0x7e, 0xf9, 0x15, 0xc0, 0x70,
+	0x82, 0x15, 0xe6, 0x3a, 0x7a, 0x04, 0x23, 0x8e, 0x9b, 0xdc, 0x34, 0x3a, 0x5f, 0x10, 0x95, 0xaf,
+	0x98, 0xa8, 0xe4, 0xaa, 0x0b, 0xfa, 0x60, 0xd0, 0xc9, 0xee, 0x72, 0xdc, 0xbc, 0x6e, 0xf4, 0x84,
+	0xa8, 0xb7, 0xf6, 0x1c, 0x3d, 0x85, 0xa1, 0x37, 0xdc, 0xe8, 0x83, 0xc1, 0xed, 0x51, 0x2f, 0x75,
+	0x41, 0xe9, 0x31, 0x28, 0x7d, 0xee, 0x83, 0xc6, 0x9d, 0xcd, 0xcf, 0x8b, 0xe0, 0xf3, 0xaf, 0x0b,
+	0xf0, 0xe5, 0xb0, 0xbe, 0x02, 0x99, 0xff, 0x17, 0x3d, 0x80, 0x67, 0x6d, 0x1c, 0xc5, 0xba, 0x7b,
+	0xb3, 0x0f, 0x06, 0x27, 0x59, 0xc8, 0x71, 0xf3, 0x02, 0xeb, 0x27, 0xbd, 0x8f, 0x87, 0xf5, 0x55,
+	0x6c, 0x8b, 0x6a, 0x8e, 0x55, 0x39, 0xc4, 0x4b, 0x06, 0x4f, 0xdf, 0x68, 0x4c, 0x49, 0x34, 0x82,
+	0x67, 0xb8, 0xaa, 0x14, 0xd1, 0xda, 0x02, 0xde, 0x1a, 0x77, 0xbf, 0x7f, 0xbb, 0x8e, 0x7d, 0x19,
+	0xcf, 0x9c, 0xf2, 0xca, 0x28, 0x26, 0x68, 0x76, 0x34, 0x46, 0xf7, 0xff, 0x43, 0x3e, 0xf9, 0x0b,
+	0x12, 0xc3, 0xd3, 0x52, 0xd6, 0xc2, 0x58, 0x8c, 0x4e, 0xe6, 0x96, 0xf1, 0xcb, 0xcd, 0x2e, 0x01,
+	0xdb, 0x5d, 0x02, 0x7e, 0xef, 0x12, 0xf0, 0x69, 0x9f, 0x04, 0xdb, 0x7d, 0x12, 0xfc, 0xd8, 0x27,
+	0xc1, 0xbb, 0x11, 0x65, 0x66, 0x56, 0x17, 0x69, 0x29, 0x39, 0x7a, 0x5f, 0xcf, 0x19, 0x11, 0xaa,
+	0x50, 0x06, 0x95, 0x33, 0xcc, 0xc4, 0x75, 0x8b, 0xcd, 0xf1, 0xfc, 0x1f, 0xb9, 0xf9, 0xb0, 0x20,
+	0xba, 0x08, 0x6d, 0x2d, 0x8f, 0xff, 0x0c, 0x00, 0x20, 0x6d, 0xfc, 0x14, 0x00, 0x02, 0x00, 0x00,
+}
+
+func (m *Params) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Params) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Params) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.MaxGas != 0 {
+		i = encodeVarintFreetx(dAtA, i, uint64(m.MaxGas))
+		i--
+		dAtA[i] = 0x18
+	}
+	n1, err1 := github_com_cosmos_gogoproto_types.StdDurationMarshalTo(m.Window, dAtA[i-github_com_cosmos_gogoproto_types.SizeOfStdDuration(m.Window):])
+	if err1 != nil {
+		return 0, err1
+	}
+	i -= n1
+	i = encodeVarintFreetx(dAtA, i, uint64(n1))
+	i--
+	dAtA[i] = 0x12
+	if m.MaxTxsPerWindow != 0 {
+		i = encodeVarintFreetx(dAtA, i, uint64(m.MaxTxsPerWindow))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *Usage) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Usage) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Usage) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.Count != 0 {
+		i = encodeVarintFreetx(dAtA, i, uint64(m.Count))
+		i--
+		dAtA[i] = 0x18
+	}
+	if m.Window != 0 {
+		i = encodeVarintFreetx(dAtA, i, uint64(m.Window))
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.Address) > 0 {
+		i -= len(m.Address)
+		copy(dAtA[i:], m.Address)
+		i = encodeVarintFreetx(dAtA, i, uint64(len(m.Address)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func encodeVarintFreetx(dAtA []byte, offset int, v uint64) int {
+	offset -= sovFreetx(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+func (m *Params) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.MaxTxsPerWindow != 0 {
+		n += 1 + sovFreetx(uint64(m.MaxTxsPerWindow))
+	}
+	l = github_com_cosmos_gogoproto_types.SizeOfStdDuration(m.Window)
+	n += 1 + l + sovFreetx(uint64(l))
+	if m.MaxGas != 0 {
+		n += 1 + sovFreetx(uint64(m.MaxGas))
+	}
+	return n
+}
+
+func (m *Usage) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Address)
+	if l > 0 {
+		n += 1 + l + sovFreetx(uint64(l))
+	}
+	if m.Window != 0 {
+		n += 1 + sovFreetx(uint64(m.Window))
+	}
+	if m.Count != 0 {
+		n += 1 + sovFreetx(uint64(m.Count))
+	}
+	return n
+}
+
+func sovFreetx(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozFreetx(x uint64) (n int) {
+	return sovFreetx(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (m *Params) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowFreetx
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Params: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Params: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxTxsPerWindow", wireType)
+			}
+			m.MaxTxsPerWindow = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFreetx
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxTxsPerWindow |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Window", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFreetx
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthFreetx
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthFreetx
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_cosmos_gogoproto_types.StdDurationUnmarshal(&m.Window, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxGas", wireType)
+			}
+			m.MaxGas = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFreetx
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxGas |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipFreetx(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthFreetx
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Usage) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowFreetx
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Usage: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Usage: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Address", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFreetx
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthFreetx
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthFreetx
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Address = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Window", wireType)
+			}
+			m.Window = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFreetx
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Window |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Count", wireType)
+			}
+			m.Count = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFreetx
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Count |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipFreetx(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthFreetx
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func skipFreetx(dAtA []byte) (n int, err error) {
+	l := len(dAtA)
+	iNdEx := 0
+	depth := 0
+	for iNdEx < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return 0, ErrIntOverflowFreetx
+			}
+			if iNdEx >= l {
+				return 0, io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		wireType := int(wire & 0x7)
+		switch wireType {
+		case 0:
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowFreetx
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				iNdEx++
+				if dAtA[iNdEx-1] < 0x80 {
+					break
+				}
+			}
+		case 1:
+			iNdEx += 8
+		case 2:
+			var length int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowFreetx
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				length |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if length < 0 {
+				return 0, ErrInvalidLengthFreetx
+			}
+			iNdEx += length
+		case 3:
+			depth++
+		case 4:
+			if depth == 0 {
+				return 0, ErrUnexpectedEndOfGroupFreetx
+			}
+			depth--
+		case 5:
+			iNdEx += 4
+		default:
+			return 0, fmt.Errorf("proto: illegal wireType %d", wireType)
+		}
+		if iNdEx < 0 {
+			return 0, ErrInvalidLengthFreetx
+		}
+		if depth == 0 {
+			return iNdEx, nil
+		}
+	}
+	return 0, io.ErrUnexpectedEOF
+}
+
+var (
+	ErrInvalidLengthFreetx        = fmt.Errorf("proto: negative length found during unmarshaling")
+	ErrIntOverflowFreetx          = fmt.Errorf("proto: integer overflow")
+	ErrUnexpectedEndOfGroupFreetx = fmt.Errorf("proto: unexpected end of group")
+)
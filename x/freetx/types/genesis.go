@@ -0,0 +1,47 @@
+package types
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// NewGenesisState creates a new genesis state.
+func NewGenesisState(params Params, usages []Usage) *GenesisState {
+	return &GenesisState{
+		Params: params,
+		Usages: usages,
+	}
+}
+
+// DefaultGenesisState returns a default genesis state.
+func DefaultGenesisState() *GenesisState {
+	return NewGenesisState(DefaultParams(), []Usage{})
+}
+
+// Validate performs basic genesis state validation.
+func (gs GenesisState) Validate() error {
+	if err := gs.Params.Validate(); err != nil {
+		return err
+	}
+
+	type usageKey struct {
+		address string
+		window  uint64
+	}
+
+	seen := make(map[usageKey]bool, len(gs.Usages))
+	for _, usage := range gs.Usages {
+		if _, err := sdk.AccAddressFromBech32(usage.Address); err != nil {
+			return fmt.Errorf("invalid usage address %s: %w", usage.Address, err)
+		}
+
+		key := usageKey{address: usage.Address, window: usage.Window}
+		if seen[key] {
+			return fmt.Errorf("duplicate usage for address %s in window %d", usage.Address, usage.Window)
+		}
+		seen[key] = true
+	}
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package types
+
+import (
+	"encoding/binary"
+
+	"github.com/cosmos/cosmos-sdk/types/address"
+)
+
+const (
+	// ModuleName defines the module name
+	ModuleName = "freetx"
+
+	// StoreKey defines the primary module store key
+	StoreKey = ModuleName
+)
+
+var (
+	// ParamsKey is the key of the module parameters
+	ParamsKey = []byte{0x01}
+
+	// UsagePrefix is the prefix of the free transaction usages, by window then by account
+	UsagePrefix = []byte{0x02}
+)
+
+// UsageWindowPrefix returns the prefix of the free transaction usages of a window.
+func UsageWindowPrefix(window uint64) []byte {
+	key := make([]byte, len(UsagePrefix), len(UsagePrefix)+8)
+	copy(key, UsagePrefix)
+
+	return binary.BigEndian.AppendUint64(key, window)
+}
+
+// UsageKey returns the key of the free transaction usage of an account in a window.
+// The usages are keyed by window first, so that the ones of the past windows can be pruned.
+func UsageKey(window uint64, addr []byte) []byte {
+	return append(UsageWindowPrefix(window), address.MustLengthPrefix(addr)...)
+}
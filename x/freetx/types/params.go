@@ -0,0 +1,56 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultMaxTxsPerWindow is the default number of free transactions per account and window.
+	DefaultMaxTxsPerWindow uint32 = 10
+	// DefaultWindow is the default window duration, a day.
+	DefaultWindow = 24 * time.Hour
+	// DefaultMaxGas is the default maximum gas limit of a free transaction.
+	DefaultMaxGas uint64 = 200_000
+)
+
+// NewParams creates a new Params instance.
+func NewParams(maxTxsPerWindow uint32, window time.Duration, maxGas uint64) Params {
+	return Params{
+		MaxTxsPerWindow: maxTxsPerWindow,
+		Window:          window,
+		MaxGas:          maxGas,
+	}
+}
+
+// DefaultParams returns the default parameters of the freetx module.
+func DefaultParams() Params {
+	return NewParams(DefaultMaxTxsPerWindow, DefaultWindow, DefaultMaxGas)
+}
+
+// Validate validates the parameters.
+func (p Params) Validate() error {
+	if p.Window < time.Second {
+		return fmt.Errorf("window must be at least a second, got %s", p.Window)
+	}
+
+	if p.MaxTxsPerWindow > 0 && p.MaxGas == 0 {
+		return fmt.Errorf("max gas must be positive when free transactions are enabled")
+	}
+
+	return nil
+}
+
+// Enabled returns whether free transactions are enabled.
+func (p Params) Enabled() bool {
+	return p.MaxTxsPerWindow > 0
+}
+
+// WindowAt returns the index of the window containing the given time.
+func (p Params) WindowAt(t time.Time) uint64 {
+	if t.Unix() <= 0 || p.Window < time.Second {
+		return 0
+	}
+
+	return uint64(t.Unix()) / uint64(p.Window/time.Second)
+}
@@ -0,0 +1,42 @@
+package types_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/julienrbrt/chain-minimal/x/freetx/types"
+)
+
+func TestWindowAt(t *testing.T) {
+	params := types.NewParams(10, time.Hour, 200_000)
+
+	start := time.Unix(10*3600, 0)
+	require.Equal(t, uint64(10), params.WindowAt(start))
+	require.Equal(t, uint64(10), params.WindowAt(start.Add(time.Hour-time.Second)))
+	require.Equal(t, uint64(11), params.WindowAt(start.Add(time.Hour)))
+
+	// unset times and windows do not panic
+	require.Equal(t, uint64(0), params.WindowAt(time.Time{}))
+	require.Equal(t, uint64(0), types.Params{}.WindowAt(start))
+}
+
+func TestGenesisValidate(t *testing.T) {
+	require.NoError(t, types.DefaultGenesisState().Validate())
+
+	params := types.DefaultParams()
+	params.Window = time.Millisecond
+	require.Error(t, types.NewGenesisState(params, nil).Validate())
+
+	params = types.DefaultParams()
+	params.MaxGas = 0
+	require.Error(t, types.NewGenesisState(params, nil).Validate())
+
+	// free transactions can be disabled
+	params.MaxTxsPerWindow = 0
+	require.NoError(t, types.NewGenesisState(params, nil).Validate())
+
+	usage := types.Usage{Address: "invalid", Window: 1, Count: 1}
+	require.Error(t, types.NewGenesisState(types.DefaultParams(), []types.Usage{usage}).Validate())
+}